@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"rto/data"
 )
 
 // Result holds the outcome of a git backup operation.
@@ -43,7 +45,8 @@ func Perform(dir, remote string) Result {
 		}
 	}
 
-	if err := runGitSilent(dir, "add", "."); err != nil {
+	// Never commit the advisory lock file held by a running TUI.
+	if err := runGitSilent(dir, "add", "--", ".", ":(exclude)"+data.LockFilename); err != nil {
 		return Result{Message: fmt.Sprintf("git add failed: %v", err), IsError: true}
 	}
 
@@ -97,6 +100,9 @@ func Status(dir string) StatusInfo {
 		if len(line) < 2 {
 			continue
 		}
+		if strings.HasSuffix(line, " "+data.LockFilename) {
+			continue
+		}
 		if line[:2] == "??" {
 			info.Untracked++
 		} else {
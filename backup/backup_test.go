@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"rto/data"
 )
 
 func hasGit(t *testing.T) bool {
@@ -265,3 +267,32 @@ func TestStatus_WithRemote(t *testing.T) {
 		t.Error("expected HasRemote=true")
 	}
 }
+
+func TestPerformSkipsLockFile(t *testing.T) {
+	if !hasGit(t) {
+		return
+	}
+	dir := t.TempDir()
+	_ = runGitSilent(dir, "init")
+	setGitIdentity(t, dir)
+
+	_ = os.WriteFile(filepath.Join(dir, "data.txt"), []byte("data"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, data.LockFilename), []byte("123\ntui\n"), 0644)
+
+	result := Perform(dir, "")
+	if result.IsError {
+		t.Fatalf("backup failed: %s", result.Message)
+	}
+	out, err := runGitOutput(dir, "ls-files")
+	if err != nil {
+		t.Fatalf("git ls-files: %v", err)
+	}
+	if strings.Contains(out, data.LockFilename) {
+		t.Error("lock file should not be committed")
+	}
+
+	info := Status(dir)
+	if info.Untracked != 0 || !info.Clean {
+		t.Errorf("lock file should not count as untracked, got %+v", info)
+	}
+}
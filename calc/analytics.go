@@ -0,0 +1,178 @@
+package calc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"rto/data"
+)
+
+// Analytics describes attendance patterns over a range of dates. Only days
+// up to today are counted, and today only once it has a badge-in.
+type Analytics struct {
+	Start, End time.Time
+
+	Weekdays      []WeekdayStats // Monday first; weekdays with no available days are left out
+	LongestStreak Streak
+	CurrentStreak Streak // the streak running up to today, if any
+
+	AverageArrival time.Duration // mean time of day of TimedBadgeIns, 0 without any
+	TimedBadgeIns  int           // office badge-ins with a recorded time of day
+
+	Offices []OfficeCount // office badge-ins per office, most first
+}
+
+// WeekdayStats is the attendance on one weekday. A day is available if it
+// is a workday that isn't a holiday or a day of leave of any type; badge-ins
+// on other days count only toward arrival times and offices.
+type WeekdayStats struct {
+	Weekday   time.Weekday
+	Available int
+	Office    int     // available days badged in at an office
+	Flex      int     // available days with a flex credit
+	Rate      float64 // Office / Available, 0 to 1
+}
+
+// Streak is a run of available days badged in at an office, uninterrupted
+// by any available day without one. Holidays, leave and days off don't
+// break a streak.
+type Streak struct {
+	Days       int
+	Start, End time.Time
+}
+
+// String describes s, e.g. "6 days (Jan 21 – Jan 28)", or "none".
+func (s Streak) String() string {
+	switch s.Days {
+	case 0:
+		return "none"
+	case 1:
+		return "1 day (" + s.Start.Format("Jan 2") + ")"
+	}
+	return fmt.Sprintf("%d days (%s – %s)", s.Days, s.Start.Format("Jan 2"), s.End.Format("Jan 2"))
+}
+
+// OfficeCount is the number of office badge-ins recorded at an office.
+type OfficeCount struct {
+	Office string
+	Days   int
+}
+
+// CalculateAnalytics computes the attendance patterns from start to end,
+// inclusive. Planned office days aren't badge-ins and are ignored, and a
+// badge-in recorded at midnight has no time of day.
+func CalculateAnalytics(
+	badges *data.BadgeEntryData,
+	holidays *data.HolidayData,
+	vacations *data.VacationData,
+	start, end, today time.Time,
+) *Analytics {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	a := &Analytics{Start: start, End: end}
+	badgeMap := badges.GetBadgeMap(start, end)
+	holidayMap := holidays.GetHolidayMap()
+	vacationMap := vacations.GetVacationMap()
+
+	weekdays := map[time.Weekday]*WeekdayStats{}
+	offices := map[string]int{}
+	var arrivals time.Duration
+	var run Streak
+	for d := start; !d.After(end) && !d.After(today); d = d.AddDate(0, 0, 1) {
+		key := d.Format(data.BadgeDateFormat)
+		entry, ok := badgeMap[key]
+		ok = ok && !entry.Planned
+		office := ok && entry.IsBadgedIn && !entry.IsFlexCredit
+		flex := ok && entry.IsBadgedIn && entry.IsFlexCredit
+		if d.Equal(today) && !office && !flex {
+			break
+		}
+
+		if office {
+			offices[entry.Office]++
+			h, m, sec := entry.DateTime.Clock()
+			if tod := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second; tod > 0 {
+				arrivals += tod
+				a.TimedBadgeIns++
+			}
+		}
+
+		_, holiday := holidayMap[key]
+		_, leave := vacationMap[key]
+		if !IsWorkday(d) || holiday || leave {
+			continue
+		}
+		ws := weekdays[d.Weekday()]
+		if ws == nil {
+			ws = &WeekdayStats{Weekday: d.Weekday()}
+			weekdays[d.Weekday()] = ws
+		}
+		ws.Available++
+		switch {
+		case office:
+			ws.Office++
+			if run.Days == 0 {
+				run.Start = d
+			}
+			run.Days++
+			run.End = d
+			if run.Days > a.LongestStreak.Days {
+				a.LongestStreak = run
+			}
+		case flex:
+			ws.Flex++
+			run = Streak{}
+		default:
+			run = Streak{}
+		}
+	}
+	a.CurrentStreak = run
+
+	for i := 1; i <= 7; i++ {
+		if ws := weekdays[time.Weekday(i%7)]; ws != nil {
+			ws.Rate = float64(ws.Office) / float64(ws.Available)
+			a.Weekdays = append(a.Weekdays, *ws)
+		}
+	}
+	if a.TimedBadgeIns > 0 {
+		a.AverageArrival = (arrivals / time.Duration(a.TimedBadgeIns)).Round(time.Minute)
+	}
+	for office, days := range offices {
+		a.Offices = append(a.Offices, OfficeCount{Office: office, Days: days})
+	}
+	sort.Slice(a.Offices, func(i, j int) bool {
+		if a.Offices[i].Days != a.Offices[j].Days {
+			return a.Offices[i].Days > a.Offices[j].Days
+		}
+		return a.Offices[i].Office < a.Offices[j].Office
+	})
+	return a
+}
+
+// LeastAttended returns the available weekday with the lowest rate, the
+// earliest in the week on a tie; false if there is none.
+func (a *Analytics) LeastAttended() (WeekdayStats, bool) {
+	if len(a.Weekdays) == 0 {
+		return WeekdayStats{}, false
+	}
+	least := a.Weekdays[0]
+	for _, ws := range a.Weekdays[1:] {
+		if ws.Rate < least.Rate {
+			least = ws
+		}
+	}
+	return least, true
+}
+
+// RateBar draws rate, from 0 to 1, as a bar of width cells.
+func RateBar(rate float64, width int) string {
+	filled := max(0, min(width, int(rate*float64(width)+0.5)))
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// FormatTimeOfDay formats a time of day, such as Analytics.AverageArrival,
+// as HH:MM.
+func FormatTimeOfDay(d time.Duration) string {
+	return time.Time{}.Add(d).Format("15:04")
+}
@@ -0,0 +1,64 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"rto/data"
+)
+
+func TestCalculateAnalytics(t *testing.T) {
+	badges := data.NewBadgeEntryData()
+	for _, d := range []string{"2025-01-14", "2025-01-15", "2025-01-16", "2025-01-21", "2025-01-22",
+		"2025-01-23", "2025-01-24", "2025-01-25", "2025-01-27", "2025-01-28"} {
+		badges.Add(data.NewOfficeBadge(parseDate(d), "HQ"))
+	}
+	badges.Add(data.NewOfficeBadge(time.Date(2025, 1, 7, 8, 30, 0, 0, time.UTC), "HQ"))
+	badges.Add(data.NewOfficeBadge(time.Date(2025, 1, 8, 9, 30, 0, 0, time.UTC), "HQ"))
+	badges.Add(data.NewOfficeBadge(parseDate("2025-01-09"), "Annex"))
+	badges.Add(data.NewFlexBadge(parseDate("2025-01-17"), "Flex"))
+	badges.Add(data.NewPlannedBadge(parseDate("2025-01-29"), "HQ"))
+	holidays := data.NewHolidayData()
+	holidays.Add(data.Holiday{Name: "Closed", Date: "2025-01-10"})
+	vacations := data.NewVacationData()
+	vacations.Add(data.Vacation{Destination: "Trip", StartDate: "2025-01-20", EndDate: "2025-01-20"})
+
+	// Mondays are missed but for the 27th; the holiday, leave and weekend
+	// don't break the streak from the 21st to the 28th.
+	a := CalculateAnalytics(badges, holidays, vacations, parseDate("2025-01-06"), parseDate("2025-01-31"), parseDate("2025-01-29"))
+	want := []WeekdayStats{
+		{Weekday: time.Monday, Available: 3, Office: 1, Rate: 1.0 / 3},
+		{Weekday: time.Tuesday, Available: 4, Office: 4, Rate: 1},
+		{Weekday: time.Wednesday, Available: 3, Office: 3, Rate: 1},
+		{Weekday: time.Thursday, Available: 3, Office: 3, Rate: 1},
+		{Weekday: time.Friday, Available: 2, Office: 1, Flex: 1, Rate: 0.5},
+	}
+	if len(a.Weekdays) != len(want) {
+		t.Fatalf("expected %d weekdays, got %+v", len(want), a.Weekdays)
+	}
+	for i := range want {
+		if a.Weekdays[i] != want[i] {
+			t.Errorf("expected %+v, got %+v", want[i], a.Weekdays[i])
+		}
+	}
+	if least, ok := a.LeastAttended(); !ok || least.Weekday != time.Monday {
+		t.Errorf("expected Monday least attended, got %v", least.Weekday)
+	}
+
+	streak := Streak{Days: 6, Start: parseDate("2025-01-21"), End: parseDate("2025-01-28")}
+	if a.LongestStreak != streak || a.CurrentStreak != streak {
+		t.Errorf("expected longest and current streak %+v, got %+v and %+v", streak, a.LongestStreak, a.CurrentStreak)
+	}
+	if a.AverageArrival != 9*time.Hour || a.TimedBadgeIns != 2 {
+		t.Errorf("expected 2 badge-ins averaging 09:00, got %d averaging %v", a.TimedBadgeIns, a.AverageArrival)
+	}
+	if len(a.Offices) != 2 || a.Offices[0] != (OfficeCount{"HQ", 12}) || a.Offices[1] != (OfficeCount{"Annex", 1}) {
+		t.Errorf("unexpected offices: %+v", a.Offices)
+	}
+
+	// A day later, the missed 29th ends the current streak.
+	a = CalculateAnalytics(badges, holidays, vacations, parseDate("2025-01-06"), parseDate("2025-01-31"), parseDate("2025-01-30"))
+	if a.CurrentStreak.Days != 0 || a.LongestStreak.Days != 6 {
+		t.Errorf("expected no current streak, got %+v", a.CurrentStreak)
+	}
+}
@@ -0,0 +1,69 @@
+package calc
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"rto/data"
+)
+
+// HistoryEntry is one period of an attendance history: its stats and the
+// figures rto history compares across periods.
+type HistoryEntry struct {
+	*PeriodStats
+	Rate     float64 // credits per workday so far (CurrentAverage), 0 to 1
+	Margin   float64 // Credits minus DaysRequired: a surplus if positive, a shortfall if negative
+	Complete bool    // the period ended before today
+}
+
+// CalculateHistory computes the stats of each of periods that has started
+// by today, in date order, under policy.
+func CalculateHistory(
+	periods []data.TimePeriod,
+	badges *data.BadgeEntryData,
+	holidays *data.HolidayData,
+	vacations *data.VacationData,
+	policy *data.Policy,
+	today time.Time,
+) ([]HistoryEntry, error) {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	sorted := make([]data.TimePeriod, len(periods))
+	copy(sorted, periods)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StartDate.Before(sorted[j].StartDate) })
+
+	var history []HistoryEntry
+	for i := range sorted {
+		tp := &sorted[i]
+		if tp.StartDate.After(today) {
+			continue
+		}
+		stats, err := CalculatePolicyStats(tp, badges, holidays, vacations, policy, &today)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, HistoryEntry{
+			PeriodStats: stats,
+			Rate:        stats.CurrentAverage,
+			Margin:      stats.Credits - float64(stats.DaysRequired),
+			Complete:    tp.EndDate.Before(today),
+		})
+	}
+	return history, nil
+}
+
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values from 0 to top as one bar each, lowest ▁ to
+// highest █; values outside the range are clamped.
+func Sparkline(values []float64, top float64) string {
+	bars := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if top > 0 {
+			level = int(math.Round(v / top * float64(len(sparkLevels)-1)))
+		}
+		bars[i] = sparkLevels[max(0, min(len(sparkLevels)-1, level))]
+	}
+	return string(bars)
+}
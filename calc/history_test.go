@@ -0,0 +1,43 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"rto/data"
+)
+
+func TestCalculateHistory(t *testing.T) {
+	periods, _ := data.GeneratePeriods(data.PeriodSpec{Unit: data.UnitMonths, Year: 2025})
+	badges := data.NewBadgeEntryData()
+	// Every weekday of January, half of February's, none since.
+	for d := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); d.Month() < time.March; d = d.AddDate(0, 0, 1) {
+		if IsWorkday(d) && (d.Month() == time.January || d.Day()%2 == 0) {
+			badges.Add(data.NewOfficeBadge(d, "HQ"))
+		}
+	}
+	today := time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+	history, err := CalculateHistory(periods, badges, data.NewHolidayData(), data.NewVacationData(), data.DefaultPolicy(50), today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("expected January to March, got %d periods", len(history))
+	}
+	jan, feb, mar := history[0], history[1], history[2]
+	// January 2025: 23 weekdays, 12 required.
+	if jan.Key != "JAN_2025" || jan.Rate != 1 || jan.Margin != 11 || !jan.Complete {
+		t.Errorf("unexpected January: rate %v, margin %v, complete %v", jan.Rate, jan.Margin, jan.Complete)
+	}
+	// February 2025: 20 weekdays, 10 on even days, 10 required.
+	if feb.Rate != 0.5 || feb.Margin != 0 {
+		t.Errorf("unexpected February: rate %v, margin %v", feb.Rate, feb.Margin)
+	}
+	if mar.Complete || mar.Margin >= 0 {
+		t.Errorf("March is in progress and short, got complete %v, margin %v", mar.Complete, mar.Margin)
+	}
+
+	if got := Sparkline([]float64{jan.Rate, feb.Rate, mar.Rate, 1.5, -1}, 1); got != "█▅▁█▁" {
+		t.Errorf("Sparkline = %q", got)
+	}
+}
@@ -0,0 +1,137 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"rto/data"
+)
+
+// PlanOptions are the preferences PlanAttendance plans around.
+type PlanOptions struct {
+	Weekdays  []time.Weekday  // preferred office days, planned first; empty for no preference
+	Blackouts map[string]bool // YYYY-MM-DD dates never planned
+	Avoid     map[string]bool // YYYY-MM-DD dates planned only when nothing else is left, e.g. days with events
+	Buffer    int             // office days to plan beyond the goal
+}
+
+// NewPlanOptions returns the options described by the planner block of
+// settings.yaml, avoiding the days that have events.
+func NewPlanOptions(s data.PlannerSettings, events *data.EventData) (PlanOptions, error) {
+	if err := s.Validate(); err != nil {
+		return PlanOptions{}, err
+	}
+	opts := PlanOptions{Blackouts: map[string]bool{}, Avoid: map[string]bool{}, Buffer: s.Buffer}
+	for _, name := range s.Weekdays {
+		d, err := data.ParseWeekday(name)
+		if err != nil {
+			return PlanOptions{}, fmt.Errorf("planner: %w", err)
+		}
+		opts.Weekdays = append(opts.Weekdays, d)
+	}
+	for _, date := range s.Blackouts {
+		opts.Blackouts[date] = true
+	}
+	if events != nil {
+		for _, ev := range events.All() {
+			opts.Avoid[ev.Date] = true
+		}
+	}
+	return opts, nil
+}
+
+// Plan is a proposed set of office days for a period.
+type Plan struct {
+	Days       []time.Time // in date order
+	Needed     int         // office days needed to reach the goal plus the buffer
+	Short      int         // days of Needed there was no open day left for
+	WeeksShort int         // weeks whose minimum can't be met on the open days left
+}
+
+// PlanAttendance proposes the future office days that get the period in
+// stats to its goal plus opts.Buffer days, and meet its weekly minimum if
+// stats has Weeks, on top of the days already planned. Only open workdays
+// from today on are proposed: not holidays, leave, blackouts, or days
+// already badged in or planned. Weeks short of
+// their minimum are filled first; after that, preferred weekdays come before
+// other days and avoided days come last, earliest first within each.
+func PlanAttendance(stats *PeriodStats, today time.Time, opts PlanOptions) *Plan {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	rank := func(d time.Time) int {
+		r := 0
+		if len(opts.Weekdays) > 0 && !containsWeekday(opts.Weekdays, d.Weekday()) {
+			r = 1
+		}
+		if opts.Avoid[d.Format(data.BadgeDateFormat)] {
+			r += 2
+		}
+		return r
+	}
+
+	var open []time.Time
+	for key, wd := range stats.WorkdayStats {
+		if wd.IsHoliday || wd.Leave != "" || wd.IsBadgedIn || wd.IsPlanned || wd.Date.Before(today) || opts.Blackouts[key] {
+			continue
+		}
+		open = append(open, wd.Date)
+	}
+	sort.Slice(open, func(i, j int) bool {
+		if ri, rj := rank(open[i]), rank(open[j]); ri != rj {
+			return ri < rj
+		}
+		return open[i].Before(open[j])
+	})
+
+	plan := &Plan{Needed: max(0, int(math.Ceil(float64(stats.DaysRequired+opts.Buffer)-stats.ProjectedCredits)))}
+	chosen := map[time.Time]bool{}
+	for _, w := range stats.Weeks {
+		short := int(math.Ceil(float64(w.Required)-w.BadgedIn)) - w.Planned
+		if short <= 0 || w.End.Before(today) {
+			continue
+		}
+		for _, d := range open {
+			if short == 0 {
+				break
+			}
+			if !chosen[d] && !d.Before(w.Start) && !d.After(w.End) {
+				chosen[d] = true
+				short--
+			}
+		}
+		if short > 0 {
+			plan.WeeksShort++
+		}
+	}
+	for _, d := range open {
+		if len(chosen) >= plan.Needed {
+			break
+		}
+		chosen[d] = true
+	}
+
+	for d := range chosen {
+		plan.Days = append(plan.Days, d)
+	}
+	sort.Slice(plan.Days, func(i, j int) bool { return plan.Days[i].Before(plan.Days[j]) })
+	plan.Short = max(0, plan.Needed-len(plan.Days))
+	return plan
+}
+
+// PlanRequiredDays returns the open workdays, from today on, that still need
+// an office badge-in for the period in stats to reach its goal, as planned
+// by PlanAttendance with no preferences. If fewer open days remain than are
+// needed, every open day is returned.
+func PlanRequiredDays(stats *PeriodStats, today time.Time) []time.Time {
+	return PlanAttendance(stats, today, PlanOptions{}).Days
+}
+
+func containsWeekday(days []time.Weekday, d time.Weekday) bool {
+	for _, wd := range days {
+		if wd == d {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"rto/data"
+)
+
+func TestPlanRequiredDays(t *testing.T) {
+	tp := &data.TimePeriod{Key: "Jan", Name: "Jan", StartDateRaw: "2025-01-06", EndDateRaw: "2025-01-17"}
+	_ = tp.ParseDates()
+
+	badges := data.NewBadgeEntryData()
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-06", IsBadgedIn: true})
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-09", IsBadgedIn: true}) // future, already planned
+	holidays := data.NewHolidayData()
+	holidays.Add(data.Holiday{Name: "Closed", Date: "2025-01-10"})
+	vacations := data.NewVacationData()
+	vacations.Add(data.Vacation{Destination: "Trip", StartDate: "2025-01-13", EndDate: "2025-01-13"})
+
+	today := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+	stats, _ := CalculatePeriodStats(tp, badges, holidays, vacations, 50, &today)
+	// 10 weekdays - 1 holiday - 1 vacation = 8 days, 4 required, 1 recorded
+	// and 1 planned.
+	if stats.DaysStillNeeded != 3 || stats.ProjectedCredits != 2 {
+		t.Fatalf("expected 3 days still needed, 2 projected; got %d, %g", stats.DaysStillNeeded, stats.ProjectedCredits)
+	}
+
+	days := PlanRequiredDays(stats, today)
+	if len(days) != 2 || days[0].Day() != 8 || days[1].Day() != 14 {
+		t.Errorf("expected Jan 8 and Jan 14, got %v", days)
+	}
+
+	late := time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)
+	stats, _ = CalculatePeriodStats(tp, badges, holidays, vacations, 50, &late)
+	if days := PlanRequiredDays(stats, late); len(days) != 1 || days[0].Day() != 17 {
+		t.Errorf("expected only the remaining open day, got %v", days)
+	}
+}
+
+func TestPlanAttendance(t *testing.T) {
+	// Jan 6–31 2025: 20 weekdays, 10 required at 50%.
+	tp := &data.TimePeriod{Key: "Jan", Name: "Jan", StartDateRaw: "2025-01-06", EndDateRaw: "2025-01-31"}
+	_ = tp.ParseDates()
+	badges := data.NewBadgeEntryData()
+	for _, d := range []string{"2025-01-07", "2025-01-08"} {
+		badges.Add(data.BadgeEntry{EntryDate: d, IsBadgedIn: true})
+	}
+	holidays := data.NewHolidayData()
+	holidays.Add(data.Holiday{Name: "MLK Day", Date: "2025-01-20"})
+	today := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+	stats, _ := CalculatePeriodStats(tp, badges, holidays, data.NewVacationData(), 50, &today)
+	// 19 days, 10 required, 2 recorded.
+	if stats.DaysStillNeeded != 8 {
+		t.Fatalf("expected 8 days still needed, got %d", stats.DaysStillNeeded)
+	}
+
+	opts := PlanOptions{
+		Weekdays:  []time.Weekday{time.Tuesday, time.Wednesday, time.Thursday},
+		Blackouts: map[string]bool{"2025-01-15": true},
+		Avoid:     map[string]bool{"2025-01-14": true},
+		Buffer:    1,
+	}
+	plan := PlanAttendance(stats, today, opts)
+	// Tue–Thu from Jan 13 without the blackout and the avoided day give 7;
+	// the other 2 are the earliest Mondays and Fridays.
+	want := []string{"2025-01-16", "2025-01-21", "2025-01-22", "2025-01-23", "2025-01-28",
+		"2025-01-29", "2025-01-30", "2025-01-13", "2025-01-17"}
+	if plan.Needed != 9 || plan.Short != 0 || len(plan.Days) != len(want) {
+		t.Fatalf("expected 9 days, got %d of %d: %v", len(plan.Days), plan.Needed, plan.Days)
+	}
+	got := map[string]bool{}
+	for _, d := range plan.Days {
+		got[d.Format(data.BadgeDateFormat)] = true
+	}
+	for _, d := range want {
+		if !got[d] {
+			t.Errorf("expected %s in the plan, got %v", d, plan.Days)
+		}
+	}
+	if got["2025-01-15"] || got["2025-01-14"] || got["2025-01-20"] {
+		t.Errorf("plan includes a blackout, avoided day or holiday: %v", plan.Days)
+	}
+	for i := 1; i < len(plan.Days); i++ {
+		if !plan.Days[i-1].Before(plan.Days[i]) {
+			t.Errorf("plan days should be in date order: %v", plan.Days)
+		}
+	}
+
+	opts.Buffer = 20
+	if plan := PlanAttendance(stats, today, opts); plan.Short != plan.Needed-len(plan.Days) || plan.Short == 0 {
+		t.Errorf("expected a shortfall with a 20-day buffer, got %d short of %d", plan.Short, plan.Needed)
+	}
+}
+
+func TestPlanAttendanceWeeklyMinimum(t *testing.T) {
+	tp := &data.TimePeriod{Key: "Jan", Name: "Jan", StartDateRaw: "2025-01-06", EndDateRaw: "2025-01-17"}
+	_ = tp.ParseDates()
+	today := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	stats, _ := CalculatePeriodStats(tp, data.NewBadgeEntryData(), data.NewHolidayData(), data.NewVacationData(), 40, &today)
+	CalculateWeeks(stats, data.WeeklyMinimum{Days: 3}, today)
+
+	// 4 days reach the goal, but each week needs 3.
+	plan := PlanAttendance(stats, today, PlanOptions{})
+	if plan.Needed != 4 || len(plan.Days) != 6 || plan.WeeksShort != 0 {
+		t.Errorf("expected 3 days in each week, got %v (needed %d)", plan.Days, plan.Needed)
+	}
+}
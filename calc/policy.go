@@ -0,0 +1,52 @@
+package calc
+
+import "rto/data"
+
+// Statuses that don't depend on pace. Between them, a period's status is
+// the name of its policy's status band.
+const (
+	StatusAchieved   = "Achieved"
+	StatusImpossible = "Impossible"
+)
+
+// RequiredDays returns the days policy requires out of total, rounded by
+// its rounding mode.
+func RequiredDays(policy *data.Policy, total int) int {
+	n := total * policy.Goal
+	switch policy.Rounding {
+	case data.RoundNearest:
+		return (n + 50) / 100
+	case data.RoundFloor:
+		return n / 100
+	default:
+		return (n + 99) / 100
+	}
+}
+
+// StatusBand returns the band of policy for a period daysAheadOfPace ahead
+// of pace: the first band whose MinPace it reaches, or the last band.
+func StatusBand(policy *data.Policy, daysAheadOfPace int) data.StatusBand {
+	bands := policy.StatusBands
+	if len(bands) == 0 {
+		bands = data.DefaultStatusBands()
+	}
+	for _, b := range bands {
+		if b.MinPace == nil || daysAheadOfPace >= *b.MinPace {
+			return b
+		}
+	}
+	return bands[len(bands)-1]
+}
+
+func complianceStatus(policy *data.Policy, credits float64, daysRequired, daysAheadOfPace, daysStillNeeded, daysLeft int) string {
+	if credits >= float64(daysRequired) {
+		return StatusAchieved
+	}
+	// Nothing recorded yet and nothing expected: not impossible, whatever
+	// the arithmetic says.
+	notStarted := daysAheadOfPace == 0 && credits == 0
+	if !notStarted && daysStillNeeded > daysLeft {
+		return StatusImpossible
+	}
+	return StatusBand(policy, daysAheadOfPace).Name
+}
@@ -0,0 +1,191 @@
+package calc
+
+import (
+	"testing"
+
+	"rto/data"
+)
+
+func TestRequiredDays(t *testing.T) {
+	tests := []struct {
+		rounding    string
+		goal, total int
+		want        int
+	}{
+		{data.RoundCeil, 50, 9, 5},
+		{data.RoundNearest, 50, 9, 5},
+		{data.RoundFloor, 50, 9, 4},
+		{data.RoundCeil, 30, 9, 3},
+		{data.RoundNearest, 30, 9, 3},
+		{data.RoundFloor, 30, 9, 2},
+		{data.RoundNearest, 20, 9, 2},
+		{data.RoundCeil, 50, 10, 5},
+	}
+	for _, tt := range tests {
+		p := &data.Policy{Goal: tt.goal, Rounding: tt.rounding}
+		if got := RequiredDays(p, tt.total); got != tt.want {
+			t.Errorf("%s %d%% of %d: got %d, want %d", tt.rounding, tt.goal, tt.total, got, tt.want)
+		}
+	}
+}
+
+// policyFixture is Jan 6–17, 2025 with a holiday on Jan 10, an office day
+// on Jan 6 and flex credits on Jan 7–9; today is Jan 13.
+func policyFixture() (*data.TimePeriod, *data.BadgeEntryData, *data.HolidayData, *data.VacationData) {
+	tp := &data.TimePeriod{Key: "Jan", Name: "Jan", StartDateRaw: "2025-01-06", EndDateRaw: "2025-01-17"}
+	_ = tp.ParseDates()
+	badges, holidays, vacations := emptyData()
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-06", IsBadgedIn: true})
+	for _, d := range []string{"2025-01-07", "2025-01-08", "2025-01-09"} {
+		badges.Add(data.BadgeEntry{EntryDate: d, IsBadgedIn: true, IsFlexCredit: true})
+	}
+	holidays.Add(data.Holiday{Name: "Closed", Date: "2025-01-10"})
+	return tp, badges, holidays, vacations
+}
+
+func TestCalculatePolicyStatsFlex(t *testing.T) {
+	tp, badges, holidays, vacations := policyFixture()
+	today := parseDate("2025-01-13")
+
+	policy := data.DefaultPolicy(50)
+	policy.Flex.Cap = 2
+	stats, _ := CalculatePolicyStats(tp, badges, holidays, vacations, policy, &today)
+	if stats.DaysBadgedIn != 3 || stats.FlexDays != 2 || stats.FlexNotCounted != 1 {
+		t.Errorf("cap 2: got %d badged, %d flex, %d not counted", stats.DaysBadgedIn, stats.FlexDays, stats.FlexNotCounted)
+	}
+	if wd := stats.WorkdayStats["2025-01-09"]; !wd.IsFlexCredit || wd.IsBadgedIn {
+		t.Errorf("the latest flex credit should be the one over the cap: %+v", wd)
+	}
+
+	off := false
+	policy.Flex = data.FlexPolicy{Count: &off}
+	stats, _ = CalculatePolicyStats(tp, badges, holidays, vacations, policy, &today)
+	if stats.DaysBadgedIn != 1 || stats.FlexDays != 0 || stats.FlexNotCounted != 3 {
+		t.Errorf("flex off: got %d badged, %d flex, %d not counted", stats.DaysBadgedIn, stats.FlexDays, stats.FlexNotCounted)
+	}
+}
+
+func TestCalculatePolicyStatsFlexWeight(t *testing.T) {
+	tp, badges, holidays, vacations := policyFixture()
+	today := parseDate("2025-01-13")
+
+	// 1 office day plus 3 half-weight flex credits of 5 required.
+	policy := data.DefaultPolicy(50)
+	policy.Flex.Weight = 0.5
+	stats, _ := CalculatePolicyStats(tp, badges, holidays, vacations, policy, &today)
+	if stats.DaysBadgedIn != 4 || stats.FlexDays != 3 || stats.Credits != 2.5 || stats.FlexWeight != 0.5 {
+		t.Errorf("got %d badged, %d flex, %g credits", stats.DaysBadgedIn, stats.FlexDays, stats.Credits)
+	}
+	if stats.DaysStillNeeded != 3 || stats.DaysAheadOfPace != 0 {
+		t.Errorf("got %d still needed, %+d pace", stats.DaysStillNeeded, stats.DaysAheadOfPace)
+	}
+	if wd := stats.WorkdayStats["2025-01-07"]; wd.Credit != 0.5 {
+		t.Errorf("a flex day should credit its weight, got %g", wd.Credit)
+	}
+}
+
+func TestCalculatePolicyStatsPartialDays(t *testing.T) {
+	tp, badges, holidays, vacations := policyFixture()
+	today := parseDate("2025-01-14")
+	half := data.BadgeEntry{EntryDate: "2025-01-13", IsBadgedIn: true, Credit: data.HalfDay}
+	badges.Add(half)
+	flexHalf := data.BadgeEntry{EntryDate: "2025-01-14", IsBadgedIn: true, IsFlexCredit: true, Credit: data.HalfDay}
+	badges.Add(flexHalf)
+
+	// 1 office day, 3 full and 1 half flex credit at 0.5 each, and a half office day.
+	policy := data.DefaultPolicy(50)
+	policy.Flex.Weight = 0.5
+	stats, _ := CalculatePolicyStats(tp, badges, holidays, vacations, policy, &today)
+	if stats.DaysBadgedIn != 6 || stats.PartialDays != 2 || stats.Credits != 3.25 {
+		t.Errorf("got %d badged, %d partial, %g credits", stats.DaysBadgedIn, stats.PartialDays, stats.Credits)
+	}
+	if stats.DaysStillNeeded != 2 {
+		t.Errorf("got %d still needed, want 2", stats.DaysStillNeeded)
+	}
+	if wd := stats.WorkdayStats["2025-01-13"]; !wd.IsPartial || wd.Credit != 0.5 {
+		t.Errorf("a half office day should credit 0.5, got %+v", wd)
+	}
+	if wd := stats.WorkdayStats["2025-01-14"]; !wd.IsPartial || wd.Credit != 0.25 {
+		t.Errorf("a half flex day should credit half the weight, got %+v", wd)
+	}
+}
+
+func TestCalculatePolicyStatsLeaveTypes(t *testing.T) {
+	tp, badges, holidays, vacations := policyFixture()
+	today := parseDate("2025-01-13")
+	vacations.Add(data.Vacation{Destination: "Flu", StartDate: "2025-01-13", EndDate: "2025-01-13", Type: data.LeaveSick})
+	vacations.Add(data.Vacation{Destination: "Austin", StartDate: "2025-01-14", EndDate: "2025-01-15", Type: data.LeaveTravel})
+	vacations.Add(data.Vacation{Destination: "Personal", StartDate: "2025-01-16", EndDate: "2025-01-16", Type: data.LeaveUnpaid})
+
+	// 9 days less 1 sick; 1 office day, 3 flex credits and 2 travel days.
+	stats, _ := CalculatePolicyStats(tp, badges, holidays, vacations, data.DefaultPolicy(50), &today)
+	if stats.TotalDays != 8 || stats.VacationDays != 1 || stats.DaysBadgedIn != 6 || stats.LeaveAttended != 2 {
+		t.Errorf("got %d total, %d excluded, %d badged, %d attended",
+			stats.TotalDays, stats.VacationDays, stats.DaysBadgedIn, stats.LeaveAttended)
+	}
+	want := map[data.LeaveType]int{data.LeaveSick: 1, data.LeaveTravel: 2, data.LeaveUnpaid: 1}
+	for lt, n := range want {
+		if stats.LeaveDays[lt] != n {
+			t.Errorf("%s: got %d days, want %d", lt, stats.LeaveDays[lt], n)
+		}
+	}
+	if wd := stats.WorkdayStats["2025-01-16"]; wd.IsVacation || wd.IsBadgedIn || wd.Leave != data.LeaveUnpaid {
+		t.Errorf("unpaid leave should stay a working day without credit: %+v", wd)
+	}
+
+	policy := data.DefaultPolicy(50)
+	policy.Leave = map[data.LeaveType]string{data.LeaveSick: data.LeaveAbsent, data.LeaveTravel: data.LeaveExclude}
+	stats, _ = CalculatePolicyStats(tp, badges, holidays, vacations, policy, &today)
+	if stats.TotalDays != 7 || stats.VacationDays != 2 || stats.DaysBadgedIn != 4 || stats.LeaveAttended != 0 {
+		t.Errorf("overridden rules: got %d total, %d excluded, %d badged, %d attended",
+			stats.TotalDays, stats.VacationDays, stats.DaysBadgedIn, stats.LeaveAttended)
+	}
+}
+
+func TestCalculatePolicyStatsHolidays(t *testing.T) {
+	tp, badges, holidays, vacations := policyFixture()
+	today := parseDate("2025-01-13")
+
+	stats, _ := CalculatePolicyStats(tp, badges, holidays, vacations, data.DefaultPolicy(50), &today)
+	if stats.TotalDays != 9 || stats.Holidays != 1 {
+		t.Errorf("excluded holidays: got %d total days, %d holidays", stats.TotalDays, stats.Holidays)
+	}
+
+	policy := data.DefaultPolicy(50)
+	policy.Holidays = data.HolidaysInclude
+	stats, _ = CalculatePolicyStats(tp, badges, holidays, vacations, policy, &today)
+	if stats.TotalDays != 10 || stats.DaysThusFar != 5 || stats.Holidays != 1 || stats.DaysRequired != 5 {
+		t.Errorf("included holidays: got %d total, %d so far, %d holidays, %d required",
+			stats.TotalDays, stats.DaysThusFar, stats.Holidays, stats.DaysRequired)
+	}
+}
+
+func TestCalculatePolicyStatsStatusBands(t *testing.T) {
+	tp, badges, holidays, vacations := policyFixture()
+	today := parseDate("2025-01-13")
+	two, zero := 2, 0
+	policy := data.DefaultPolicy(50)
+	policy.Name = "Division"
+	policy.StatusBands = []data.StatusBand{
+		{Name: "Ahead", MinPace: &two},
+		{Name: "On Track", MinPace: &zero},
+		{Name: "Behind"},
+	}
+
+	// 4 of 9 days so far, 5 required: 2 expected, 4 badged in.
+	stats, _ := CalculatePolicyStats(tp, badges, holidays, vacations, policy, &today)
+	if stats.DaysAheadOfPace != 2 || stats.ComplianceStatus != "Ahead" || stats.Policy != "Division" {
+		t.Errorf("got %+d, %q under %q", stats.DaysAheadOfPace, stats.ComplianceStatus, stats.Policy)
+	}
+
+	off := false
+	policy.Flex.Count = &off
+	stats, _ = CalculatePolicyStats(tp, badges, holidays, vacations, policy, &today)
+	if stats.DaysAheadOfPace != -1 || stats.ComplianceStatus != "Behind" {
+		t.Errorf("got %+d, %q", stats.DaysAheadOfPace, stats.ComplianceStatus)
+	}
+
+	if b := StatusBand(&data.Policy{}, -5); b.Name != "At Risk" {
+		t.Errorf("a policy without bands should use the defaults, got %q", b.Name)
+	}
+}
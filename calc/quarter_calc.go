@@ -9,13 +9,22 @@ import (
 
 // PeriodStats contains all computed statistics for a single time period.
 type PeriodStats struct {
+	Key       string
 	Name      string
 	StartDate time.Time
 	EndDate   time.Time
+	Policy    string // name of the policy evaluated
+	GoalPct   int
 
 	// Counts
 	DaysBadgedIn      int
-	FlexDays          int
+	FlexDays          int     // flex credits counted in DaysBadgedIn
+	FlexNotCounted    int     // flex credits the policy didn't count
+	FlexCap           int     // flex credits the policy counts in this period; 0 means no limit
+	FlexWeight        float64 // days each counted flex credit is worth
+	PartialDays       int     // counted days credited for less than a full day
+	LeaveAttended     int     // leave days counted as office days in DaysBadgedIn, e.g. business travel
+	Credits           float64 // office days plus weighted flex credits, measured against DaysRequired
 	DaysThusFar       int
 	DaysLeft          int
 	TotalDays         int
@@ -27,7 +36,8 @@ type PeriodStats struct {
 	DaysStillNeeded       int
 	DaysOff               int
 	Holidays              int
-	VacationDays          int
+	VacationDays          int                    // leave days excluded from TotalDays, of any type
+	LeaveDays             map[data.LeaveType]int // workdays of leave by type, whatever the policy does with them
 	DaysAheadOfPace       int
 	RemainingMissableDays int
 
@@ -41,12 +51,33 @@ type PeriodStats struct {
 	// Projection
 	ProjectedCompletionDate *time.Time
 
+	// Planned office days from today on, and badge-ins dated after today,
+	// aren't counted above. The projection adds them to Credits;
+	// PlannedCompletionDate is the planned day that reaches the goal, nil if
+	// none does. Planned days that passed without a badge-in are MissedPlans.
+	PlannedDays           int
+	ProjectedCredits      float64
+	PlannedCompletionDate *time.Time
+	MissedPlans           int
+
+	// Leave that isn't approved yet counts like approved leave above.
+	// Committed holds the stats without it — what the period looks like
+	// if the requests are denied — and is nil when none is pending.
+	PendingLeaveDays int
+	Committed        *PeriodStats
+
 	// Per-day status map
 	WorkdayStats map[string]*Workday
+
+	// Weekly minimum, set by CalculateWeeks
+	WeeklyMinimum int // office days required per full week; 0 if no weekly policy
+	Weeks         []WeekStats
+	WeeksMet      int
+	WeeksMissed   int
 }
 
-// CalculatePeriodStats computes full statistics for a time period.
-// goalPct is the required office percentage (e.g. 50 means 50%).
+// CalculatePeriodStats computes full statistics for a time period under the
+// default policy. goalPct is the required office percentage (e.g. 50 means 50%).
 func CalculatePeriodStats(
 	period *data.TimePeriod,
 	badges *data.BadgeEntryData,
@@ -54,6 +85,20 @@ func CalculatePeriodStats(
 	vacations *data.VacationData,
 	goalPct int,
 	today *time.Time,
+) (*PeriodStats, error) {
+	return CalculatePolicyStats(period, badges, holidays, vacations, data.DefaultPolicy(goalPct), today)
+}
+
+// CalculatePolicyStats computes full statistics for a time period under
+// policy: its goal and rounding, which flex credits count, whether holidays
+// are in the total, and its status bands.
+func CalculatePolicyStats(
+	period *data.TimePeriod,
+	badges *data.BadgeEntryData,
+	holidays *data.HolidayData,
+	vacations *data.VacationData,
+	policy *data.Policy,
+	today *time.Time,
 ) (*PeriodStats, error) {
 	now := time.Now()
 	if today != nil {
@@ -79,69 +124,113 @@ func CalculatePeriodStats(
 	totalDays := 0
 	daysBadgedIn := 0
 	flexDays := 0
+	flexNotCounted := 0
+	partialDays := 0
+	leaveAttended := 0
+	credits := 0.0
 	daysThusFar := 0
 	holidayCount := 0
 	vacationDays := 0
+	leaveDays := map[data.LeaveType]int{}
+	pendingLeaveDays := 0
+	missedPlans := 0
+	var plannedDates []time.Time
+	var plannedDayCredits []float64
+	plannedCredits := 0.0
+
+	// In date order, so a flex cap keeps the earliest credits.
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		dateKey := d.Format(data.BadgeDateFormat)
+		wd, ok := wdMap[dateKey]
+		if !ok {
+			continue
+		}
+		availableWorkdays++
 
-	for dateKey, wd := range wdMap {
 		if _, isHoliday := holidayMap[dateKey]; isHoliday {
 			wd.IsHoliday = true
 			holidayCount++
-			availableWorkdays++
-			continue
+			if policy.Holidays != data.HolidaysInclude {
+				continue
+			}
+		} else if vac, isVacation := vacationMap[dateKey]; isVacation {
+			wd.Leave = vac.Leave()
+			leaveDays[wd.Leave]++
+			if !vac.Approved {
+				pendingLeaveDays++
+			}
+			if policy.LeaveRule(wd.Leave) == data.LeaveExclude {
+				wd.IsVacation = true
+				vacationDays++
+				continue
+			}
 		}
 
-		availableWorkdays++
+		totalDays++
+		if wd.Date.Before(now) {
+			daysThusFar++
+		}
 
-		if _, isVacation := vacationMap[dateKey]; isVacation {
-			wd.IsVacation = true
-			vacationDays++
+		// Attended leave is a full office day whatever was badged.
+		if wd.Leave != "" && policy.LeaveRule(wd.Leave) == data.LeaveAttend {
+			wd.IsBadgedIn = true
+			wd.Credit = 1
+			daysBadgedIn++
+			leaveAttended++
+			credits++
 			continue
 		}
 
-		totalDays++
-
-		if wd.Date.After(now) {
-			if entry, ok := badgeMap[dateKey]; ok && entry.IsBadgedIn {
-				wd.IsBadgedIn = true
-				daysBadgedIn++
+		// A badge-in dated after today hasn't happened yet, so it is
+		// projected like a planned day.
+		entry, ok := badgeMap[dateKey]
+		if ok && entry.IsBadgedIn && !entry.Planned && wd.Date.After(now) {
+			entry.Planned = true
+		}
+		if ok && entry.Planned {
+			if wd.Date.Before(now) {
+				wd.IsMissedPlan = true
+				missedPlans++
+			} else {
+				wd.IsPlanned = true
+				credit := entry.DayCredit()
 				if entry.IsFlexCredit {
-					wd.IsFlexCredit = true
-					flexDays++
+					credit *= policy.Flex.Credit()
 				}
+				plannedDates = append(plannedDates, wd.Date)
+				plannedDayCredits = append(plannedDayCredits, credit)
+				plannedCredits += credit
 			}
 			continue
 		}
-
-		isToday := wd.Date.Equal(now)
-		if isToday {
-			if entry, ok := badgeMap[dateKey]; ok && entry.IsBadgedIn {
-				wd.IsBadgedIn = true
-				daysBadgedIn++
-				if entry.IsFlexCredit {
-					wd.IsFlexCredit = true
-					flexDays++
-				}
-			}
-			wdMap[dateKey] = wd
+		if !ok || !entry.IsBadgedIn {
 			continue
 		}
-
-		daysThusFar++
-
-		if entry, ok := badgeMap[dateKey]; ok && entry.IsBadgedIn {
-			wd.IsBadgedIn = true
-			daysBadgedIn++
-			if entry.IsFlexCredit {
-				wd.IsFlexCredit = true
-				flexDays++
+		if entry.IsFlexCredit {
+			wd.IsFlexCredit = true
+			if !policy.Flex.Allows(flexDays) {
+				flexNotCounted++
+				continue
 			}
+			flexDays++
+			wd.Credit = policy.Flex.Credit() * entry.DayCredit()
+		} else {
+			wd.Credit = entry.DayCredit()
 		}
+		if entry.IsPartial() {
+			wd.IsPartial = true
+			partialDays++
+		}
+		wd.IsBadgedIn = true
+		daysBadgedIn++
+		credits += wd.Credit
 	}
+	// Fractions like 0.3 don't add up exactly in binary.
+	credits = math.Round(credits*1e6) / 1e6
 
 	daysLeft := totalDays - daysThusFar
-	daysRequired := int(math.Ceil(float64(totalDays) * float64(goalPct) / 100.0))
-	daysStillNeeded := daysRequired - daysBadgedIn
+	daysRequired := RequiredDays(policy, totalDays)
+	daysStillNeeded := int(math.Ceil(float64(daysRequired) - credits))
 	if daysStillNeeded < 0 {
 		daysStillNeeded = 0
 	}
@@ -150,14 +239,14 @@ func CalculatePeriodStats(
 	daysAheadOfPace := 0
 	if daysThusFar > 0 && totalDays > 0 {
 		expectedBadgeIns := int(math.Round(float64(daysThusFar) * float64(daysRequired) / float64(totalDays)))
-		daysAheadOfPace = daysBadgedIn - expectedBadgeIns
+		daysAheadOfPace = int(math.Floor(credits)) - expectedBadgeIns
 	}
 
 	remainingMissable := daysLeft - daysStillNeeded
 
 	currentAverage := 0.0
 	if daysThusFar > 0 {
-		currentAverage = float64(daysBadgedIn) / float64(daysThusFar)
+		currentAverage = credits / float64(daysThusFar)
 	}
 
 	requiredFutureAverage := 0.0
@@ -165,11 +254,24 @@ func CalculatePeriodStats(
 		requiredFutureAverage = float64(daysStillNeeded) / float64(daysLeft)
 	}
 
-	complianceStatus := determineComplianceStatus(daysBadgedIn, daysRequired, daysAheadOfPace, daysStillNeeded, daysLeft, end, now)
+	projectedCredits := math.Round((credits+plannedCredits)*1e6) / 1e6
+	var plannedDate *time.Time
+	if credits < float64(daysRequired) {
+		running := credits
+		for i, d := range plannedDates {
+			running += plannedDayCredits[i]
+			if running >= float64(daysRequired)-1e-6 {
+				plannedDate = &d
+				break
+			}
+		}
+	}
+
+	complianceStatus := complianceStatus(policy, credits, daysRequired, daysAheadOfPace, daysStillNeeded, daysLeft)
 
 	var projectedDate *time.Time
 	if daysBadgedIn > 0 && daysThusFar > 0 && daysStillNeeded > 0 {
-		rate := float64(daysBadgedIn) / float64(daysThusFar)
+		rate := credits / float64(daysThusFar)
 		if rate > 0 {
 			estimatedDays := int(math.Ceil(float64(daysStillNeeded) / rate))
 			proj := now.AddDate(0, 0, estimatedDays)
@@ -177,12 +279,21 @@ func CalculatePeriodStats(
 		}
 	}
 
-	return &PeriodStats{
+	stats := &PeriodStats{
+		Key:                     period.Key,
 		Name:                    period.Name,
 		StartDate:               start,
 		EndDate:                 end,
+		Policy:                  policy.Name,
+		GoalPct:                 policy.Goal,
 		DaysBadgedIn:            daysBadgedIn,
 		FlexDays:                flexDays,
+		FlexNotCounted:          flexNotCounted,
+		FlexCap:                 policy.Flex.Cap,
+		FlexWeight:              policy.Flex.Credit(),
+		PartialDays:             partialDays,
+		LeaveAttended:           leaveAttended,
+		Credits:                 credits,
 		DaysThusFar:             daysThusFar,
 		DaysLeft:                daysLeft,
 		TotalDays:               totalDays,
@@ -193,6 +304,7 @@ func CalculatePeriodStats(
 		DaysOff:                 daysOff,
 		Holidays:                holidayCount,
 		VacationDays:            vacationDays,
+		LeaveDays:               leaveDays,
 		DaysAheadOfPace:         daysAheadOfPace,
 		RemainingMissableDays:   remainingMissable,
 		CurrentAverage:          currentAverage,
@@ -200,29 +312,24 @@ func CalculatePeriodStats(
 		ComplianceStatus:        complianceStatus,
 		ProjectedCompletionDate: projectedDate,
 		WorkdayStats:            wdMap,
-	}, nil
-}
-
-func determineComplianceStatus(
-	daysBadgedIn, daysRequired, daysAheadOfPace, daysStillNeeded, daysLeft int,
-	periodEnd, today time.Time,
-) string {
-	if daysBadgedIn >= daysRequired {
-		return "Achieved"
-	}
-	if daysAheadOfPace == 0 && daysBadgedIn == 0 {
-		return "On Track"
+		PendingLeaveDays:        pendingLeaveDays,
+		PlannedDays:             len(plannedDates),
+		ProjectedCredits:        projectedCredits,
+		PlannedCompletionDate:   plannedDate,
+		MissedPlans:             missedPlans,
 	}
-	if daysStillNeeded > daysLeft {
-		return "Impossible"
-	}
-	if daysAheadOfPace < 0 {
-		return "At Risk"
+	if pendingLeaveDays > 0 {
+		committed, err := CalculatePolicyStats(period, badges, holidays, vacations.Approved(), policy, today)
+		if err != nil {
+			return nil, err
+		}
+		stats.Committed = committed
 	}
-	return "On Track"
+	return stats, nil
 }
 
-// CalculateYearStats computes aggregate statistics across all time periods in a calendar year.
+// CalculateYearStats computes aggregate statistics across all time periods
+// in a calendar year under the default policy.
 func CalculateYearStats(
 	periods []*data.TimePeriod,
 	badges *data.BadgeEntryData,
@@ -230,6 +337,20 @@ func CalculateYearStats(
 	vacations *data.VacationData,
 	goalPct int,
 	today *time.Time,
+) (*PeriodStats, error) {
+	return CalculateYearPolicyStats(periods, badges, holidays, vacations, data.DefaultPolicy(goalPct), today)
+}
+
+// CalculateYearPolicyStats computes aggregate statistics across all time
+// periods in a calendar year under policy. A flex cap is per period, so the
+// year allows the cap once for each period.
+func CalculateYearPolicyStats(
+	periods []*data.TimePeriod,
+	badges *data.BadgeEntryData,
+	holidays *data.HolidayData,
+	vacations *data.VacationData,
+	policy *data.Policy,
+	today *time.Time,
 ) (*PeriodStats, error) {
 	if len(periods) == 0 {
 		return nil, nil
@@ -255,7 +376,9 @@ func CalculateYearStats(
 		EndDateRaw:   end.Format("2006-01-02"),
 	}
 
-	stats, err := CalculatePeriodStats(syntheticTP, badges, holidays, vacations, goalPct, today)
+	yearPolicy := *policy
+	yearPolicy.Flex.Cap *= len(periods)
+	stats, err := CalculatePolicyStats(syntheticTP, badges, holidays, vacations, &yearPolicy, today)
 	if err != nil {
 		return nil, err
 	}
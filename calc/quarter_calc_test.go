@@ -207,6 +207,100 @@ func TestVacationDaysExcludedFromTotal(t *testing.T) {
 	}
 }
 
+func TestPendingLeaveCommittedStats(t *testing.T) {
+	q := makeQ1()
+	badges, holidays, vacations := emptyData()
+	vacations.Add(data.Vacation{Destination: "Beach", StartDate: "2025-01-06", EndDate: "2025-01-10", Approved: true})
+	vacations.Add(data.Vacation{Destination: "Ski", StartDate: "2025-02-03", EndDate: "2025-02-07"})
+
+	today := parseDate("2025-01-31")
+	stats, err := CalculatePeriodStats(q, badges, holidays, vacations, 50, &today)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.VacationDays != 10 || stats.PendingLeaveDays != 5 {
+		t.Errorf("expected 10 vacation days, 5 pending; got %d, %d", stats.VacationDays, stats.PendingLeaveDays)
+	}
+	c := stats.Committed
+	if c == nil {
+		t.Fatal("expected committed stats with leave pending")
+	}
+	if c.VacationDays != 5 || c.TotalDays != stats.TotalDays+5 || c.PendingLeaveDays != 0 || c.Committed != nil {
+		t.Errorf("committed stats should drop the pending week: %d vacation, %d total", c.VacationDays, c.TotalDays)
+	}
+	if c.DaysRequired <= stats.DaysRequired {
+		t.Errorf("expected more days required if the leave is denied: %d vs %d", c.DaysRequired, stats.DaysRequired)
+	}
+
+	vacations.Update(1, data.Vacation{Destination: "Ski", StartDate: "2025-02-03", EndDate: "2025-02-07", Approved: true})
+	stats, _ = CalculatePeriodStats(q, badges, holidays, vacations, 50, &today)
+	if stats.PendingLeaveDays != 0 || stats.Committed != nil {
+		t.Errorf("expected no committed stats once everything is approved, got %d pending", stats.PendingLeaveDays)
+	}
+}
+
+func TestPlannedDaysStats(t *testing.T) {
+	q := makeQ1()
+	badges, holidays, vacations := emptyData()
+	badges.Add(data.NewOfficeBadge(parseDate("2025-01-02"), "HQ"))
+	badges.Add(data.NewOfficeBadge(parseDate("2025-01-03"), "HQ"))
+	for _, d := range []string{"2025-01-06", "2025-01-20", "2025-01-21", "2025-01-22", "2025-01-23", "2025-01-24", "2025-01-27"} {
+		badges.Add(data.NewPlannedBadge(parseDate(d), "HQ"))
+	}
+
+	// 10% of 64 days is 7 required; the 2 badge-ins and the first 5 planned
+	// days from today on reach it.
+	today := parseDate("2025-01-15")
+	stats, err := CalculatePeriodStats(q, badges, holidays, vacations, 10, &today)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.DaysBadgedIn != 2 || stats.Credits != 2 || stats.DaysStillNeeded != 5 {
+		t.Errorf("planned days shouldn't count as badged in: %d badged, %g credits, %d needed",
+			stats.DaysBadgedIn, stats.Credits, stats.DaysStillNeeded)
+	}
+	if stats.PlannedDays != 6 || stats.MissedPlans != 1 || stats.ProjectedCredits != 8 {
+		t.Errorf("expected 6 planned, 1 missed, 8 projected; got %d, %d, %g",
+			stats.PlannedDays, stats.MissedPlans, stats.ProjectedCredits)
+	}
+	if d := stats.PlannedCompletionDate; d == nil || !d.Equal(parseDate("2025-01-24")) {
+		t.Errorf("expected the plan to reach the goal on 2025-01-24, got %v", d)
+	}
+	if wd := stats.WorkdayStats["2025-01-06"]; !wd.IsMissedPlan || wd.IsPlanned {
+		t.Errorf("a past planned day should be a missed plan: %+v", wd)
+	}
+	if wd := stats.WorkdayStats["2025-01-20"]; !wd.IsPlanned || wd.IsBadgedIn || wd.Credit != 0 {
+		t.Errorf("expected a planned day: %+v", wd)
+	}
+}
+
+func TestFutureBadgeInCountsAsPlanned(t *testing.T) {
+	q := makeQ1()
+	badges, holidays, vacations := emptyData()
+	badges.Add(data.NewOfficeBadge(parseDate("2025-01-14"), "HQ"))
+	badges.Add(data.NewOfficeBadge(parseDate("2025-01-15"), "HQ"))
+	for _, d := range []string{"2025-01-20", "2025-01-21", "2025-01-22"} {
+		badges.Add(data.NewOfficeBadge(parseDate(d), "HQ"))
+	}
+
+	today := parseDate("2025-01-15")
+	stats, err := CalculatePeriodStats(q, badges, holidays, vacations, 50, &today)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.DaysBadgedIn != 2 || stats.Credits != 2 {
+		t.Errorf("future badge-ins shouldn't count as attended: %d badged, %g credits",
+			stats.DaysBadgedIn, stats.Credits)
+	}
+	if stats.PlannedDays != 3 || stats.ProjectedCredits != 5 {
+		t.Errorf("expected 3 planned days projecting 5 credits, got %d, %g",
+			stats.PlannedDays, stats.ProjectedCredits)
+	}
+	if wd := stats.WorkdayStats["2025-01-20"]; !wd.IsPlanned || wd.IsBadgedIn {
+		t.Errorf("expected a future badge-in to show as planned: %+v", wd)
+	}
+}
+
 func TestDaysRequired50Percent(t *testing.T) {
 	q := makeQ1()
 	badges, holidays, vacations := emptyData()
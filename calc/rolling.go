@@ -0,0 +1,154 @@
+package calc
+
+import (
+	"math"
+	"time"
+
+	"rto/data"
+)
+
+// RollingDay is the attendance over the trailing window ending on Date.
+type RollingDay struct {
+	Date        time.Time
+	WindowStart time.Time
+	Available   int     // working days in the window that count toward the goal
+	Required    int     // RequiredDays of Available
+	BadgedIn    float64 // office days credited, as in PeriodStats.Credits
+	Rate        float64 // BadgedIn / Available; 0 when nothing is available
+
+	// BelowGoalOn is the first day on or after Date whose window falls
+	// below the goal if there are no badge-ins after Date. It is zero if
+	// that never happens, e.g. with a goal of 0%.
+	BelowGoalOn time.Time
+}
+
+// Compliant reports whether the window meets the goal.
+func (d RollingDay) Compliant() bool {
+	return d.BadgedIn >= float64(d.Required)
+}
+
+// RollingStats is a rolling-window evaluation: the trailing rate for every
+// day in a range, and for today.
+type RollingStats struct {
+	WindowDays int
+	GoalPct    int
+	Today      RollingDay
+	Days       []RollingDay // one per calendar day in the requested range
+}
+
+// CalculateRolling evaluates a rolling window of windowDays calendar days
+// against policy for every day in [from, to] and for today. Unlike
+// CalculatePolicyStats it ignores period boundaries: each window reaches
+// back as far as it needs to. Partial days count at their fraction and flex
+// credits at the policy's weight; a flex cap, being per period, doesn't
+// apply. Badge-ins dated after today don't count, even in windows that end
+// after today.
+func CalculateRolling(
+	badges *data.BadgeEntryData,
+	holidays *data.HolidayData,
+	vacations *data.VacationData,
+	policy *data.Policy,
+	windowDays int,
+	from, to, today time.Time,
+) *RollingStats {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	first, last := from, to
+	if today.Before(first) {
+		first = today
+	}
+	if today.After(last) {
+		last = today
+	}
+	// Look back one window for the earliest day and ahead two for the
+	// below-goal projection of the latest.
+	lo := first.AddDate(0, 0, 1-windowDays)
+	hi := last.AddDate(0, 0, 2*windowDays)
+
+	badgeMap := badges.GetBadgeMap(lo, hi)
+	holidayMap := holidays.GetHolidayMap()
+	vacationMap := vacations.GetVacationMap()
+
+	// Prefix sums of available days and credits; index i is lo+i.
+	n := int(hi.Sub(lo).Hours()/24) + 1
+	avail := make([]int, n+1)
+	badged := make([]float64, n+1)
+	for i := 0; i < n; i++ {
+		d := lo.AddDate(0, 0, i)
+		key := d.Format(data.BadgeDateFormat)
+		avail[i+1], badged[i+1] = avail[i], badged[i]
+		_, isHoliday := holidayMap[key]
+		rule := ""
+		if vac, ok := vacationMap[key]; ok && !isHoliday {
+			rule = policy.LeaveRule(vac.Leave())
+		}
+		off := rule == data.LeaveExclude
+		if isHoliday {
+			off = policy.Holidays != data.HolidaysInclude
+		}
+		if !IsWorkday(d) || off {
+			continue
+		}
+		avail[i+1]++
+		if rule == data.LeaveAttend {
+			badged[i+1]++
+		} else if entry, ok := badgeMap[key]; ok && entry.IsBadgedIn && !d.After(today) {
+			// A badge-in dated after today is planned, as in
+			// CalculatePolicyStats, not attendance.
+			switch {
+			case !entry.IsFlexCredit:
+				badged[i+1] += entry.DayCredit()
+			case policy.Flex.Counts():
+				badged[i+1] += policy.Flex.Credit() * entry.DayCredit()
+			}
+		}
+	}
+
+	required := func(available int) int {
+		return RequiredDays(policy, available)
+	}
+	// windowAt returns the window ending on index e counting only badge-ins
+	// up to index upTo.
+	windowAt := func(e, upTo int) (available int, badgedIn float64) {
+		start := max(e-windowDays+1, 0)
+		available = avail[e+1] - avail[start]
+		if upTo >= start {
+			// Fractions like 0.3 don't add up exactly in binary.
+			badgedIn = math.Round((badged[min(upTo, e)+1]-badged[start])*1e6) / 1e6
+		}
+		return available, badgedIn
+	}
+	dayAt := func(d time.Time) RollingDay {
+		i := int(d.Sub(lo).Hours() / 24)
+		rd := RollingDay{Date: d, WindowStart: d.AddDate(0, 0, 1-windowDays)}
+		rd.Available, rd.BadgedIn = windowAt(i, i)
+		rd.Required = required(rd.Available)
+		if rd.Available > 0 {
+			rd.Rate = rd.BadgedIn / float64(rd.Available)
+		}
+		for e := i; e < n; e++ {
+			a, b := windowAt(e, i)
+			if b < float64(required(a)) {
+				rd.BelowGoalOn = lo.AddDate(0, 0, e)
+				break
+			}
+		}
+		return rd
+	}
+
+	stats := &RollingStats{WindowDays: windowDays, GoalPct: policy.Goal, Today: dayAt(today)}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		stats.Days = append(stats.Days, dayAt(d))
+	}
+	return stats
+}
+
+// Day returns the evaluation of the window ending on date, if it is in
+// range.
+func (s *RollingStats) Day(date time.Time) (RollingDay, bool) {
+	for _, d := range s.Days {
+		if d.Date.Equal(date) {
+			return d, true
+		}
+	}
+	return RollingDay{}, false
+}
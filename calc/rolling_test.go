@@ -0,0 +1,119 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"rto/data"
+)
+
+func TestCalculateRolling(t *testing.T) {
+	badges := data.NewBadgeEntryData()
+	for _, d := range []string{"2025-01-06", "2025-01-07", "2025-01-08", "2025-01-09", "2025-01-10", "2025-01-13"} {
+		badges.Add(data.BadgeEntry{EntryDate: d, IsBadgedIn: true})
+	}
+	holidays := data.NewHolidayData()
+	vacations := data.NewVacationData()
+	date := func(day int) time.Time { return time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC) }
+
+	stats := CalculateRolling(badges, holidays, vacations, data.DefaultPolicy(50), 14, date(6), date(17), date(17))
+	today := stats.Today
+	if !today.WindowStart.Equal(date(4)) || today.Available != 10 || today.BadgedIn != 6 || today.Required != 5 {
+		t.Errorf("unexpected window ending today: %+v", today)
+	}
+	if !today.Compliant() || today.Rate != 0.6 {
+		t.Errorf("expected a compliant 60%% window, got %v", today.Rate)
+	}
+	// Jan 20 still has Jan 7–10 and 13 (5 of 10); Jan 21 drops Jan 7.
+	if !today.BelowGoalOn.Equal(date(21)) {
+		t.Errorf("expected below goal on Jan 21, got %s", today.BelowGoalOn)
+	}
+
+	if len(stats.Days) != 12 {
+		t.Fatalf("expected one entry per day Jan 6–17, got %d", len(stats.Days))
+	}
+	jan10, ok := stats.Day(date(10))
+	if !ok || jan10.Available != 10 || jan10.BadgedIn != 5 || !jan10.Compliant() {
+		t.Errorf("window ending Jan 10 reaches back into December: %+v", jan10)
+	}
+	// As of Jan 6, one badge-in out of Dec 24–Jan 6's 10 working days.
+	if jan6, _ := stats.Day(date(6)); !jan6.BelowGoalOn.Equal(date(6)) {
+		t.Errorf("expected the window ending Jan 6 to already be below goal, got %s", jan6.BelowGoalOn)
+	}
+	if _, ok := stats.Day(date(18)); ok {
+		t.Error("Day should not find a date outside the range")
+	}
+
+	if stats := CalculateRolling(badges, holidays, vacations, data.DefaultPolicy(0), 14, date(6), date(17), date(17)); !stats.Today.BelowGoalOn.IsZero() {
+		t.Errorf("a 0%% goal is never missed, got %s", stats.Today.BelowGoalOn)
+	}
+}
+
+func TestCalculateRollingExcludesTimeOff(t *testing.T) {
+	badges := data.NewBadgeEntryData()
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-02", IsBadgedIn: true})
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-06", IsBadgedIn: true}) // on vacation: ignored
+	holidays := data.NewHolidayData()
+	holidays.Add(data.Holiday{Name: "New Year", Date: "2025-01-01"})
+	vacations := data.NewVacationData()
+	vacations.Add(data.Vacation{Destination: "Trip", StartDate: "2025-01-06", EndDate: "2025-01-08"})
+
+	today := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+	stats := CalculateRolling(badges, holidays, vacations, data.DefaultPolicy(50), 7, today, today, today)
+	// Jan 2–8: Jan 2, 3 available; Jan 6–8 vacation.
+	if stats.Today.Available != 2 || stats.Today.BadgedIn != 1 || stats.Today.Required != 1 {
+		t.Errorf("unexpected window: %+v", stats.Today)
+	}
+
+	vacations = data.NewVacationData()
+	vacations.Add(data.Vacation{Destination: "Austin", StartDate: "2025-01-06", EndDate: "2025-01-07", Type: data.LeaveTravel})
+	vacations.Add(data.Vacation{Destination: "Personal", StartDate: "2025-01-08", EndDate: "2025-01-08", Type: data.LeaveUnpaid})
+	stats = CalculateRolling(badges, holidays, vacations, data.DefaultPolicy(50), 7, today, today, today)
+	// Jan 2–8: 5 available; Jan 2 and both travel days count.
+	if stats.Today.Available != 5 || stats.Today.BadgedIn != 3 {
+		t.Errorf("travel should count and unpaid leave stay available: %+v", stats.Today)
+	}
+}
+
+func TestCalculateRollingCredits(t *testing.T) {
+	badges := data.NewBadgeEntryData()
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-06", IsBadgedIn: true})
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-07", IsBadgedIn: true, Credit: data.HalfDay})
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-08", IsBadgedIn: true, IsFlexCredit: true})
+	holidays := data.NewHolidayData()
+	vacations := data.NewVacationData()
+
+	today := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	policy := data.DefaultPolicy(60)
+	policy.Flex.Weight = 0.5
+	stats := CalculateRolling(badges, holidays, vacations, policy, 5, today, today, today)
+	// Jan 6–10: a full day, a half day and a flex credit at half weight.
+	if stats.Today.Available != 5 || stats.Today.BadgedIn != 2 || stats.Today.Rate != 0.4 {
+		t.Errorf("expected 2 of 5 days credited, got %+v", stats.Today)
+	}
+	if stats.Today.Required != 3 || stats.Today.Compliant() {
+		t.Errorf("2 credits shouldn't meet a requirement of 3: %+v", stats.Today)
+	}
+}
+
+func TestCalculateRollingIgnoresFutureBadgeIns(t *testing.T) {
+	badges := data.NewBadgeEntryData()
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-06", IsBadgedIn: true})
+	holidays := data.NewHolidayData()
+	vacations := data.NewVacationData()
+	date := func(day int) time.Time { return time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC) }
+	policy := data.DefaultPolicy(50)
+
+	before := CalculateRolling(badges, holidays, vacations, policy, 7, date(7), date(10), date(7))
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-09", IsBadgedIn: true})
+	after := CalculateRolling(badges, holidays, vacations, policy, 7, date(7), date(10), date(7))
+
+	if after.Today != before.Today {
+		t.Errorf("a future badge-in changed today's window: got %+v, want %+v", after.Today, before.Today)
+	}
+	for i, d := range after.Days {
+		if d.BadgedIn != before.Days[i].BadgedIn || d.Rate != before.Days[i].Rate {
+			t.Errorf("%s: a future badge-in counted, got %v of %d", d.Date.Format("Jan 2"), d.BadgedIn, d.Available)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package calc
+
+import (
+	"math"
+	"time"
+
+	"rto/data"
+)
+
+// WeekStatus is the outcome of one week under a weekly minimum.
+type WeekStatus string
+
+const (
+	WeekMet        WeekStatus = "met"
+	WeekMissed     WeekStatus = "missed"
+	WeekInProgress WeekStatus = "in_progress" // current week, minimum not yet reached
+	WeekUpcoming   WeekStatus = "upcoming"
+	WeekExempt     WeekStatus = "exempt" // nothing required, e.g. a vacation week
+)
+
+// WeekStats holds the weekly-minimum evaluation of one ISO week.
+type WeekStats struct {
+	Year, Week    int       // ISO year and week number
+	Start, End    time.Time // Monday–Sunday, clipped to the period
+	WorkingDays   int       // scheduled working days in the whole ISO week
+	AvailableDays int       // working days in [Start, End] that aren't holidays or vacation
+	Required      int
+	BadgedIn      float64 // office days credited, as in PeriodStats.Credits
+	Planned       int     // planned office days, not counted in BadgedIn
+	Status        WeekStatus
+}
+
+// CalculateWeeks evaluates a weekly minimum over every ISO week touching
+// the period in stats, using its WorkdayStats, and fills stats.Weeks,
+// WeeksMet and WeeksMissed. Weeks cut by the period's start or end, and
+// weeks with holidays or vacation, have their minimum prorated by
+// policy.Rule().
+func CalculateWeeks(stats *PeriodStats, policy data.WeeklyMinimum, today time.Time) {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	stats.Weeks = nil
+	stats.WeeksMet, stats.WeeksMissed = 0, 0
+	stats.WeeklyMinimum = policy.Days
+	if !policy.Enabled() {
+		return
+	}
+
+	for monday := isoMonday(stats.StartDate); !monday.After(stats.EndDate); monday = monday.AddDate(0, 0, 7) {
+		sunday := monday.AddDate(0, 0, 6)
+		w := WeekStats{Start: monday, End: sunday}
+		w.Year, w.Week = monday.ISOWeek()
+		if w.Start.Before(stats.StartDate) {
+			w.Start = stats.StartDate
+		}
+		if w.End.After(stats.EndDate) {
+			w.End = stats.EndDate
+		}
+
+		for d := monday; !d.After(sunday); d = d.AddDate(0, 0, 1) {
+			if data.IsWorkday(d) {
+				w.WorkingDays++
+			}
+			wd, ok := stats.WorkdayStats[d.Format(data.BadgeDateFormat)]
+			if !ok || wd.IsHoliday || wd.IsVacation {
+				continue
+			}
+			w.AvailableDays++
+			if wd.IsBadgedIn {
+				w.BadgedIn += wd.Credit
+			} else if wd.IsPlanned {
+				w.Planned++
+			}
+		}
+		w.BadgedIn = math.Round(w.BadgedIn*1e6) / 1e6
+		w.Required = weeklyRequirement(policy, w.WorkingDays, w.AvailableDays)
+
+		switch {
+		case w.Required == 0:
+			w.Status = WeekExempt
+		case w.BadgedIn >= float64(w.Required):
+			w.Status = WeekMet
+		case w.End.Before(today):
+			w.Status = WeekMissed
+		case w.Start.After(today):
+			w.Status = WeekUpcoming
+		default:
+			w.Status = WeekInProgress
+		}
+		// A week isn't Met until it has started.
+		if w.Status == WeekMet && w.Start.After(today) {
+			w.Status = WeekUpcoming
+		}
+
+		switch w.Status {
+		case WeekMet:
+			stats.WeeksMet++
+		case WeekMissed:
+			stats.WeeksMissed++
+		}
+		stats.Weeks = append(stats.Weeks, w)
+	}
+}
+
+// WeekOf returns the evaluated week containing date, if any.
+func (s *PeriodStats) WeekOf(date time.Time) (WeekStats, bool) {
+	year, week := date.ISOWeek()
+	for _, w := range s.Weeks {
+		if w.Year == year && w.Week == week {
+			return w, true
+		}
+	}
+	return WeekStats{}, false
+}
+
+// weeklyRequirement prorates the weekly minimum for a week with scheduled
+// working days, of which available can be worked in the office.
+func weeklyRequirement(policy data.WeeklyMinimum, scheduled, available int) int {
+	if available <= 0 || scheduled <= 0 {
+		return 0
+	}
+	req := policy.Days
+	switch policy.Rule() {
+	case data.ProrateProportional:
+		if available < scheduled {
+			req = int(math.Round(float64(policy.Days) * float64(available) / float64(scheduled)))
+		}
+	case data.ProrateSubtract:
+		req = policy.Days - (scheduled - available)
+	}
+	if req > available {
+		req = available
+	}
+	if req < 0 {
+		req = 0
+	}
+	return req
+}
+
+// isoMonday returns the Monday starting d's ISO week.
+func isoMonday(d time.Time) time.Time {
+	offset := (int(d.Weekday()) + 6) % 7 // days since Monday
+	return time.Date(d.Year(), d.Month(), d.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
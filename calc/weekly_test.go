@@ -0,0 +1,123 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"rto/data"
+)
+
+func weeklyTestStats(t *testing.T) *PeriodStats {
+	t.Helper()
+	tp := &data.TimePeriod{Key: "Jan", Name: "Jan", StartDateRaw: "2025-01-01", EndDateRaw: "2025-01-31"}
+	_ = tp.ParseDates()
+
+	badges := data.NewBadgeEntryData()
+	for _, d := range []string{"2025-01-02", "2025-01-03", "2025-01-06", "2025-01-13", "2025-01-14",
+		"2025-01-27", "2025-01-28", "2025-01-29"} {
+		badges.Add(data.BadgeEntry{EntryDate: d, IsBadgedIn: true})
+	}
+	holidays := data.NewHolidayData()
+	holidays.Add(data.Holiday{Name: "New Year", Date: "2025-01-01"})
+	vacations := data.NewVacationData()
+	vacations.Add(data.Vacation{Destination: "Trip", StartDate: "2025-01-20", EndDate: "2025-01-24"})
+
+	today := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	stats, err := CalculatePeriodStats(tp, badges, holidays, vacations, 50, &today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stats
+}
+
+func TestCalculateWeeks(t *testing.T) {
+	stats := weeklyTestStats(t)
+	today := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	CalculateWeeks(stats, data.WeeklyMinimum{Days: 3}, today)
+
+	want := []struct {
+		week, available, required, badged int
+		status                            WeekStatus
+	}{
+		{1, 2, 1, 2, WeekMet},        // Jan 1–5: cut by the period start, Jan 1 holiday
+		{2, 5, 3, 1, WeekMissed},     // Jan 6–12
+		{3, 5, 3, 2, WeekInProgress}, // Jan 13–19: today
+		{4, 0, 0, 0, WeekExempt},     // Jan 20–26: vacation
+		{5, 5, 3, 0, WeekUpcoming},   // Jan 27–31: future badge-ins are planned, not counted
+	}
+	if len(stats.Weeks) != len(want) {
+		t.Fatalf("expected %d weeks, got %d", len(want), len(stats.Weeks))
+	}
+	for i, w := range want {
+		got := stats.Weeks[i]
+		if got.Week != w.week || got.AvailableDays != w.available || got.Required != w.required ||
+			got.BadgedIn != float64(w.badged) || got.Status != w.status {
+			t.Errorf("week %d: got %+v, want %+v", i, got, w)
+		}
+	}
+	if !stats.Weeks[0].Start.Equal(stats.StartDate) || stats.Weeks[0].WorkingDays != 5 {
+		t.Errorf("first week should be clipped to the period but scheduled as a full week: %+v", stats.Weeks[0])
+	}
+	if stats.WeeksMet != 1 || stats.WeeksMissed != 1 || stats.WeeklyMinimum != 3 {
+		t.Errorf("expected 1 met, 1 missed, minimum 3; got %d, %d, %d", stats.WeeksMet, stats.WeeksMissed, stats.WeeklyMinimum)
+	}
+
+	if w, ok := stats.WeekOf(time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)); !ok || w.Week != 2 {
+		t.Errorf("WeekOf(Jan 8) = %+v, %v", w, ok)
+	}
+	if _, ok := stats.WeekOf(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)); ok {
+		t.Error("WeekOf should not find a week outside the period")
+	}
+}
+
+func TestCalculateWeeksPartialDays(t *testing.T) {
+	tp := &data.TimePeriod{Key: "Jan", Name: "Jan", StartDateRaw: "2025-01-06", EndDateRaw: "2025-01-10"}
+	_ = tp.ParseDates()
+	badges := data.NewBadgeEntryData()
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-06", IsBadgedIn: true})
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-07", IsBadgedIn: true})
+	badges.Add(data.BadgeEntry{EntryDate: "2025-01-08", IsBadgedIn: true, Credit: data.HalfDay})
+
+	today := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+	stats, err := CalculatePeriodStats(tp, badges, data.NewHolidayData(), data.NewVacationData(), 50, &today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	CalculateWeeks(stats, data.WeeklyMinimum{Days: 3}, today)
+	if w := stats.Weeks[0]; w.BadgedIn != 2.5 || w.Status != WeekMissed {
+		t.Errorf("a half day should count as half toward the minimum: %+v", w)
+	}
+}
+
+func TestCalculateWeeksDisabled(t *testing.T) {
+	stats := weeklyTestStats(t)
+	CalculateWeeks(stats, data.WeeklyMinimum{}, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC))
+	if stats.Weeks != nil || stats.WeeklyMinimum != 0 {
+		t.Errorf("expected no weeks without a policy, got %d", len(stats.Weeks))
+	}
+}
+
+func TestWeeklyRequirement(t *testing.T) {
+	tests := []struct {
+		rule               string
+		days, sched, avail int
+		want               int
+	}{
+		{data.ProrateProportional, 3, 5, 5, 3},
+		{data.ProrateProportional, 3, 5, 4, 2}, // 2.4
+		{data.ProrateProportional, 3, 5, 3, 2}, // 1.8
+		{data.ProrateProportional, 3, 5, 2, 1},
+		{data.ProrateSubtract, 3, 5, 4, 2},
+		{data.ProrateSubtract, 3, 5, 2, 0},
+		{data.ProrateNone, 3, 5, 2, 2},
+		{data.ProrateNone, 3, 5, 4, 3},
+		{data.ProrateNone, 3, 5, 0, 0},
+		{data.ProrateProportional, 3, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		got := weeklyRequirement(data.WeeklyMinimum{Days: tt.days, Proration: tt.rule}, tt.sched, tt.avail)
+		if got != tt.want {
+			t.Errorf("%s %d of %d/%d: got %d, want %d", tt.rule, tt.days, tt.avail, tt.sched, got, tt.want)
+		}
+	}
+}
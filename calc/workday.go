@@ -2,6 +2,8 @@ package calc
 
 import (
 	"time"
+
+	"rto/data"
 )
 
 // Workday holds status flags for a single calendar day.
@@ -12,20 +14,33 @@ type Workday struct {
 	IsBadgedIn   bool
 	IsFlexCredit bool
 	IsHoliday    bool
-	IsVacation   bool
+	IsVacation   bool           // on leave that the policy excludes from the total days
+	Leave        data.LeaveType // type of leave on the day, whatever its rule; empty for none
+	IsPartial    bool           // credited for part of the day, e.g. a half day
+	Credit       float64        // days counted toward the goal: the day's credit, times the flex weight for a flex credit
+	IsPlanned    bool           // a planned office day from today on; not counted as badged in
+	IsMissedPlan bool           // a planned office day that passed without being recorded
+}
+
+// IsWorkday reports whether date is a working day under the work schedule
+// in settings.yaml (Monday–Friday by default).
+func IsWorkday(date time.Time) bool {
+	return data.IsWorkday(date)
 }
 
-// IsWeekday returns true if the date is Monday through Friday.
+// IsWeekday reports whether date is a working day.
+//
+// Deprecated: working days follow the work schedule, which may include
+// weekends; use IsWorkday.
 func IsWeekday(date time.Time) bool {
-	w := date.Weekday()
-	return w != time.Saturday && w != time.Sunday
+	return IsWorkday(date)
 }
 
-// CreateWorkdayMap builds a map of YYYY-MM-DD → Workday for all weekdays in [start, end].
+// CreateWorkdayMap builds a map of YYYY-MM-DD → Workday for all working days in [start, end].
 func CreateWorkdayMap(start, end time.Time) map[string]*Workday {
 	m := make(map[string]*Workday)
 	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
-		if !IsWeekday(d) {
+		if !IsWorkday(d) {
 			continue
 		}
 		key := d.Format("2006-01-02")
@@ -3,6 +3,8 @@ package calc
 import (
 	"testing"
 	"time"
+
+	"rto/data"
 )
 
 func TestIsWeekday(t *testing.T) {
@@ -28,6 +30,21 @@ func TestIsWeekday(t *testing.T) {
 	}
 }
 
+func TestIsWorkday(t *testing.T) {
+	ws, _ := data.ParseWorkSchedule([]data.WorkWeek{{Days: []string{"sun", "mon", "tue", "wed", "thu"}}})
+	data.SetWorkSchedule(ws)
+	defer data.SetWorkSchedule(nil)
+
+	sunday, _ := time.Parse("2006-01-02", "2025-01-12")
+	friday, _ := time.Parse("2006-01-02", "2025-01-10")
+	if !IsWorkday(sunday) || IsWorkday(friday) {
+		t.Error("expected Sunday to be a working day and Friday not under a Sunday–Thursday week")
+	}
+	if IsWeekday(sunday) != IsWorkday(sunday) {
+		t.Error("IsWeekday should follow the work schedule like IsWorkday")
+	}
+}
+
 func TestCreateWorkdayMapCountsWeekdays(t *testing.T) {
 	// Q1 2025: Jan 1 - Mar 31
 	// Jan 1 is a Wednesday
@@ -118,3 +135,25 @@ func TestCreateWorkdayMapKeyFormat(t *testing.T) {
 		t.Error("key should be in YYYY-MM-DD format")
 	}
 }
+
+func TestCreateWorkdayMapRespectsWorkSchedule(t *testing.T) {
+	ws, _ := data.ParseWorkSchedule([]data.WorkWeek{
+		{Days: []string{"mon", "tue", "wed", "thu"}},
+		{From: "2025-01-13", Days: []string{"mon", "wed", "fri"}},
+	})
+	data.SetWorkSchedule(ws)
+	defer data.SetWorkSchedule(nil)
+
+	start, _ := time.Parse("2006-01-02", "2025-01-06")
+	end, _ := time.Parse("2006-01-02", "2025-01-19")
+	m := CreateWorkdayMap(start, end)
+	if len(m) != 7 {
+		t.Errorf("expected 4 + 3 working days, got %d", len(m))
+	}
+	if _, ok := m["2025-01-10"]; ok {
+		t.Error("Friday Jan 10 is not a working day under the 4-day week")
+	}
+	if _, ok := m["2025-01-17"]; !ok {
+		t.Error("Friday Jan 17 is a working day under the later schedule")
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"rto/calc"
+	"rto/data"
+)
+
+// AnalyticsOptions configures RunAnalytics: either a period or a date range.
+type AnalyticsOptions struct {
+	Period string // period key in the active time period file
+	From   string // range start, YYYY-MM-DD or "today"; overrides Period
+	To     string // range end, inclusive; "" for today
+}
+
+// RunAnalytics prints the attendance patterns of a period or date range in
+// the selected output format.
+func RunAnalytics(opts AnalyticsOptions) error {
+	now := time.Now()
+	var start, end time.Time
+	label := ""
+	if opts.From != "" {
+		var err error
+		if start, err = parseBadgeDate(opts.From, now); err != nil {
+			return err
+		}
+		to := opts.To
+		if to == "" {
+			to = "today"
+		}
+		if end, err = parseBadgeDate(to, now); err != nil {
+			return err
+		}
+		if end.Before(start) {
+			return fmt.Errorf("--to %s is before --from %s", end.Format(data.BadgeDateFormat), start.Format(data.BadgeDateFormat))
+		}
+	} else {
+		td, err := data.LoadTimePeriodData()
+		if err != nil {
+			return fmt.Errorf("loading time periods: %w", err)
+		}
+		tp, err := td.GetPeriodByKey(opts.Period)
+		if err != nil {
+			return fmt.Errorf("time period %q not found — run 'rto init' to create data files", opts.Period)
+		}
+		start, end, label = tp.StartDate, tp.EndDate, tp.Key
+	}
+
+	badges, err := data.LoadBadgeEntryData()
+	if err != nil {
+		return fmt.Errorf("loading badge data: %w", err)
+	}
+	holidays, err := data.LoadHolidayData()
+	if err != nil {
+		return fmt.Errorf("loading holidays: %w", err)
+	}
+	vacations, err := data.LoadVacationData()
+	if err != nil {
+		return fmt.Errorf("loading vacations: %w", err)
+	}
+
+	a := calc.CalculateAnalytics(badges, holidays, vacations, start, end, now)
+	return WriteAnalyticsOutput(a, label, outputFormat, os.Stdout)
+}
+
+// WriteAnalytics writes the weekday table, streaks, arrival time and office
+// counts of a, headed by period (empty for a date range).
+func WriteAnalytics(a *calc.Analytics, period string, w io.Writer) error {
+	dates := a.Start.Format("Jan 2, 2006") + " – " + a.End.Format("Jan 2, 2006")
+	var err error
+	if period != "" {
+		_, err = fmt.Fprintf(w, "Period: %s  (%s)\n", period, dates)
+	} else {
+		_, err = fmt.Fprintf(w, "Dates: %s\n", dates)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	if len(a.Weekdays) == 0 {
+		fmt.Fprintln(w, "  No workdays so far.")
+	} else {
+		fmt.Fprintf(w, "  %-10s  %6s  %4s  %9s  %6s\n", "Weekday", "Office", "Flex", "Available", "Rate")
+		for _, ws := range a.Weekdays {
+			fmt.Fprintf(w, "  %-10s  %6d  %4d  %9d  %5.1f%%  %s\n",
+				ws.Weekday, ws.Office, ws.Flex, ws.Available, ws.Rate*100, calc.RateBar(ws.Rate, 10))
+		}
+		if least, ok := a.LeastAttended(); ok && least.Rate < 1 {
+			fmt.Fprintf(w, "\n  Least attended:       %ss (%.1f%%)\n", least.Weekday, least.Rate*100)
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Longest streak:       %s\n", a.LongestStreak)
+	fmt.Fprintf(w, "  Current streak:       %s\n", a.CurrentStreak)
+	if a.TimedBadgeIns > 0 {
+		officeDays := 0
+		for _, o := range a.Offices {
+			officeDays += o.Days
+		}
+		fmt.Fprintf(w, "  Average arrival:      %s  (from %d of %d office badge-ins)\n",
+			calc.FormatTimeOfDay(a.AverageArrival), a.TimedBadgeIns, officeDays)
+	} else {
+		fmt.Fprintf(w, "  Average arrival:      no badge-in times recorded\n")
+	}
+	_, err = fmt.Fprintf(w, "  Offices:              %s\n", formatOffices(a.Offices))
+	return err
+}
+
+// formatOffices lists office counts, e.g. "HQ 12, Annex 1", or "none".
+func formatOffices(offices []calc.OfficeCount) string {
+	if len(offices) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(offices))
+	for i, o := range offices {
+		name := o.Office
+		if name == "" {
+			name = "(unnamed)"
+		}
+		parts[i] = fmt.Sprintf("%s %d", name, o.Days)
+	}
+	return strings.Join(parts, ", ")
+}
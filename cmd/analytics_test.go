@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"rto/calc"
+	"rto/data"
+)
+
+func sampleAnalytics() *calc.Analytics {
+	badges := data.NewBadgeEntryData()
+	// Tuesday to Thursday every week of January 2025, arriving at 08:45.
+	for d := time.Date(2025, 1, 1, 8, 45, 0, 0, time.UTC); d.Month() == time.January; d = d.AddDate(0, 0, 1) {
+		if wd := d.Weekday(); wd >= time.Tuesday && wd <= time.Thursday {
+			badges.Add(data.NewOfficeBadge(d, "HQ"))
+		}
+	}
+	start, end := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	return calc.CalculateAnalytics(badges, data.NewHolidayData(), data.NewVacationData(), start, end, end.AddDate(0, 0, 1))
+}
+
+func TestWriteAnalytics(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAnalytics(sampleAnalytics(), "JAN_2025", &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Period: JAN_2025", "Monday           0     0          4    0.0%  ░░░░░░░░░░",
+		"Least attended:       Mondays (0.0%)", "Longest streak:       3 days (Jan 7 – Jan 9)",
+		"Current streak:       none", "Average arrival:      08:45  (from 14 of 14 office badge-ins)", "Offices:              HQ 14"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteAnalyticsOutput(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAnalyticsOutput(sampleAnalytics(), "", OutputJSON, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var out AnalyticsOutput
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Weekdays) != 5 || out.Weekdays[1].Weekday != "Tuesday" || out.Weekdays[1].Rate != 1 ||
+		out.CurrentStreak.StartDate != nil || *out.LongestStreak.EndDate != "2025-01-09" ||
+		*out.AverageArrival != "08:45" || out.Offices[0] != (OfficeOutput{"HQ", 14}) {
+		t.Errorf("unexpected output: %+v", out)
+	}
+
+	buf.Reset()
+	if err := WriteAnalyticsOutput(sampleAnalytics(), "JAN_2025", OutputCSV, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 6 || lines[1] != "JAN_2025,2025-01-01,2025-01-31,Monday,4,0,0,0.0000" {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"rto/calc"
+	"rto/data"
+)
+
+// BadgeOptions selects the dates and kind of entry for RunBadge.
+type BadgeOptions struct {
+	Date     string         // "today" or YYYY-MM-DD; empty when From is set
+	From     string         // range start, YYYY-MM-DD or "today"
+	To       string         // range end (inclusive); defaults to today
+	Weekdays []time.Weekday // days of the week to touch; nil means every working day
+	Flex     bool           // record a flex credit instead of an office badge-in
+	Planned  bool           // record a planned office day instead of a badge-in
+	Office   string         // office name; defaults to settings.default_office
+	Credit   float64        // fraction of the day credited, e.g. 0.5; 0 keeps an existing entry's credit, or records a full day
+	Remove   bool           // remove entries instead of adding them
+}
+
+// BadgeResult describes what happened to a single date.
+type BadgeResult struct {
+	Date   time.Time
+	Change data.BadgeChange
+	Reason string // why the date was skipped, left unchanged or planned, if it was
+}
+
+// RunBadge records (or removes) badge entries from the command line using the
+// same rules as the calendar's b and f keys, saves them, and prints the
+// updated stats for the period containing the last date touched.
+func RunBadge(opts BadgeOptions) error {
+	lock, err := acquireWriteLock()
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	badges, err := data.LoadBadgeEntryData()
+	if err != nil {
+		return fmt.Errorf("loading badge data: %w", err)
+	}
+	holidays, err := data.LoadHolidayData()
+	if err != nil {
+		return fmt.Errorf("loading holidays: %w", err)
+	}
+	vacations, err := data.LoadVacationData()
+	if err != nil {
+		return fmt.Errorf("loading vacations: %w", err)
+	}
+	settings, err := data.LoadAppSettings()
+	if err != nil {
+		return fmt.Errorf("loading settings: %w", err)
+	}
+	policy, err := data.LoadPolicy(settings)
+	if err != nil {
+		return fmt.Errorf("loading policy: %w", err)
+	}
+
+	results, err := ApplyBadges(badges, holidays, vacations, settings, opts, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := badges.Save(); err != nil {
+		return fmt.Errorf("saving badge data: %w", err)
+	}
+	if err := WriteBadgeResults(results, opts, os.Stdout); err != nil {
+		return err
+	}
+
+	if len(results) > 0 {
+		td, err := data.LoadTimePeriodData()
+		if err != nil {
+			return fmt.Errorf("loading time periods: %w", err)
+		}
+		last := results[len(results)-1].Date
+		if tp, err := td.GetPeriodByDate(last); err == nil && tp != nil {
+			stats, err := periodStats(tp, badges, holidays, vacations, settings, policy, time.Now())
+			if err != nil {
+				return fmt.Errorf("calculating stats: %w", err)
+			}
+			fmt.Println()
+			if err := WriteStats(stats, os.Stdout); err != nil {
+				return err
+			}
+		}
+	}
+
+	conflicts := 0
+	for _, r := range results {
+		if r.Change == data.BadgeConflict {
+			conflicts++
+		}
+	}
+	if conflicts > 0 {
+		return fmt.Errorf("%d date(s) left unchanged because they hold a conflicting entry", conflicts)
+	}
+	return nil
+}
+
+// ApplyBadges applies opts to badges and reports the outcome for each date.
+// When adding, non-working days, holidays, vacation days and days outside
+// opts.Weekdays are skipped; removal touches every selected date. Office
+// days after today are planned rather than badged in. now resolves "today"
+// and stamps today's entry with the current time of day.
+func ApplyBadges(badges *data.BadgeEntryData, holidays *data.HolidayData, vacations *data.VacationData,
+	settings *data.AppSettings, opts BadgeOptions, now time.Time) ([]BadgeResult, error) {
+
+	if opts.Flex && opts.Office != "" {
+		return nil, fmt.Errorf("--office can't be combined with --flex")
+	}
+	if opts.Flex && opts.Planned {
+		return nil, fmt.Errorf("--planned can't be combined with --flex")
+	}
+	if opts.Credit < 0 || opts.Credit > 1 {
+		return nil, fmt.Errorf("--credit %s must be more than 0 and at most 1", formatDays(opts.Credit))
+	}
+	dates, err := badgeDates(opts, now)
+	if err != nil {
+		return nil, err
+	}
+
+	holidayMap := holidays.GetHolidayMap()
+	vacationMap := vacations.GetVacationMap()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var results []BadgeResult
+	for _, d := range dates {
+		key := d.Format(data.BadgeDateFormat)
+		if !opts.Remove {
+			reason := dayOffReason(key, d, holidayMap, vacationMap)
+			if reason == "" && len(opts.Weekdays) > 0 && !containsWeekday(opts.Weekdays, d.Weekday()) {
+				reason = "not a selected weekday"
+			}
+			if reason != "" {
+				results = append(results, BadgeResult{Date: d, Change: data.BadgeUnchanged, Reason: reason})
+				continue
+			}
+		}
+
+		// A day after today can't be attended yet: an office badge-in is
+		// planned instead, and a flex credit, which can't be planned, is
+		// refused. Removal still clears an entry recorded there earlier.
+		existing, exists := badges.Get(key)
+		future := d.After(today) && !opts.Planned && !(opts.Remove && exists && !existing.Planned)
+		if future && opts.Flex && !opts.Remove {
+			results = append(results, BadgeResult{Date: d, Change: data.BadgeUnchanged, Reason: "future date"})
+			continue
+		}
+
+		stamp := d
+		if d.Equal(today) {
+			stamp = time.Date(d.Year(), d.Month(), d.Day(), now.Hour(), now.Minute(), now.Second(), 0, time.UTC)
+		}
+		var entry data.BadgeEntry
+		if opts.Flex {
+			entry = data.NewFlexBadge(stamp, settings.FlexCredit)
+		} else {
+			office := opts.Office
+			if office == "" {
+				office = settings.DefaultOffice
+			}
+			if opts.Planned || future {
+				entry = data.NewPlannedBadge(d, office)
+			} else {
+				entry = data.NewOfficeBadge(stamp, office)
+			}
+		}
+		entry.Credit = opts.Credit
+		if exists {
+			if opts.Office == "" {
+				entry.Office = existing.Office // keep the recorded office unless --office overrides it
+			}
+			if opts.Credit == 0 {
+				entry.Credit = existing.Credit // likewise the credit
+			}
+		}
+
+		r := BadgeResult{Date: d, Change: badges.SetBadge(entry, opts.Remove)}
+		if future && r.Change == data.BadgeAdded {
+			r.Reason = "future date"
+		}
+		if r.Change == data.BadgeConflict {
+			if entry.Planned {
+				r.Reason = "day already recorded"
+			} else if opts.Flex {
+				r.Reason = "office badge-in already recorded"
+			} else {
+				r.Reason = "flex credit already recorded"
+			}
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// WriteBadgeResults writes one line per date describing what changed.
+func WriteBadgeResults(results []BadgeResult, opts BadgeOptions, w io.Writer) error {
+	kind := "office badge-in"
+	if opts.Flex {
+		kind = "flex credit"
+	} else if opts.Planned {
+		kind = "planned office day"
+	}
+	changed := 0
+	for _, r := range results {
+		var msg string
+		switch r.Change {
+		case data.BadgeAdded:
+			msg = "added " + kind
+			if r.Reason != "" {
+				msg = "added planned office day (" + r.Reason + ")"
+			}
+		case data.BadgeRemoved:
+			msg = "removed " + kind
+		case data.BadgeUpdated:
+			var parts []string
+			if opts.Office != "" {
+				parts = append(parts, "office to "+opts.Office)
+			}
+			if opts.Credit != 0 {
+				parts = append(parts, "credit to "+formatDays(opts.Credit)+" day")
+			}
+			msg = "updated " + strings.Join(parts, ", ")
+		case data.BadgeConflict:
+			msg = "unchanged: " + r.Reason
+		default:
+			if r.Reason != "" {
+				msg = "skipped: " + r.Reason
+			} else if opts.Remove {
+				msg = "no " + kind + " recorded"
+			} else {
+				msg = "already recorded"
+			}
+		}
+		if r.Change == data.BadgeAdded || r.Change == data.BadgeRemoved || r.Change == data.BadgeUpdated {
+			changed++
+		}
+		if _, err := fmt.Fprintf(w, "  %s  %s  %s\n", r.Date.Format(data.BadgeDateFormat), r.Date.Format("Mon"), msg); err != nil {
+			return err
+		}
+	}
+	if len(results) > 1 {
+		_, err := fmt.Fprintf(w, "%d of %d date(s) changed.\n", changed, len(results))
+		return err
+	}
+	return nil
+}
+
+// ParseWeekdays parses a comma-separated list of weekday names or
+// abbreviations (e.g. "mon,wed,fri").
+func ParseWeekdays(s string) ([]time.Weekday, error) {
+	var result []time.Weekday
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		d, err := data.ParseWeekday(part)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func badgeDates(opts BadgeOptions, now time.Time) ([]time.Time, error) {
+	if opts.From == "" {
+		if opts.To != "" {
+			return nil, fmt.Errorf("--to requires --from")
+		}
+		date := opts.Date
+		if date == "" {
+			date = "today"
+		}
+		d, err := parseBadgeDate(date, now)
+		if err != nil {
+			return nil, err
+		}
+		return []time.Time{d}, nil
+	}
+
+	if opts.Date != "" {
+		return nil, fmt.Errorf("give either a DATE or --from/--to, not both")
+	}
+	from, err := parseBadgeDate(opts.From, now)
+	if err != nil {
+		return nil, err
+	}
+	to := opts.To
+	if to == "" {
+		to = "today"
+	}
+	end, err := parseBadgeDate(to, now)
+	if err != nil {
+		return nil, err
+	}
+	if end.Before(from) {
+		return nil, fmt.Errorf("--to %s is before --from %s", end.Format(data.BadgeDateFormat), from.Format(data.BadgeDateFormat))
+	}
+	var dates []time.Time
+	for d := from; !d.After(end); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d)
+	}
+	return dates, nil
+}
+
+func parseBadgeDate(s string, now time.Time) (time.Time, error) {
+	if strings.EqualFold(s, "today") {
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	d, err := time.Parse(data.BadgeDateFormat, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or today)", s)
+	}
+	return d, nil
+}
+
+// dayOffReason explains why no badge-in should be recorded on d, or returns
+// "" for a regular workday.
+func dayOffReason(key string, d time.Time, holidayMap map[string]data.Holiday, vacationMap map[string]data.Vacation) string {
+	if h, ok := holidayMap[key]; ok {
+		return "holiday (" + h.Name + ")"
+	}
+	if v, ok := vacationMap[key]; ok {
+		return strings.ToLower(v.Leave().Label()) + " (" + v.Destination + ")"
+	}
+	if !calc.IsWorkday(d) {
+		if data.IsWeekend(d) {
+			return "weekend"
+		}
+		return "not a working day"
+	}
+	return ""
+}
+
+func containsWeekday(days []time.Weekday, d time.Weekday) bool {
+	for _, x := range days {
+		if x == d {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"rto/data"
+)
+
+func badgeFixtures() (*data.BadgeEntryData, *data.HolidayData, *data.VacationData, *data.AppSettings) {
+	holidays := data.NewHolidayData()
+	holidays.Add(data.Holiday{Name: "MLK Day", Date: "2025-01-20"})
+	vacations := data.NewVacationData()
+	vacations.Add(data.Vacation{Destination: "Ski", StartDate: "2025-01-22", EndDate: "2025-01-22"})
+	settings := &data.AppSettings{DefaultOffice: "HQ", FlexCredit: "Flex Credit", Goal: 50}
+	return data.NewBadgeEntryData(), holidays, vacations, settings
+}
+
+func TestApplyBadgesToday(t *testing.T) {
+	badges, holidays, vacations, settings := badgeFixtures()
+	now := time.Date(2025, 1, 21, 8, 47, 0, 0, time.Local)
+
+	results, err := ApplyBadges(badges, holidays, vacations, settings, BadgeOptions{}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Change != data.BadgeAdded {
+		t.Fatalf("expected one added entry, got %+v", results)
+	}
+	e, ok := badges.Get("2025-01-21")
+	if !ok || e.Office != "HQ" || e.IsFlexCredit {
+		t.Fatalf("expected office badge at HQ, got %+v", e)
+	}
+	if e.DateTime.Hour() != 8 || e.DateTime.Minute() != 47 {
+		t.Errorf("today's entry should carry the current time, got %v", e.DateTime)
+	}
+}
+
+func TestApplyBadgesRangeSkipsNonWorkdays(t *testing.T) {
+	badges, holidays, vacations, settings := badgeFixtures()
+	opts := BadgeOptions{From: "2025-01-18", To: "2025-01-24", Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday}}
+
+	results, err := ApplyBadges(badges, holidays, vacations, settings, opts, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reasons := map[string]string{}
+	for _, r := range results {
+		reasons[r.Date.Format(data.BadgeDateFormat)] = r.Reason
+	}
+	want := map[string]string{
+		"2025-01-18": "weekend",
+		"2025-01-19": "weekend",
+		"2025-01-20": "holiday (MLK Day)",
+		"2025-01-21": "",
+		"2025-01-22": "vacation (Ski)",
+		"2025-01-23": "",
+		"2025-01-24": "not a selected weekday",
+	}
+	for date, reason := range want {
+		if reasons[date] != reason {
+			t.Errorf("%s: got reason %q, want %q", date, reasons[date], reason)
+		}
+	}
+	if badges.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", badges.Len())
+	}
+}
+
+func TestApplyBadgesFollowsWorkSchedule(t *testing.T) {
+	ws, _ := data.ParseWorkSchedule([]data.WorkWeek{{Days: []string{"sun", "mon", "tue", "wed", "thu"}}})
+	data.SetWorkSchedule(ws)
+	defer data.SetWorkSchedule(nil)
+
+	badges, holidays, vacations, settings := badgeFixtures()
+	results, err := ApplyBadges(badges, holidays, vacations, settings, BadgeOptions{From: "2025-01-24", To: "2025-01-26"}, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Fri and Sat are off; Sun is a working day.
+	if results[0].Reason == "" || results[1].Reason == "" || results[2].Change != data.BadgeAdded {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
+
+func TestApplyBadgesFlexExclusivity(t *testing.T) {
+	badges, holidays, vacations, settings := badgeFixtures()
+	badges.Add(data.NewOfficeBadge(time.Date(2025, 1, 21, 0, 0, 0, 0, time.UTC), "HQ"))
+
+	results, err := ApplyBadges(badges, holidays, vacations, settings, BadgeOptions{Date: "2025-01-21", Flex: true}, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Change != data.BadgeConflict {
+		t.Fatalf("expected conflict, got %+v", results[0])
+	}
+	if e, _ := badges.Get("2025-01-21"); e.IsFlexCredit {
+		t.Error("office entry must not be replaced by flex")
+	}
+
+	var buf bytes.Buffer
+	WriteBadgeResults(results, BadgeOptions{Flex: true}, &buf)
+	if !strings.Contains(buf.String(), "2025-01-21  Tue  unchanged: office badge-in already recorded") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestApplyBadgesRemoveKeepsOffice(t *testing.T) {
+	badges, holidays, vacations, settings := badgeFixtures()
+	badges.Add(data.NewOfficeBadge(time.Date(2025, 1, 21, 0, 0, 0, 0, time.UTC), "Annex"))
+
+	results, _ := ApplyBadges(badges, holidays, vacations, settings, BadgeOptions{Date: "2025-01-21"}, time.Now())
+	if results[0].Change != data.BadgeUnchanged {
+		t.Errorf("re-badging without --office should keep the recorded office, got %+v", results[0])
+	}
+	results, _ = ApplyBadges(badges, holidays, vacations, settings, BadgeOptions{Date: "2025-01-21", Remove: true}, time.Now())
+	if results[0].Change != data.BadgeRemoved || badges.Len() != 0 {
+		t.Errorf("expected removal, got %+v", results[0])
+	}
+}
+
+func TestApplyBadgesCredit(t *testing.T) {
+	badges, holidays, vacations, settings := badgeFixtures()
+
+	results, _ := ApplyBadges(badges, holidays, vacations, settings, BadgeOptions{Date: "2025-01-21", Credit: 0.5}, time.Now())
+	if e, _ := badges.Get("2025-01-21"); results[0].Change != data.BadgeAdded || e.DayCredit() != 0.5 {
+		t.Fatalf("expected a half day, got %+v and %+v", results[0], e)
+	}
+	results, _ = ApplyBadges(badges, holidays, vacations, settings, BadgeOptions{Date: "2025-01-21"}, time.Now())
+	if results[0].Change != data.BadgeUnchanged {
+		t.Errorf("re-badging without --credit should keep the recorded credit, got %+v", results[0])
+	}
+	results, _ = ApplyBadges(badges, holidays, vacations, settings, BadgeOptions{Date: "2025-01-21", Credit: 1}, time.Now())
+	if e, _ := badges.Get("2025-01-21"); results[0].Change != data.BadgeUpdated || e.IsPartial() {
+		t.Errorf("--credit 1 should make it a full day, got %+v and %+v", results[0], e)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBadgeResults(results, BadgeOptions{Credit: 1}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "updated credit to 1 day") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestApplyBadgesFutureDateIsPlanned(t *testing.T) {
+	badges, holidays, vacations, settings := badgeFixtures()
+	now := time.Date(2025, 1, 21, 8, 47, 0, 0, time.Local)
+
+	results, err := ApplyBadges(badges, holidays, vacations, settings, BadgeOptions{Date: "2025-01-23"}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Change != data.BadgeAdded || results[0].Reason != "future date" {
+		t.Fatalf("expected a planned day, got %+v", results)
+	}
+	if e, _ := badges.Get("2025-01-23"); !e.Planned || e.IsBadgedIn {
+		t.Errorf("a future office day should be planned, got %+v", e)
+	}
+	var out bytes.Buffer
+	_ = WriteBadgeResults(results, BadgeOptions{}, &out)
+	if !strings.Contains(out.String(), "added planned office day (future date)") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	results, _ = ApplyBadges(badges, holidays, vacations, settings, BadgeOptions{Date: "2025-01-24", Flex: true}, now)
+	if results[0].Change != data.BadgeUnchanged || badges.Has("2025-01-24") {
+		t.Errorf("a future flex credit should be refused, got %+v", results)
+	}
+
+	badges.Add(data.NewOfficeBadge(time.Date(2025, 1, 27, 0, 0, 0, 0, time.UTC), "HQ"))
+	results, _ = ApplyBadges(badges, holidays, vacations, settings, BadgeOptions{Date: "2025-01-27", Remove: true}, now)
+	if results[0].Change != data.BadgeRemoved {
+		t.Errorf("a future badge-in recorded earlier should still be removable, got %+v", results)
+	}
+}
+
+func TestApplyBadgesInvalidOptions(t *testing.T) {
+	badges, holidays, vacations, settings := badgeFixtures()
+	cases := []BadgeOptions{
+		{Date: "01/21/2025"},
+		{To: "2025-01-21"},
+		{Date: "2025-01-21", From: "2025-01-20"},
+		{From: "2025-01-21", To: "2025-01-20"},
+		{Flex: true, Office: "HQ"},
+		{Credit: 1.5},
+		{Credit: -0.5},
+	}
+	for _, opts := range cases {
+		if _, err := ApplyBadges(badges, holidays, vacations, settings, opts, time.Now()); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+}
+
+func TestParseWeekdays(t *testing.T) {
+	got, err := ParseWeekdays("mon, Wed,friday")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []time.Weekday{time.Monday, time.Wednesday, time.Friday}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+	if _, err := ParseWeekdays("funday"); err == nil {
+		t.Error("expected error for unknown weekday")
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"rto/data"
+)
+
+// RunDoctor checks every data file in the global store for problems. With
+// fix, mechanical problems are repaired. It returns an error if any problem
+// remains, so scripts can rely on the exit status.
+func RunDoctor(fix bool) error {
+	return lockUnless(!fix, func() error {
+		issues, err := data.Diagnose(data.GetStore(), fix)
+		if err != nil {
+			return fmt.Errorf("checking data: %w", err)
+		}
+		if err := WriteDoctorReport(issues, fix, os.Stdout); err != nil {
+			return err
+		}
+		if remaining := countRemaining(issues, fix); remaining > 0 {
+			return fmt.Errorf("%d problem(s) need manual attention", remaining)
+		}
+		return nil
+	})
+}
+
+// WriteDoctorReport lists each issue as file:line: message, marking the ones
+// --fix can repair (or has repaired, when fix is set) and the warnings.
+func WriteDoctorReport(issues []data.Issue, fix bool, w io.Writer) error {
+	if len(issues) == 0 {
+		_, err := fmt.Fprintln(w, "No problems found.")
+		return err
+	}
+
+	fixable, warnings := 0, 0
+	for _, i := range issues {
+		tag := ""
+		switch {
+		case i.Warning:
+			warnings++
+			tag = " [warning]"
+		case i.Fixable:
+			fixable++
+			tag = " [fixable]"
+			if fix {
+				tag = " [fixed]"
+			}
+		}
+		fmt.Fprintf(w, "  %s%s\n", i, tag)
+	}
+
+	fmt.Fprintln(w)
+	if warnings > 0 {
+		fmt.Fprintf(w, "%d warning(s): worth a look, but left as they are.\n", warnings)
+	}
+	problems := len(issues) - warnings
+	manual := problems - fixable
+	switch {
+	case problems == 0:
+		_, err := fmt.Fprintln(w, "No problems found.")
+		return err
+	case fix:
+		_, err := fmt.Fprintf(w, "Fixed %d problem(s); %d need manual attention.\n", fixable, manual)
+		return err
+	case fixable > 0:
+		_, err := fmt.Fprintf(w, "%d problem(s) found, %d fixable. Run 'rto doctor --fix' to repair them.\n", problems, fixable)
+		return err
+	default:
+		_, err := fmt.Fprintf(w, "%d problem(s) found.\n", problems)
+		return err
+	}
+}
+
+func countRemaining(issues []data.Issue, fix bool) int {
+	n := 0
+	for _, i := range issues {
+		if !i.Warning && (!fix || !i.Fixable) {
+			n++
+		}
+	}
+	return n
+}
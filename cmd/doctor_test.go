@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"rto/data"
+)
+
+func TestWriteDoctorReportClean(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDoctorReport(nil, false, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No problems found") {
+		t.Errorf("expected clean message, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteDoctorReportIssues(t *testing.T) {
+	issues := []data.Issue{
+		{File: "badge_data.json", Line: 12, Message: "duplicate badge entry for 2025-01-06", Fixable: true},
+		{File: "vacations.yaml", Line: 3, Message: "end_date 2025-06-01 is before start_date 2025-06-10"},
+	}
+	var buf bytes.Buffer
+	WriteDoctorReport(issues, false, &buf)
+	out := buf.String()
+	if !strings.Contains(out, "badge_data.json:12: duplicate badge entry for 2025-01-06 [fixable]") {
+		t.Errorf("expected file:line with fixable tag, got:\n%s", out)
+	}
+	if !strings.Contains(out, "rto doctor --fix") {
+		t.Errorf("expected --fix hint, got:\n%s", out)
+	}
+
+	buf.Reset()
+	WriteDoctorReport(issues, true, &buf)
+	if !strings.Contains(buf.String(), "[fixed]") || !strings.Contains(buf.String(), "Fixed 1 problem(s); 1 need manual attention") {
+		t.Errorf("expected fixed summary, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteDoctorReportWarnings(t *testing.T) {
+	issues := []data.Issue{
+		{File: "badge_data.json", Line: 4, Message: "badge entry on a non-working day (2025-01-10, Friday)", Warning: true},
+	}
+	var buf bytes.Buffer
+	WriteDoctorReport(issues, true, &buf)
+	out := buf.String()
+	if !strings.Contains(out, "(2025-01-10, Friday) [warning]") || !strings.Contains(out, "No problems found") {
+		t.Errorf("expected a warning and no problems, got:\n%s", out)
+	}
+	if n := countRemaining(issues, false); n != 0 {
+		t.Errorf("warnings shouldn't fail the check, got %d remaining", n)
+	}
+}
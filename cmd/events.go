@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"rto/data"
+)
+
+// RunEvents prints all events to stdout.
+func RunEvents() error {
+	ed, err := data.LoadEventData()
+	if err != nil {
+		return fmt.Errorf("loading events: %w", err)
+	}
+	return WriteEventsOutput(ed, outputFormat, os.Stdout)
+}
+
+// WriteEvents formats and writes event data to the given writer.
+func WriteEvents(ed *data.EventData, w io.Writer) error {
+	all := ed.All()
+	if len(all) == 0 {
+		_, err := fmt.Fprintln(w, "No events recorded.")
+		return err
+	}
+
+	// Header
+	_, err := fmt.Fprintf(w, "%-4s  %-12s  %s\n", "#", "Date", "Description")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%-4s  %-12s  %s\n", "----", "------------", "------------------------------")
+
+	for i, ev := range all {
+		_, err := fmt.Fprintf(w, "%-4d  %-12s  %s\n", i+1, ev.Date, ev.Description)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EventEdit lists the fields to change on an existing event; nil fields are
+// left as they are.
+type EventEdit struct {
+	Date        *string
+	Description *string
+}
+
+// AddEvent validates ev and adds it, rejecting exact duplicates.
+func AddEvent(ed *data.EventData, ev data.Event) error {
+	if err := ev.Validate(); err != nil {
+		return err
+	}
+	if indexOf(ed.All(), ev, -1) >= 0 {
+		return fmt.Errorf("event %q on %s already exists", ev.Description, ev.Date)
+	}
+	ed.Add(ev)
+	return nil
+}
+
+// EditEvent applies edit to the event chosen by sel (a # or date) and
+// returns the updated event.
+func EditEvent(ed *data.EventData, sel string, edit EventEdit) (data.Event, error) {
+	i, err := selectEvent(ed, sel)
+	if err != nil {
+		return data.Event{}, err
+	}
+	ev := ed.All()[i]
+	if edit.Date != nil {
+		ev.Date = strings.TrimSpace(*edit.Date)
+	}
+	if edit.Description != nil {
+		ev.Description = strings.TrimSpace(*edit.Description)
+	}
+	if err := ev.Validate(); err != nil {
+		return data.Event{}, err
+	}
+	if indexOf(ed.All(), ev, i) >= 0 {
+		return data.Event{}, fmt.Errorf("event %q on %s already exists", ev.Description, ev.Date)
+	}
+	ed.Update(i, ev)
+	return ev, nil
+}
+
+// RemoveEvent deletes the event chosen by sel and returns it.
+func RemoveEvent(ed *data.EventData, sel string) (data.Event, error) {
+	i, err := selectEvent(ed, sel)
+	if err != nil {
+		return data.Event{}, err
+	}
+	ev := ed.All()[i]
+	ed.RemoveAt(i)
+	return ev, nil
+}
+
+// RunEventAdd adds an event to the global store.
+func RunEventAdd(ev data.Event) error {
+	return modifyEvents(func(ed *data.EventData) (string, error) {
+		if err := AddEvent(ed, ev); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Added event: %s  %s", ev.Date, ev.Description), nil
+	})
+}
+
+// RunEventEdit edits an event in the global store.
+func RunEventEdit(sel string, edit EventEdit) error {
+	return modifyEvents(func(ed *data.EventData) (string, error) {
+		ev, err := EditEvent(ed, sel, edit)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Updated event: %s  %s", ev.Date, ev.Description), nil
+	})
+}
+
+// RunEventRemove removes an event from the global store.
+func RunEventRemove(sel string) error {
+	return modifyEvents(func(ed *data.EventData) (string, error) {
+		ev, err := RemoveEvent(ed, sel)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Removed event: %s  %s", ev.Date, ev.Description), nil
+	})
+}
+
+func modifyEvents(fn func(ed *data.EventData) (string, error)) error {
+	return withWriteLock(func() error {
+		ed, err := data.LoadEventData()
+		if err != nil {
+			return fmt.Errorf("loading events: %w", err)
+		}
+		msg, err := fn(ed)
+		if err != nil {
+			return err
+		}
+		if err := ed.Save(); err != nil {
+			return fmt.Errorf("saving events: %w", err)
+		}
+		fmt.Println(msg)
+		return nil
+	})
+}
+
+func selectEvent(ed *data.EventData, sel string) (int, error) {
+	all := ed.All()
+	dates := make([]string, len(all))
+	for i, ev := range all {
+		dates[i] = ev.Date
+	}
+	return resolveSelector(sel, "event", dates, func(i int) string { return all[i].Description })
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"rto/data"
+)
+
+func TestWriteEvents(t *testing.T) {
+	ed := data.NewEventData()
+	var buf bytes.Buffer
+	WriteEvents(ed, &buf)
+	if !strings.Contains(buf.String(), "No events") {
+		t.Errorf("expected empty message, got:\n%s", buf.String())
+	}
+
+	ed.Add(data.Event{Date: "2025-03-01", Description: "Offsite"})
+	buf.Reset()
+	WriteEvents(ed, &buf)
+	if !strings.Contains(buf.String(), "1     2025-03-01    Offsite") {
+		t.Errorf("expected numbered row, got:\n%s", buf.String())
+	}
+}
+
+func TestEventCRUD(t *testing.T) {
+	ed := data.NewEventData()
+	if err := AddEvent(ed, data.Event{Date: "2025-03-01", Description: "Offsite"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := AddEvent(ed, data.Event{Date: "2025-03-01", Description: "Offsite"}); err == nil {
+		t.Error("expected duplicate error")
+	}
+	if err := AddEvent(ed, data.Event{Date: "March 1", Description: "x"}); err == nil {
+		t.Error("expected invalid date error")
+	}
+	if err := AddEvent(ed, data.Event{Date: "2025-03-01", Description: "Lunch"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	if _, err := EditEvent(ed, "2025-03-01", EventEdit{}); err == nil || !strings.Contains(err.Error(), "#2") {
+		t.Errorf("ambiguous date should list candidates, got %v", err)
+	}
+	date := "2025-02-01"
+	ev, err := EditEvent(ed, "2", EventEdit{Date: &date})
+	if err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if ev.Date != date || ed.All()[0].Description != "Lunch" {
+		t.Errorf("expected edited event re-sorted first, got %+v", ed.All())
+	}
+
+	removed, err := RemoveEvent(ed, "2025-02-01")
+	if err != nil || removed.Description != "Lunch" || ed.Len() != 1 {
+		t.Errorf("remove: got %+v, %v (len %d)", removed, err, ed.Len())
+	}
+	if _, err := RemoveEvent(ed, "5"); err == nil {
+		t.Error("expected out-of-range error")
+	}
+}
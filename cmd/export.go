@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"rto/calc"
+	"rto/data"
+	"rto/ics"
+)
+
+// ICSCategories are the kinds of entry rto export ics can write, in the
+// order they're listed in help text. Each becomes an iCalendar CATEGORIES
+// value so calendar apps can color or filter them separately.
+var ICSCategories = []string{"office", "flex", "vacation", "holiday", "event", "required"}
+
+// ICSExportOptions configures RunExportICS.
+type ICSExportOptions struct {
+	Path       string    // output file; empty or "-" writes to stdout
+	From, To   time.Time // only days in [From, To] are exported
+	Categories []string  // subset of ICSCategories; nil means all
+	Remind     time.Duration
+}
+
+// RunExportICS writes badge days, vacations, holidays, events and the days
+// still required to meet the goal as an iCalendar file.
+func RunExportICS(opts ICSExportOptions) error {
+	badges, err := data.LoadBadgeEntryData()
+	if err != nil {
+		return fmt.Errorf("loading badge data: %w", err)
+	}
+	holidays, err := data.LoadHolidayData()
+	if err != nil {
+		return fmt.Errorf("loading holidays: %w", err)
+	}
+	vacations, err := data.LoadVacationData()
+	if err != nil {
+		return fmt.Errorf("loading vacations: %w", err)
+	}
+	events, err := data.LoadEventData()
+	if err != nil {
+		return fmt.Errorf("loading events: %w", err)
+	}
+	settings, err := data.LoadAppSettings()
+	if err != nil {
+		return fmt.Errorf("loading settings: %w", err)
+	}
+	policy, err := data.LoadPolicy(settings)
+	if err != nil {
+		return fmt.Errorf("loading policy: %w", err)
+	}
+	td, err := data.LoadTimePeriodData()
+	if err != nil {
+		return fmt.Errorf("loading time periods: %w", err)
+	}
+
+	cal, err := BuildICSExport(badges, holidays, vacations, events, settings, policy, td.All(), opts, time.Now())
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := ics.Write(&buf, cal); err != nil {
+		return err
+	}
+	if opts.Path == "" || opts.Path == "-" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(opts.Path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", opts.Path, err)
+	}
+	fmt.Printf("Wrote %d calendar entries to %s\n", len(cal.Events), opts.Path)
+	return nil
+}
+
+// BuildICSExport assembles the calendar written by RunExportICS. Badge days,
+// holidays and events become one all-day event per day; vacations span their
+// dates. "required" days come from calc.PlanRequiredDays for each period in
+// periods that hasn't ended by now, and get a reminder when opts.Remind is
+// set. UIDs are derived from the category and date so re-exports update,
+// rather than duplicate, entries already subscribed to.
+func BuildICSExport(badges *data.BadgeEntryData, holidays *data.HolidayData, vacations *data.VacationData,
+	events *data.EventData, settings *data.AppSettings, policy *data.Policy, periods []data.TimePeriod,
+	opts ICSExportOptions, now time.Time) (ics.Calendar, error) {
+
+	include := map[string]bool{}
+	for _, c := range opts.Categories {
+		if indexOf(ICSCategories, c, -1) < 0 {
+			return ics.Calendar{}, fmt.Errorf("unknown category %q (expected %s)", c, strings.Join(ICSCategories, ", "))
+		}
+		include[c] = true
+	}
+	if len(include) == 0 {
+		for _, c := range ICSCategories {
+			include[c] = true
+		}
+	}
+
+	from, to := opts.From, opts.To
+	inWindow := func(d time.Time) bool { return !d.Before(from) && !d.After(to) }
+	allDay := func(category, uidKey, summary string, start, end time.Time) ics.Event {
+		return ics.Event{
+			UID:        fmt.Sprintf("%s-%s@rto", category, uidKey),
+			Summary:    summary,
+			Start:      start,
+			End:        end.AddDate(0, 0, 1),
+			AllDay:     true,
+			Categories: []string{exportCategoryName(category)},
+		}
+	}
+
+	var out []ics.Event
+	for _, b := range badges.All() {
+		d, err := time.Parse(data.BadgeDateFormat, b.EntryDate)
+		if err != nil || !b.IsBadgedIn || !inWindow(d) {
+			continue
+		}
+		part := ""
+		if b.IsPartial() {
+			part = fmt.Sprintf(" (%s day)", formatDays(b.DayCredit()))
+		}
+		if b.IsFlexCredit && include["flex"] {
+			out = append(out, allDay("flex", b.EntryDate, labelOr(b.Office, settings.FlexCredit)+part, d, d))
+		} else if !b.IsFlexCredit && include["office"] {
+			out = append(out, allDay("office", b.EntryDate, "In office: "+labelOr(b.Office, settings.DefaultOffice)+part, d, d))
+		}
+	}
+
+	if include["vacation"] {
+		for _, v := range vacations.All() {
+			start, err1 := time.Parse(data.BadgeDateFormat, v.StartDate)
+			end, err2 := time.Parse(data.BadgeDateFormat, v.EndDate)
+			if err1 != nil || err2 != nil || end.Before(from) || start.After(to) {
+				continue
+			}
+			ev := allDay("vacation", v.StartDate+"-"+shortHash(v.Destination), v.Leave().Label()+": "+v.Destination, start, end)
+			if !v.Approved {
+				ev.Summary += " (not approved)"
+				ev.Status = "TENTATIVE"
+			}
+			out = append(out, ev)
+		}
+	}
+
+	if include["holiday"] {
+		for _, h := range holidays.All() {
+			d, err := time.Parse(data.BadgeDateFormat, h.Date)
+			if err != nil || !inWindow(d) {
+				continue
+			}
+			out = append(out, allDay("holiday", h.Date, h.Name, d, d))
+		}
+	}
+
+	if include["event"] {
+		for _, e := range events.All() {
+			d, err := time.Parse(data.BadgeDateFormat, e.Date)
+			if err != nil || !inWindow(d) {
+				continue
+			}
+			out = append(out, allDay("event", e.Date+"-"+shortHash(e.Description), e.Description, d, d))
+		}
+	}
+
+	if include["required"] {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		for i := range periods {
+			tp := &periods[i]
+			if tp.EndDate.Before(today) || tp.EndDate.Before(from) || tp.StartDate.After(to) {
+				continue
+			}
+			stats, err := calc.CalculatePolicyStats(tp, badges, holidays, vacations, policy, &today)
+			if err != nil {
+				return ics.Calendar{}, fmt.Errorf("calculating stats for %s: %w", tp.Key, err)
+			}
+			for _, d := range calc.PlanRequiredDays(stats, today) {
+				if !inWindow(d) {
+					continue
+				}
+				key := d.Format(data.BadgeDateFormat)
+				ev := allDay("required", key, "Office day required", d, d)
+				ev.Description = fmt.Sprintf("Planned to reach the %d%% goal for %s (%d more office days needed).",
+					policy.Goal, tp.Name, stats.DaysStillNeeded)
+				if opts.Remind > 0 {
+					ev.Alarms = []ics.Alarm{{Before: opts.Remind, Description: "Office day required"}}
+				}
+				out = append(out, ev)
+			}
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return ics.Calendar{
+		ProdID: "-//rto//Return-to-Office Tracker//EN",
+		Name:   "rto",
+		Stamp:  now.UTC().Truncate(time.Second),
+		Events: out,
+	}, nil
+}
+
+// exportCategoryName returns the CATEGORIES value for an ICSCategories entry.
+func exportCategoryName(category string) string {
+	return "RTO " + strings.ToUpper(category[:1]) + category[1:]
+}
+
+func labelOr(label, fallback string) string {
+	if label == "" {
+		return fallback
+	}
+	return label
+}
+
+// shortHash distinguishes entries that share a date in their UID.
+func shortHash(s string) string {
+	return fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(s)))
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"rto/data"
+	"rto/ics"
+)
+
+func exportFixture() (*data.BadgeEntryData, *data.HolidayData, *data.VacationData, *data.EventData, *data.AppSettings, []data.TimePeriod) {
+	badges := data.NewBadgeEntryData()
+	badges.Add(data.NewOfficeBadge(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), "HQ"))
+	badges.Add(data.NewFlexBadge(time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC), "WFH"))
+	holidays := data.NewHolidayData()
+	holidays.Add(data.Holiday{Name: "Closed", Date: "2025-01-10"})
+	vacations := data.NewVacationData()
+	vacations.Add(data.Vacation{Destination: "Ski", StartDate: "2025-01-13", EndDate: "2025-01-14"})
+	events := data.NewEventData()
+	events.Add(data.Event{Date: "2025-01-08", Description: "Offsite"})
+	settings := &data.AppSettings{DefaultOffice: "HQ", FlexCredit: "WFH", Goal: 50}
+	tp := data.TimePeriod{Key: "Jan", Name: "Jan", StartDateRaw: "2025-01-06", EndDateRaw: "2025-01-17"}
+	_ = tp.ParseDates()
+	return badges, holidays, vacations, events, settings, []data.TimePeriod{tp}
+}
+
+func exportWindow() ICSExportOptions {
+	return ICSExportOptions{
+		From: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func eventByCategory(cal ics.Calendar, category string) []ics.Event {
+	var result []ics.Event
+	for _, ev := range cal.Events {
+		if ev.Categories[0] == category {
+			result = append(result, ev)
+		}
+	}
+	return result
+}
+
+func TestBuildICSExportCategories(t *testing.T) {
+	b, h, v, e, s, periods := exportFixture()
+	now := time.Date(2025, 1, 8, 10, 0, 0, 0, time.UTC)
+	cal, err := BuildICSExport(b, h, v, e, s, data.DefaultPolicy(s.Goal), periods, exportWindow(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if office := eventByCategory(cal, "RTO Office"); len(office) != 1 || office[0].Summary != "In office: HQ" || office[0].UID != "office-2025-01-06@rto" {
+		t.Errorf("unexpected office events: %+v", office)
+	}
+	if flex := eventByCategory(cal, "RTO Flex"); len(flex) != 1 || flex[0].Summary != "WFH" {
+		t.Errorf("unexpected flex events: %+v", flex)
+	}
+	vac := eventByCategory(cal, "RTO Vacation")
+	if len(vac) != 1 || vac[0].End.Day() != 15 || vac[0].Status != "TENTATIVE" {
+		t.Errorf("expected one tentative vacation ending (exclusively) on Jan 15, got %+v", vac)
+	}
+	if len(eventByCategory(cal, "RTO Holiday")) != 1 || len(eventByCategory(cal, "RTO Event")) != 1 {
+		t.Error("expected one holiday and one event")
+	}
+
+	// 10 weekdays - 1 holiday - 2 vacation = 7 days; 4 required, 2 recorded.
+	req := eventByCategory(cal, "RTO Required")
+	if len(req) != 2 || req[0].Start.Day() != 8 || req[1].Start.Day() != 9 {
+		t.Errorf("expected required days Jan 8 and 9, got %+v", req)
+	}
+	if len(req) > 0 && len(req[0].Alarms) != 0 {
+		t.Error("expected no reminders without --remind")
+	}
+
+	for i := 1; i < len(cal.Events); i++ {
+		if cal.Events[i].Start.Before(cal.Events[i-1].Start) {
+			t.Fatal("events should be sorted by start")
+		}
+	}
+}
+
+func TestBuildICSExportFilters(t *testing.T) {
+	b, h, v, e, s, periods := exportFixture()
+	now := time.Date(2025, 1, 8, 10, 0, 0, 0, time.UTC)
+
+	opts := exportWindow()
+	opts.Categories = []string{"required"}
+	opts.Remind = 15 * time.Hour
+	cal, _ := BuildICSExport(b, h, v, e, s, data.DefaultPolicy(s.Goal), periods, opts, now)
+	if len(cal.Events) != 2 || len(cal.Events[0].Alarms) != 1 || cal.Events[0].Alarms[0].Before != 15*time.Hour {
+		t.Errorf("expected only required days, with reminders: %+v", cal.Events)
+	}
+
+	opts = exportWindow()
+	opts.From = time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC)
+	cal, _ = BuildICSExport(b, h, v, e, s, data.DefaultPolicy(s.Goal), periods, opts, now)
+	for _, ev := range cal.Events {
+		if ev.End.AddDate(0, 0, -1).Before(opts.From) {
+			t.Errorf("event before --from exported: %+v", ev)
+		}
+	}
+
+	opts.Categories = []string{"badges"}
+	if _, err := BuildICSExport(b, h, v, e, s, data.DefaultPolicy(s.Goal), periods, opts, now); err == nil || !strings.Contains(err.Error(), "unknown category") {
+		t.Errorf("expected unknown category error, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"rto/calc"
+	"rto/data"
+)
+
+// RunHistory prints the stats of every period of a time period file that has
+// started, oldest first, in the selected output format. An empty file means
+// the default view.
+func RunHistory(file string) error {
+	settings, err := data.LoadAppSettings()
+	if err != nil {
+		return fmt.Errorf("loading settings: %w", err)
+	}
+	if file == "" {
+		file = settings.ActiveTimePeriodFile(0)
+	}
+	td, err := data.LoadTimePeriodDataFromStore(data.GetStore(), file)
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", file, err)
+	}
+	badges, err := data.LoadBadgeEntryData()
+	if err != nil {
+		return fmt.Errorf("loading badge data: %w", err)
+	}
+	holidays, err := data.LoadHolidayData()
+	if err != nil {
+		return fmt.Errorf("loading holidays: %w", err)
+	}
+	vacations, err := data.LoadVacationData()
+	if err != nil {
+		return fmt.Errorf("loading vacations: %w", err)
+	}
+	policy, err := data.LoadPolicy(settings)
+	if err != nil {
+		return fmt.Errorf("loading policy: %w", err)
+	}
+
+	history, err := calc.CalculateHistory(td.All(), badges, holidays, vacations, policy, time.Now())
+	if err != nil {
+		return fmt.Errorf("calculating stats: %w", err)
+	}
+	return WriteHistoryOutput(history, file, outputFormat, os.Stdout)
+}
+
+// WriteHistory writes one line per period and a sparkline of their rates.
+func WriteHistory(history []calc.HistoryEntry, w io.Writer) error {
+	if len(history) == 0 {
+		_, err := fmt.Fprintln(w, "No periods have started yet.")
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%-12s  %-23s  %8s  %6s  %4s  %6s  %6s  %s\n",
+		"Period", "Dates", "Required", "Badged", "Flex", "Rate", "Margin", "Status")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%-12s  %-23s  %8s  %6s  %4s  %6s  %6s  %s\n",
+		"------------", "-----------------------", "--------", "------", "----", "------", "------", "------------------")
+
+	var rates []float64
+	finished, met := 0, 0
+	for _, h := range history {
+		status := h.ComplianceStatus
+		if h.Complete {
+			finished++
+			if h.Margin >= 0 {
+				met++
+			}
+		} else {
+			status += " (in progress)"
+		}
+		fmt.Fprintf(w, "%-12s  %s – %s  %8d  %6d  %4d  %5.1f%%  %6s  %s\n",
+			h.Key, h.StartDate.Format(data.BadgeDateFormat), h.EndDate.Format(data.BadgeDateFormat),
+			h.DaysRequired, h.DaysBadgedIn, h.FlexDays, h.Rate*100, formatMargin(h.Margin), status)
+		rates = append(rates, h.Rate)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Rate trend:  %s  (goal %d%%)\n", calc.Sparkline(rates, 1), history[len(history)-1].GoalPct)
+	_, err = fmt.Fprintf(w, "Goal met in %d of %d finished periods.\n", met, finished)
+	return err
+}
+
+// formatMargin formats a surplus with a plus sign and a shortfall with a
+// minus sign.
+func formatMargin(d float64) string {
+	if d > 0 {
+		return "+" + formatDays(d)
+	}
+	return formatDays(d)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"rto/calc"
+	"rto/data"
+)
+
+func sampleHistory(t *testing.T) []calc.HistoryEntry {
+	t.Helper()
+	periods, _ := data.GeneratePeriods(data.PeriodSpec{Unit: data.UnitMonths, Year: 2025})
+	badges := data.NewBadgeEntryData()
+	for d := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); d.Month() == time.January; d = d.AddDate(0, 0, 1) {
+		if calc.IsWorkday(d) {
+			badges.Add(data.NewOfficeBadge(d, "HQ"))
+		}
+	}
+	today := time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)
+	history, err := calc.CalculateHistory(periods, badges, data.NewHolidayData(), data.NewVacationData(), data.DefaultPolicy(50), today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return history
+}
+
+func TestWriteHistory(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHistory(sampleHistory(t), &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"JAN_2025", "100.0%", "+11", "(in progress)", "Rate trend:  █▁", "Goal met in 1 of 1 finished periods."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	_ = WriteHistory(nil, &buf)
+	if !strings.Contains(buf.String(), "No periods") {
+		t.Errorf("expected a message for an empty history, got %q", buf.String())
+	}
+}
+
+func TestWriteHistoryOutput(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHistoryOutput(sampleHistory(t), "months.yaml", OutputJSON, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var out HistoryOutput
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.File != "months.yaml" || len(out.Periods) != 2 || out.Periods[0].Margin != 11 || out.Periods[1].Complete {
+		t.Errorf("unexpected output: %+v", out)
+	}
+
+	buf.Reset()
+	if err := WriteHistoryOutput(sampleHistory(t), "months.yaml", OutputCSV, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[1], "JAN_2025,Jan,2025-01-01") {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"strings"
 
 	"rto/data"
 )
@@ -14,7 +17,7 @@ func RunHolidays() error {
 	if err != nil {
 		return fmt.Errorf("loading holidays: %w", err)
 	}
-	return WriteHolidays(hd, os.Stdout)
+	return WriteHolidaysOutput(hd, outputFormat, os.Stdout)
 }
 
 // WriteHolidays formats and writes holiday data to the given writer.
@@ -26,17 +29,212 @@ func WriteHolidays(hd *data.HolidayData, w io.Writer) error {
 	}
 
 	// Header
-	_, err := fmt.Fprintf(w, "%-12s  %s\n", "Date", "Name")
+	_, err := fmt.Fprintf(w, "%-4s  %-12s  %s\n", "#", "Date", "Name")
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(w, "%-12s  %s\n", "------------", "------------------------------")
+	fmt.Fprintf(w, "%-4s  %-12s  %s\n", "----", "------------", "------------------------------")
 
-	for _, h := range all {
-		_, err := fmt.Fprintf(w, "%-12s  %s\n", h.Date, h.Name)
+	for i, h := range all {
+		_, err := fmt.Fprintf(w, "%-4d  %-12s  %s\n", i+1, h.Date, h.Name)
 		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// HolidayEdit lists the fields to change on an existing holiday; nil fields
+// are left as they are.
+type HolidayEdit struct {
+	Date *string
+	Name *string
+}
+
+// AddHoliday validates h and appends it. A date can hold only one holiday.
+func AddHoliday(hd *data.HolidayData, h data.Holiday) error {
+	if err := h.Validate(); err != nil {
+		return err
+	}
+	if err := checkHolidayDate(hd, h, -1); err != nil {
+		return err
+	}
+	hd.Add(h)
+	return nil
+}
+
+// EditHoliday applies edit to the holiday chosen by sel (a # or date) and
+// returns the updated holiday.
+func EditHoliday(hd *data.HolidayData, sel string, edit HolidayEdit) (data.Holiday, error) {
+	i, err := selectHoliday(hd, sel)
+	if err != nil {
+		return data.Holiday{}, err
+	}
+	h := hd.All()[i]
+	if edit.Date != nil {
+		h.Date = strings.TrimSpace(*edit.Date)
+	}
+	if edit.Name != nil {
+		h.Name = strings.TrimSpace(*edit.Name)
+	}
+	if err := h.Validate(); err != nil {
+		return data.Holiday{}, err
+	}
+	if err := checkHolidayDate(hd, h, i); err != nil {
+		return data.Holiday{}, err
+	}
+	hd.Update(i, h)
+	return h, nil
+}
+
+// RemoveHoliday deletes the holiday chosen by sel and returns it.
+func RemoveHoliday(hd *data.HolidayData, sel string) (data.Holiday, error) {
+	i, err := selectHoliday(hd, sel)
+	if err != nil {
+		return data.Holiday{}, err
+	}
+	h := hd.All()[i]
+	hd.RemoveAt(i)
+	return h, nil
+}
+
+// RunHolidayAdd adds a holiday to the global store.
+func RunHolidayAdd(h data.Holiday) error {
+	return modifyHolidays(func(hd *data.HolidayData) (string, error) {
+		if err := AddHoliday(hd, h); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Added holiday: %s (%s)", h.Name, h.Date), nil
+	})
+}
+
+// RunHolidayEdit edits a holiday in the global store.
+func RunHolidayEdit(sel string, edit HolidayEdit) error {
+	return modifyHolidays(func(hd *data.HolidayData) (string, error) {
+		h, err := EditHoliday(hd, sel, edit)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Updated holiday: %s (%s)", h.Name, h.Date), nil
+	})
+}
+
+// RunHolidayRemove removes a holiday from the global store.
+func RunHolidayRemove(sel string) error {
+	return modifyHolidays(func(hd *data.HolidayData) (string, error) {
+		h, err := RemoveHoliday(hd, sel)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Removed holiday: %s (%s)", h.Name, h.Date), nil
+	})
+}
+
+// HolidayGenerateOptions configures RunHolidayGenerate.
+type HolidayGenerateOptions struct {
+	Year   int
+	Set    string // a built-in set name, or the path of a holiday rules file
+	DryRun bool
+}
+
+// RunHolidayGenerate generates the holidays of a rule set for a year and
+// merges them into holidays.yaml, skipping dates that already have one.
+func RunHolidayGenerate(opts HolidayGenerateOptions) error {
+	set, err := LoadHolidaySet(opts.Set)
+	if err != nil {
+		return err
+	}
+
+	return lockUnless(opts.DryRun, func() error {
+		hd, err := data.LoadHolidayData()
+		if err != nil {
+			return fmt.Errorf("loading holidays: %w", err)
+		}
+		results := MergeHolidays(hd, set.Generate(opts.Year))
+		if !opts.DryRun && countAdded(results) > 0 {
+			if err := hd.Save(); err != nil {
+				return fmt.Errorf("saving holidays: %w", err)
+			}
+		}
+		fmt.Printf("%s, %d:\n", set.Name, opts.Year)
+		return WriteImportReport(results, "holidays", opts.DryRun, os.Stdout)
+	})
+}
+
+// LoadHolidaySet returns the built-in holiday set called name or, failing
+// that, the set in the rules file at path name.
+func LoadHolidaySet(name string) (data.HolidaySet, error) {
+	if set, ok := data.HolidaySets[name]; ok {
+		return set, nil
+	}
+	raw, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return data.HolidaySet{}, fmt.Errorf("unknown holiday set %q (expected %s, or a rules file)",
+			name, strings.Join(data.HolidaySetNames(), ", "))
+	}
+	if err != nil {
+		return data.HolidaySet{}, err
+	}
+	set, err := data.ParseHolidaySet(raw)
+	if err != nil {
+		return data.HolidaySet{}, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	if set.Name == "" {
+		set.Name = name
+	}
+	return set, nil
+}
+
+// MergeHolidays adds each of holidays whose date has no holiday yet.
+func MergeHolidays(hd *data.HolidayData, holidays []data.Holiday) []ImportResult {
+	existing := hd.GetHolidayMap()
+	var results []ImportResult
+	for _, h := range holidays {
+		r := ImportResult{Date: h.Date, Name: h.Name}
+		if prev, ok := existing[h.Date]; ok {
+			r.Reason = "already a holiday: " + prev.Name
+		} else {
+			hd.Add(h)
+			existing[h.Date] = h
+			r.Added = true
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func modifyHolidays(fn func(hd *data.HolidayData) (string, error)) error {
+	return withWriteLock(func() error {
+		hd, err := data.LoadHolidayData()
+		if err != nil {
+			return fmt.Errorf("loading holidays: %w", err)
+		}
+		msg, err := fn(hd)
+		if err != nil {
+			return err
+		}
+		if err := hd.Save(); err != nil {
+			return fmt.Errorf("saving holidays: %w", err)
+		}
+		fmt.Println(msg)
+		return nil
+	})
+}
+
+func selectHoliday(hd *data.HolidayData, sel string) (int, error) {
+	all := hd.All()
+	dates := make([]string, len(all))
+	for i, h := range all {
+		dates[i] = h.Date
+	}
+	return resolveSelector(sel, "holiday", dates, func(i int) string { return all[i].Name })
+}
+
+func checkHolidayDate(hd *data.HolidayData, h data.Holiday, skip int) error {
+	for i, existing := range hd.All() {
+		if i != skip && existing.Date == h.Date {
+			return fmt.Errorf("%s is already a holiday (%s)", h.Date, existing.Name)
+		}
+	}
+	return nil
+}
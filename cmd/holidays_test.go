@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -69,3 +71,62 @@ func TestWriteHolidaysMultiple(t *testing.T) {
 		t.Errorf("expected at least 5 lines, got %d", len(lines))
 	}
 }
+
+func TestHolidayCRUD(t *testing.T) {
+	hd := data.NewHolidayData()
+	if err := AddHoliday(hd, data.Holiday{Name: "Christmas", Date: "2025-12-25"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := AddHoliday(hd, data.Holiday{Name: "Xmas", Date: "2025-12-25"}); err == nil {
+		t.Error("expected one-holiday-per-date error")
+	}
+	if err := AddHoliday(hd, data.Holiday{Name: "", Date: "2025-12-26"}); err == nil {
+		t.Error("expected missing name error")
+	}
+	if err := AddHoliday(hd, data.Holiday{Name: "Boxing Day", Date: "2025-12-26"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	date := "2025-12-25"
+	if _, err := EditHoliday(hd, "2025-12-26", HolidayEdit{Date: &date}); err == nil {
+		t.Error("edit onto an existing holiday's date should fail")
+	}
+	name := "Christmas Day"
+	h, err := EditHoliday(hd, "1", HolidayEdit{Name: &name})
+	if err != nil || h.Name != name || h.Date != "2025-12-25" {
+		t.Errorf("edit: got %+v, %v", h, err)
+	}
+	if _, err := RemoveHoliday(hd, "2025-12-26"); err != nil || hd.Len() != 1 {
+		t.Errorf("remove: %v (len %d)", err, hd.Len())
+	}
+}
+
+func TestGenerateHolidaysMerge(t *testing.T) {
+	set, err := LoadHolidaySet("us-federal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hd := data.NewHolidayData()
+	hd.Add(data.Holiday{Name: "Company Day", Date: "2027-07-05"})
+	results := MergeHolidays(hd, set.Generate(2027))
+	if countAdded(results) != 11 || hd.Len() != 12 {
+		t.Errorf("expected 11 of 12 holidays added, got %d (%d total)", countAdded(results), hd.Len())
+	}
+	if m := hd.GetHolidayMap(); m["2027-07-05"].Name != "Company Day" {
+		t.Errorf("an existing holiday should be kept, got %q", m["2027-07-05"].Name)
+	}
+	if again := MergeHolidays(hd, set.Generate(2027)); countAdded(again) != 0 {
+		t.Errorf("generating twice should add nothing, added %d", countAdded(again))
+	}
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte("rules:\n  - name: Founders' Day\n    month: 3\n    day: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if set, err := LoadHolidaySet(path); err != nil || set.Name != path || len(set.Generate(2027)) != 1 {
+		t.Errorf("unexpected set from a rules file: %+v, %v", set, err)
+	}
+	if _, err := LoadHolidaySet("atlantis"); err == nil || !strings.Contains(err.Error(), "us-federal") {
+		t.Errorf("expected an unknown-set error listing the built-in sets, got %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"rto/data"
+	"rto/ics"
+)
+
+// ICSImportOptions configures RunImportICS.
+type ICSImportOptions struct {
+	Path     string
+	As       string    // "holidays", "vacations" or "events"
+	From, To time.Time // only occurrences starting in [From, To] are imported
+	Approved bool      // mark imported vacations approved
+	Timed    bool      // also import timed events as holidays or vacations
+	DryRun   bool
+}
+
+// ImportResult describes one entry considered by an import.
+type ImportResult struct {
+	Date    string
+	EndDate string // last day, for vacations
+	Name    string
+	Added   bool
+	Updated bool   // an existing entry was moved to these dates
+	Reason  string // why an entry was not added
+}
+
+// RunImportICS reads an iCalendar file and merges its events into holidays,
+// vacations or events, skipping entries that are already present. Only
+// all-day events become holidays or vacations, unless opts.Timed is set: a
+// calendar export is mostly meetings.
+func RunImportICS(opts ICSImportOptions) error {
+	f, err := os.Open(opts.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	events, err := ics.Parse(f)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", opts.Path, err)
+	}
+	occs, err := ics.Expand(events, opts.From, opts.To)
+	if err != nil {
+		return fmt.Errorf("expanding %s: %w", opts.Path, err)
+	}
+	timed := 0
+	if opts.As != "events" && !opts.Timed {
+		occs, timed = allDayOccurrences(occs)
+	}
+
+	return lockUnless(opts.DryRun, func() error {
+		var results []ImportResult
+		var save func() error
+		switch opts.As {
+		case "holidays":
+			hd, err := data.LoadHolidayData()
+			if err != nil {
+				return fmt.Errorf("loading holidays: %w", err)
+			}
+			results, save = ImportHolidays(hd, occs), hd.Save
+		case "vacations":
+			vd, err := data.LoadVacationData()
+			if err != nil {
+				return fmt.Errorf("loading vacations: %w", err)
+			}
+			results, save = ImportVacations(vd, occs, opts.Approved), vd.Save
+		case "events":
+			ed, err := data.LoadEventData()
+			if err != nil {
+				return fmt.Errorf("loading events: %w", err)
+			}
+			results, save = ImportEvents(ed, occs), ed.Save
+		default:
+			return fmt.Errorf("unknown import target %q (expected holidays, vacations or events)", opts.As)
+		}
+
+		if !opts.DryRun && countAdded(results)+countUpdated(results) > 0 {
+			if err := save(); err != nil {
+				return fmt.Errorf("saving %s: %w", opts.As, err)
+			}
+		}
+		if err := WriteImportReport(results, opts.As, opts.DryRun, os.Stdout); err != nil {
+			return err
+		}
+		if timed > 0 {
+			_, err := fmt.Printf("Skipped %d timed event(s); use --timed to import them as %s too.\n", timed, opts.As)
+			return err
+		}
+		return nil
+	})
+}
+
+// allDayOccurrences returns the all-day occurrences in occs and how many
+// timed ones were left out.
+func allDayOccurrences(occs []ics.Occurrence) ([]ics.Occurrence, int) {
+	var kept []ics.Occurrence
+	for _, o := range occs {
+		if o.AllDay {
+			kept = append(kept, o)
+		}
+	}
+	return kept, len(occs) - len(kept)
+}
+
+// ImportHolidays adds one holiday per day covered by each occurrence. A day
+// that already has a holiday is skipped.
+func ImportHolidays(hd *data.HolidayData, occs []ics.Occurrence) []ImportResult {
+	var holidays []data.Holiday
+	for _, o := range occs {
+		for _, d := range o.Days() {
+			holidays = append(holidays, data.Holiday{Name: occurrenceName(o), Date: d.Format(data.BadgeDateFormat), UID: o.UID})
+		}
+	}
+	return MergeHolidays(hd, holidays)
+}
+
+// ImportVacations adds one vacation per occurrence, spanning the days it
+// covers. An occurrence matching an existing vacation's UID and start date,
+// or its exact date range, is skipped. A one-off event whose UID matches a
+// single existing vacation with other dates was moved in the calendar: the
+// vacation takes its dates and name and keeps everything else.
+func ImportVacations(vd *data.VacationData, occs []ics.Occurrence, approved bool) []ImportResult {
+	perUID := map[string]int{}
+	for _, o := range occs {
+		perUID[o.UID]++
+	}
+
+	var results []ImportResult
+	for _, o := range occs {
+		days := o.Days()
+		v := data.Vacation{
+			Destination: occurrenceName(o),
+			StartDate:   days[0].Format(data.BadgeDateFormat),
+			EndDate:     days[len(days)-1].Format(data.BadgeDateFormat),
+			Approved:    approved,
+			UID:         o.UID,
+		}
+		r := ImportResult{Date: v.StartDate, EndDate: v.EndDate, Name: v.Destination}
+		if i, prev, ok := movedVacation(vd, v, perUID[v.UID]); ok {
+			prev.Destination, prev.StartDate, prev.EndDate = v.Destination, v.StartDate, v.EndDate
+			vd.Update(i, prev)
+			r.Updated = true
+			results = append(results, r)
+			continue
+		}
+		for _, prev := range vd.All() {
+			if (prev.UID != "" && prev.UID == v.UID && prev.StartDate == v.StartDate) ||
+				(prev.StartDate == v.StartDate && prev.EndDate == v.EndDate) {
+				r.Reason = "already a vacation: " + prev.Destination
+				break
+			}
+		}
+		if r.Reason == "" {
+			vd.Add(v)
+			r.Added = true
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// movedVacation returns the index and value of the vacation that v moves:
+// the only one with v's UID, if its dates differ. occurrences is how many
+// occurrences of v's event are being imported; a recurring event's
+// occurrences share a UID, so they never move a vacation.
+func movedVacation(vd *data.VacationData, v data.Vacation, occurrences int) (int, data.Vacation, bool) {
+	if v.UID == "" || occurrences != 1 {
+		return 0, data.Vacation{}, false
+	}
+	found := -1
+	for i, prev := range vd.All() {
+		if prev.UID != v.UID {
+			continue
+		}
+		if found >= 0 {
+			return 0, data.Vacation{}, false
+		}
+		found = i
+	}
+	if found < 0 {
+		return 0, data.Vacation{}, false
+	}
+	prev := vd.All()[found]
+	if prev.StartDate == v.StartDate && prev.EndDate == v.EndDate {
+		return 0, data.Vacation{}, false
+	}
+	return found, prev, true
+}
+
+// ImportEvents adds one event per day covered by each occurrence. A day that
+// already has an event with the same UID or description is skipped.
+func ImportEvents(ed *data.EventData, occs []ics.Occurrence) []ImportResult {
+	var results []ImportResult
+	for _, o := range occs {
+		for _, d := range o.Days() {
+			ev := data.Event{Date: d.Format(data.BadgeDateFormat), Description: occurrenceName(o), UID: o.UID}
+			r := ImportResult{Date: ev.Date, Name: ev.Description}
+			for _, prev := range ed.GetEventMap()[ev.Date] {
+				if (prev.UID != "" && prev.UID == ev.UID) || prev.Description == ev.Description {
+					r.Reason = "already an event: " + prev.Description
+					break
+				}
+			}
+			if r.Reason == "" {
+				ed.Add(ev)
+				r.Added = true
+			}
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// WriteImportReport lists each imported (+), updated (~) and skipped (=)
+// entry.
+func WriteImportReport(results []ImportResult, what string, dryRun bool, w io.Writer) error {
+	if len(results) == 0 {
+		_, err := fmt.Fprintln(w, "No events found in the import window.")
+		return err
+	}
+	for _, r := range results {
+		dates := r.Date
+		if r.EndDate != "" && r.EndDate != r.Date {
+			dates += " – " + r.EndDate
+		}
+		switch {
+		case r.Added:
+			fmt.Fprintf(w, "  + %-25s  %s\n", dates, r.Name)
+		case r.Updated:
+			fmt.Fprintf(w, "  ~ %-25s  %s  (moved)\n", dates, r.Name)
+		default:
+			fmt.Fprintf(w, "  = %-25s  %s  (%s)\n", dates, r.Name, r.Reason)
+		}
+	}
+
+	added, updated := countAdded(results), countUpdated(results)
+	verb, updateVerb := "Added", "updated"
+	if dryRun {
+		verb, updateVerb = "Would add", "update"
+	}
+	changes := fmt.Sprintf("%s %d %s", verb, added, what)
+	if updated > 0 {
+		changes += fmt.Sprintf(", %s %d", updateVerb, updated)
+	}
+	fmt.Fprintln(w)
+	_, err := fmt.Fprintf(w, "%s; %d already present.\n", changes, len(results)-added-updated)
+	return err
+}
+
+func countAdded(results []ImportResult) int {
+	n := 0
+	for _, r := range results {
+		if r.Added {
+			n++
+		}
+	}
+	return n
+}
+
+func countUpdated(results []ImportResult) int {
+	n := 0
+	for _, r := range results {
+		if r.Updated {
+			n++
+		}
+	}
+	return n
+}
+
+func occurrenceName(o ics.Occurrence) string {
+	if o.Summary == "" {
+		return "(untitled)"
+	}
+	return o.Summary
+}
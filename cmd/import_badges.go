@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rto/data"
+)
+
+// swipeTimeFormats are tried, in order, when no timestamp_format is set.
+var swipeTimeFormats = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"01/02/2006 15:04:05",
+	"01/02/2006 15:04",
+	"1/2/2006 3:04:05 PM",
+	"1/2/2006 3:04 PM",
+}
+
+// BadgeImportOptions configures RunImportBadges.
+type BadgeImportOptions struct {
+	Path        string
+	Mapping     data.BadgeImportSettings // settings.yaml badge_import, with flags applied
+	SaveMapping bool                     // store Mapping in settings.yaml
+	DryRun      bool
+}
+
+// Swipe is one row of a badge-swipe export.
+type Swipe struct {
+	Time     time.Time // in the mapping's timezone
+	Location string
+}
+
+// BadgeImportResult describes the badge entry derived from one day's swipes.
+type BadgeImportResult struct {
+	Date   time.Time
+	First  time.Time // first swipe of the day
+	Swipes int
+	Office string
+	Change data.BadgeChange
+	Reason string // why the day was skipped or left unchanged, if it was
+}
+
+// RunImportBadges reads a badge-swipe CSV export and records one office
+// badge-in per day with swipes, using the first swipe's time and mapped
+// office.
+func RunImportBadges(opts BadgeImportOptions) error {
+	f, err := os.Open(opts.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	swipes, err := ParseSwipes(f, opts.Mapping)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", opts.Path, err)
+	}
+
+	return lockUnless(opts.DryRun, func() error {
+		badges, err := data.LoadBadgeEntryData()
+		if err != nil {
+			return fmt.Errorf("loading badge data: %w", err)
+		}
+		holidays, err := data.LoadHolidayData()
+		if err != nil {
+			return fmt.Errorf("loading holidays: %w", err)
+		}
+		vacations, err := data.LoadVacationData()
+		if err != nil {
+			return fmt.Errorf("loading vacations: %w", err)
+		}
+		settings, err := data.LoadAppSettings()
+		if err != nil {
+			return fmt.Errorf("loading settings: %w", err)
+		}
+
+		results := ImportBadges(badges, holidays, vacations, opts.Mapping, settings.DefaultOffice, swipes)
+		if !opts.DryRun && countBadgeChanges(results) > 0 {
+			if err := badges.Save(); err != nil {
+				return fmt.Errorf("saving badge data: %w", err)
+			}
+		}
+		if !opts.DryRun && opts.SaveMapping {
+			settings.BadgeImport = opts.Mapping
+			if err := settings.Save(); err != nil {
+				return fmt.Errorf("saving settings: %w", err)
+			}
+		}
+		return WriteBadgeImportReport(results, opts.DryRun, os.Stdout)
+	})
+}
+
+// ParseSwipes reads the swipes in a CSV export with a header row, keeping
+// only rows for m.CardID when it is set. Timestamps without a UTC offset are
+// read in m.Timezone; all are returned in that zone.
+func ParseSwipes(r io.Reader, m data.BadgeImportSettings) ([]Swipe, error) {
+	loc := time.Local
+	if m.Timezone != "" {
+		l, err := time.LoadLocation(m.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone %q", m.Timezone)
+		}
+		loc = l
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tsCol := m.TimestampColumn
+	if tsCol == "" {
+		tsCol = "timestamp"
+	}
+	ts, err := csvColumn(header, tsCol, "timestamp_column")
+	if err != nil {
+		return nil, err
+	}
+	locCol, cardCol := -1, -1
+	if m.LocationColumn != "" {
+		if locCol, err = csvColumn(header, m.LocationColumn, "location_column"); err != nil {
+			return nil, err
+		}
+	}
+	if m.CardID != "" {
+		if m.CardColumn == "" {
+			return nil, fmt.Errorf("card_id is set but card_column is not")
+		}
+		if cardCol, err = csvColumn(header, m.CardColumn, "card_column"); err != nil {
+			return nil, err
+		}
+	}
+
+	var swipes []Swipe
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(i int) string {
+			if i < 0 || i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+		if cardCol >= 0 && field(cardCol) != m.CardID {
+			continue
+		}
+		if field(ts) == "" {
+			continue // blank or trailer row
+		}
+		t, err := parseSwipeTime(field(ts), m.TimestampFormat, loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		swipes = append(swipes, Swipe{Time: t, Location: field(locCol)})
+	}
+	return swipes, nil
+}
+
+// ImportBadges collapses swipes into one office badge-in per day, stamped
+// with the day's first swipe and the office its location maps to. Days
+// that are weekends, holidays or vacation days are skipped, and existing
+// entries are handled as by rto badge: an office entry keeps its time but
+// takes the mapped office, and a flex credit is left alone.
+func ImportBadges(badges *data.BadgeEntryData, holidays *data.HolidayData, vacations *data.VacationData,
+	m data.BadgeImportSettings, defaultOffice string, swipes []Swipe) []BadgeImportResult {
+
+	byDay := map[string]*BadgeImportResult{}
+	for _, s := range swipes {
+		key := s.Time.Format(data.BadgeDateFormat)
+		r, ok := byDay[key]
+		if !ok {
+			r = &BadgeImportResult{Date: time.Date(s.Time.Year(), s.Time.Month(), s.Time.Day(), 0, 0, 0, 0, time.UTC), First: s.Time}
+			byDay[key] = r
+		}
+		r.Swipes++
+		if !s.Time.After(r.First) {
+			r.First = s.Time
+			r.Office = mapOffice(m.Offices, s.Location, defaultOffice)
+		}
+	}
+
+	holidayMap := holidays.GetHolidayMap()
+	vacationMap := vacations.GetVacationMap()
+	var results []BadgeImportResult
+	for key, r := range byDay {
+		if r.Reason = dayOffReason(key, r.Date, holidayMap, vacationMap); r.Reason == "" {
+			// Badge times are stored as naive wall-clock times.
+			stamp := time.Date(r.First.Year(), r.First.Month(), r.First.Day(), r.First.Hour(), r.First.Minute(), r.First.Second(), 0, time.UTC)
+			r.Change = badges.SetBadge(data.NewOfficeBadge(stamp, r.Office), false)
+			if r.Change == data.BadgeConflict {
+				r.Reason = "flex credit already recorded"
+			}
+		}
+		results = append(results, *r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Date.Before(results[j].Date) })
+	return results
+}
+
+// WriteBadgeImportReport writes one line per day with swipes and a summary.
+func WriteBadgeImportReport(results []BadgeImportResult, dryRun bool, w io.Writer) error {
+	if len(results) == 0 {
+		_, err := fmt.Fprintln(w, "No swipes found.")
+		return err
+	}
+	for _, r := range results {
+		var msg string
+		switch r.Change {
+		case data.BadgeAdded:
+			msg = "added"
+		case data.BadgeUpdated:
+			msg = "updated office"
+		case data.BadgeConflict:
+			msg = "unchanged: " + r.Reason
+		default:
+			if r.Reason != "" {
+				msg = "skipped: " + r.Reason
+			} else {
+				msg = "already recorded"
+			}
+		}
+		fmt.Fprintf(w, "  %s  %s  %s  %-20s  %2d swipe(s)  %s\n",
+			r.Date.Format(data.BadgeDateFormat), r.Date.Format("Mon"), r.First.Format("15:04"), r.Office, r.Swipes, msg)
+	}
+	verb := "changed"
+	if dryRun {
+		verb = "would change"
+	}
+	fmt.Fprintln(w)
+	_, err := fmt.Fprintf(w, "%d of %d day(s) %s.\n", countBadgeChanges(results), len(results), verb)
+	return err
+}
+
+func countBadgeChanges(results []BadgeImportResult) int {
+	n := 0
+	for _, r := range results {
+		if r.Change == data.BadgeAdded || r.Change == data.BadgeUpdated {
+			n++
+		}
+	}
+	return n
+}
+
+// csvColumn resolves a column given by header name (case-insensitive) or
+// 1-based number.
+func csvColumn(header []string, col, setting string) (int, error) {
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), col) {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(col); err == nil && n >= 1 && n <= len(header) {
+		return n - 1, nil
+	}
+	return -1, fmt.Errorf("%s %q not found in header (%s)", setting, col, strings.Join(header, ", "))
+}
+
+func parseSwipeTime(s, layout string, loc *time.Location) (time.Time, error) {
+	layouts := swipeTimeFormats
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l, s, loc); err == nil {
+			return t.In(loc), nil
+		}
+	}
+	if layout != "" {
+		return time.Time{}, fmt.Errorf("timestamp %q doesn't match timestamp_format %q", s, layout)
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q (set timestamp_format)", s)
+}
+
+// mapOffice returns the office for a swipe location: an exact key of
+// offices, else the longest key contained in it (case-insensitively), else
+// fallback.
+func mapOffice(offices map[string]string, location, fallback string) string {
+	if office, ok := offices[location]; ok {
+		return office
+	}
+	best := ""
+	lower := strings.ToLower(location)
+	for k := range offices {
+		if k != "" && strings.Contains(lower, strings.ToLower(k)) && (len(k) > len(best) || (len(k) == len(best) && k < best)) {
+			best = k
+		}
+	}
+	if best != "" {
+		return offices[best]
+	}
+	return fallback
+}
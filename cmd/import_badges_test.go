@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"rto/data"
+)
+
+const swipeCSV = `Event Time,Door,Card
+2025-03-03 08:47:12,HQ-B2-Main,1234
+2025-03-03 07:55:00,HQ-B2-Garage,1234
+2025-03-03 07:00:00,HQ-B2-Main,9999
+2025-03-04T13:30:00Z,Reston Lobby,1234
+2025-03-08 09:00:00,HQ-B2-Main,1234
+,,
+`
+
+func swipeMapping() data.BadgeImportSettings {
+	return data.BadgeImportSettings{
+		TimestampColumn: "event time",
+		Timezone:        "America/New_York",
+		LocationColumn:  "Door",
+		CardColumn:      "3",
+		CardID:          "1234",
+		Offices:         map[string]string{"HQ-": "McLean, VA", "hq-b2-garage": "Garage", "Reston": "Reston, VA"},
+	}
+}
+
+func TestParseSwipes(t *testing.T) {
+	swipes, err := ParseSwipes(strings.NewReader(swipeCSV), swipeMapping())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(swipes) != 4 {
+		t.Fatalf("expected 4 swipes for card 1234, got %d", len(swipes))
+	}
+	// 13:30Z is 08:30 in New York (EST until Mar 9, 2025).
+	if got := swipes[2].Time.Format("2006-01-02 15:04"); got != "2025-03-04 08:30" {
+		t.Errorf("expected offset timestamp converted to the mapping zone, got %s", got)
+	}
+	if swipes[0].Location != "HQ-B2-Main" {
+		t.Errorf("unexpected location %q", swipes[0].Location)
+	}
+}
+
+func TestParseSwipesErrors(t *testing.T) {
+	m := swipeMapping()
+	m.TimestampColumn = "When"
+	if _, err := ParseSwipes(strings.NewReader(swipeCSV), m); err == nil || !strings.Contains(err.Error(), "timestamp_column") {
+		t.Errorf("expected missing column error, got %v", err)
+	}
+
+	m = swipeMapping()
+	m.TimestampFormat = "01/02/2006"
+	if _, err := ParseSwipes(strings.NewReader(swipeCSV), m); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected format error with line number, got %v", err)
+	}
+
+	m = swipeMapping()
+	m.CardColumn = ""
+	if _, err := ParseSwipes(strings.NewReader(swipeCSV), m); err == nil {
+		t.Error("expected error for card_id without card_column")
+	}
+}
+
+func TestImportBadgesCollapsesDays(t *testing.T) {
+	swipes, _ := ParseSwipes(strings.NewReader(swipeCSV), swipeMapping())
+	badges := data.NewBadgeEntryData()
+	badges.Add(data.NewFlexBadge(time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC), "Flex"))
+
+	results := ImportBadges(badges, data.NewHolidayData(), data.NewVacationData(), swipeMapping(), "Default", swipes)
+	if len(results) != 3 {
+		t.Fatalf("expected one result per day, got %d", len(results))
+	}
+
+	mon := results[0]
+	if mon.Change != data.BadgeAdded || mon.Swipes != 2 || mon.Office != "Garage" {
+		t.Errorf("expected first swipe (07:55, garage) to win, got %+v", mon)
+	}
+	e, _ := badges.Get("2025-03-03")
+	if e.DateTime.Format("15:04:05") != "07:55:00" || e.Office != "Garage" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if results[1].Change != data.BadgeConflict {
+		t.Errorf("expected flex day to be left alone, got %+v", results[1])
+	}
+	if results[2].Reason != "weekend" {
+		t.Errorf("expected Saturday to be skipped, got %+v", results[2])
+	}
+
+	// Importing again changes nothing.
+	again := ImportBadges(badges, data.NewHolidayData(), data.NewVacationData(), swipeMapping(), "Default", swipes)
+	if countBadgeChanges(again) != 0 {
+		t.Errorf("expected re-import to be a no-op, got %+v", again)
+	}
+}
+
+func TestMapOffice(t *testing.T) {
+	offices := map[string]string{"HQ": "McLean", "HQ-B2": "McLean B2", "Lobby 1": "Exact"}
+	cases := map[string]string{
+		"Lobby 1":     "Exact",
+		"hq-b2-door":  "McLean B2",
+		"HQ-B1-door":  "McLean",
+		"Parking lot": "Fallback",
+	}
+	for loc, want := range cases {
+		if got := mapOffice(offices, loc, "Fallback"); got != want {
+			t.Errorf("mapOffice(%q) = %q, want %q", loc, got, want)
+		}
+	}
+}
+
+func TestWriteBadgeImportReport(t *testing.T) {
+	results := []BadgeImportResult{
+		{Date: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), First: time.Date(2025, 3, 3, 7, 55, 0, 0, time.UTC), Swipes: 2, Office: "HQ", Change: data.BadgeAdded},
+		{Date: time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC), Swipes: 1, Office: "HQ", Reason: "weekend"},
+	}
+	var buf bytes.Buffer
+	WriteBadgeImportReport(results, true, &buf)
+	out := buf.String()
+	for _, want := range []string{"2025-03-03  Mon  07:55  HQ", "added", "skipped: weekend", "1 of 2 day(s) would change."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"rto/data"
+	"rto/ics"
+)
+
+func allDay(uid, summary, start string, days int) ics.Occurrence {
+	s, _ := time.Parse(data.BadgeDateFormat, start)
+	return ics.Occurrence{UID: uid, Summary: summary, Start: s, End: s.AddDate(0, 0, days), AllDay: true}
+}
+
+func TestImportHolidaysSkipsExistingDates(t *testing.T) {
+	hd := data.NewHolidayData()
+	hd.Add(data.Holiday{Name: "New Year", Date: "2025-01-01"})
+
+	results := ImportHolidays(hd, []ics.Occurrence{
+		allDay("ny", "New Year's Day", "2025-01-01", 1),
+		allDay("tg", "Thanksgiving", "2025-11-27", 2),
+	})
+	if len(results) != 3 {
+		t.Fatalf("expected one result per day, got %d", len(results))
+	}
+	if results[0].Added || !strings.Contains(results[0].Reason, "New Year") {
+		t.Errorf("expected existing holiday to be skipped, got %+v", results[0])
+	}
+	if hd.Len() != 3 {
+		t.Errorf("expected 3 holidays, got %d", hd.Len())
+	}
+	if h := hd.GetHolidayMap()["2025-11-28"]; h.UID != "tg" {
+		t.Errorf("expected imported holiday to keep its UID, got %+v", h)
+	}
+}
+
+func TestImportVacationsDedupe(t *testing.T) {
+	vd := data.NewVacationData()
+	vd.Add(data.Vacation{Destination: "Beach", StartDate: "2025-07-01", EndDate: "2025-07-04"})
+	vd.Add(data.Vacation{Destination: "Ski", StartDate: "2025-02-10", EndDate: "2025-02-11", UID: "ski"})
+
+	results := ImportVacations(vd, []ics.Occurrence{
+		allDay("beach", "Beach trip", "2025-07-01", 4),   // same range
+		allDay("ski", "Ski (extended)", "2025-02-10", 5), // same UID and start
+		allDay("fam", "Family", "2025-08-04", 5),
+	}, true)
+
+	if results[0].Added || results[1].Added || !results[2].Added {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if vd.Len() != 3 {
+		t.Fatalf("expected 3 vacations, got %d", vd.Len())
+	}
+	v := vd.All()[2]
+	if v.StartDate != "2025-08-04" || v.EndDate != "2025-08-08" || !v.Approved || v.UID != "fam" {
+		t.Errorf("unexpected imported vacation: %+v", v)
+	}
+}
+
+func TestImportVacationsMoved(t *testing.T) {
+	vd := data.NewVacationData()
+	vd.Add(data.Vacation{Destination: "Beach", StartDate: "2025-07-01", EndDate: "2025-07-04", UID: "beach", Type: data.LeaveSick})
+	vd.Add(data.Vacation{Destination: "Standup", StartDate: "2025-03-03", EndDate: "2025-03-03", UID: "weekly"})
+
+	results := ImportVacations(vd, []ics.Occurrence{
+		allDay("beach", "Beach trip", "2025-07-14", 5), // moved in the calendar
+		allDay("weekly", "Standup", "2025-03-10", 1),   // recurring: a new occurrence
+		allDay("weekly", "Standup", "2025-03-17", 1),
+	}, true)
+
+	if !results[0].Updated || results[0].Added || !results[1].Added || !results[2].Added {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if vd.Len() != 4 {
+		t.Fatalf("expected the moved vacation to be updated in place, got %d vacations", vd.Len())
+	}
+	v := vd.All()[0]
+	if v.StartDate != "2025-07-14" || v.EndDate != "2025-07-18" || v.Destination != "Beach trip" || v.Type != data.LeaveSick || v.Approved {
+		t.Errorf("expected new dates and name with the rest kept, got %+v", v)
+	}
+
+	var buf bytes.Buffer
+	WriteImportReport(results, "vacations", false, &buf)
+	for _, want := range []string{"~ 2025-07-14 – 2025-07-18", "(moved)", "Added 2 vacations, updated 1; 0 already present."} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestImportSkipsTimedEvents(t *testing.T) {
+	cal := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:pto\r\nSUMMARY:PTO\r\nDTSTART;VALUE=DATE:20250303\r\nDTEND;VALUE=DATE:20250305\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:sync\r\nSUMMARY:Team sync\r\nDTSTART:20250306T150000Z\r\nDTEND:20250306T153000Z\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	events, err := ics.Parse(strings.NewReader(cal))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	from, to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	occs, err := ics.Expand(events, from, to)
+	if err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+
+	occs, timed := allDayOccurrences(occs)
+	if timed != 1 || len(occs) != 1 {
+		t.Fatalf("expected the meeting to be left out, got %d kept, %d timed", len(occs), timed)
+	}
+	hd := data.NewHolidayData()
+	ImportHolidays(hd, occs)
+	if hd.Len() != 2 {
+		t.Errorf("expected only the all-day event's 2 days, got %+v", hd.All())
+	}
+	if _, ok := hd.GetHolidayMap()["2025-03-06"]; ok {
+		t.Error("a timed meeting became a holiday")
+	}
+}
+
+func TestImportEventsDedupe(t *testing.T) {
+	ed := data.NewEventData()
+	ed.Add(data.Event{Date: "2025-03-03", Description: "All hands"})
+
+	results := ImportEvents(ed, []ics.Occurrence{
+		allDay("ah", "All hands", "2025-03-03", 1),
+		allDay("ah", "All hands", "2025-03-04", 1),
+		allDay("ah", "All hands (renamed)", "2025-03-04", 1), // same UID, same day
+	})
+	if results[0].Added || !results[1].Added || results[2].Added {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if ed.Len() != 2 {
+		t.Errorf("expected 2 events, got %d", ed.Len())
+	}
+}
+
+func TestWriteImportReport(t *testing.T) {
+	results := []ImportResult{
+		{Date: "2025-07-01", EndDate: "2025-07-04", Name: "Beach", Added: true},
+		{Date: "2025-02-10", EndDate: "2025-02-10", Name: "Ski", Reason: "already a vacation: Ski"},
+	}
+	var buf bytes.Buffer
+	if err := WriteImportReport(results, "vacations", true, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"+ 2025-07-01 – 2025-07-04", "= 2025-02-10 ", "Would add 1 vacations; 1 already present."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	WriteImportReport(nil, "events", false, &buf)
+	if !strings.Contains(buf.String(), "No events found") {
+		t.Errorf("expected empty-import message, got %q", buf.String())
+	}
+}
@@ -3,14 +3,18 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"rto/data"
 )
 
-// RunInit initializes data files in the global data directory.
+// RunInit initializes data files in the global store.
 func RunInit() error {
-	return RunInitInDir(data.GetDataDir())
+	lock, err := acquireWriteLock()
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+	return RunInitInStore(data.GetStore())
 }
 
 // RunInitInDir initializes data files in the given directory (for testing).
@@ -19,64 +23,64 @@ func RunInitInDir(dir string) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("creating directory %s: %w", dir, err)
 	}
+	return RunInitInStore(data.NewDirStore(dir))
+}
 
+// RunInitInStore initializes data files in the given store.
+// Existing files are never overwritten.
+func RunInitInStore(st data.Store) error {
 	settings := data.DefaultAppSettings()
-	if !fileExists(dir, "settings.yaml") {
-		if err := settings.SaveTo(dir); err != nil {
+	if !st.Exists("settings.yaml") {
+		if err := settings.SaveToStore(st); err != nil {
 			return fmt.Errorf("writing settings.yaml: %w", err)
 		}
 	}
 
 	tpFile := settings.ActiveTimePeriodFile(0)
-	if !fileExists(dir, tpFile) {
+	if !st.Exists(tpFile) {
 		tpData := data.NewTimePeriodData()
 		for _, tp := range data.DefaultTimePeriods() {
 			tpData.Add(tp)
 		}
-		if err := tpData.SaveTo(dir); err != nil {
+		if err := tpData.SaveToStore(st); err != nil {
 			return fmt.Errorf("writing %s: %w", tpFile, err)
 		}
 	}
 
-	if !fileExists(dir, "badge_data.json") {
+	if !st.Exists("badge_data.json") {
 		badgeData := data.NewBadgeEntryData()
 		badgeData.Add(data.SampleBadgeEntry(settings.DefaultOffice))
-		if err := badgeData.SaveTo(dir); err != nil {
+		if err := badgeData.SaveToStore(st); err != nil {
 			return fmt.Errorf("writing badge_data.json: %w", err)
 		}
 	}
 
-	if !fileExists(dir, "holidays.yaml") {
+	if !st.Exists("holidays.yaml") {
 		holidayData := data.NewHolidayData()
 		for _, h := range data.DefaultHolidays() {
 			holidayData.Add(h)
 		}
-		if err := holidayData.SaveTo(dir); err != nil {
+		if err := holidayData.SaveToStore(st); err != nil {
 			return fmt.Errorf("writing holidays.yaml: %w", err)
 		}
 	}
 
-	if !fileExists(dir, "vacations.yaml") {
+	if !st.Exists("vacations.yaml") {
 		vacationData := data.NewVacationData()
 		vacationData.Add(data.SampleVacation())
-		if err := vacationData.SaveTo(dir); err != nil {
+		if err := vacationData.SaveToStore(st); err != nil {
 			return fmt.Errorf("writing vacations.yaml: %w", err)
 		}
 	}
 
-	if !fileExists(dir, "events.json") {
+	if !st.Exists("events.json") {
 		eventData := data.NewEventData()
 		eventData.Add(data.SampleEvent())
-		if err := eventData.SaveTo(dir); err != nil {
+		if err := eventData.SaveToStore(st); err != nil {
 			return fmt.Errorf("writing events.json: %w", err)
 		}
 	}
 
-	fmt.Printf("Initialized data files in: %s\n", dir)
+	fmt.Printf("Initialized data files in: %s\n", st)
 	return nil
 }
-
-func fileExists(dir, name string) bool {
-	_, err := os.Stat(filepath.Join(dir, name))
-	return err == nil
-}
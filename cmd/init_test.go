@@ -164,3 +164,27 @@ func TestSaveSettingsPreservesTimePeriods(t *testing.T) {
 		t.Errorf("expected Denver, CO, got %s", s.DefaultOffice)
 	}
 }
+
+func TestRunInitInSQLiteStore(t *testing.T) {
+	st, err := data.OpenSQLiteStore(filepath.Join(t.TempDir(), "rto.db"))
+	if err != nil {
+		t.Fatalf("open error: %v", err)
+	}
+	defer st.Close()
+
+	if err := RunInitInStore(st); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range []string{"settings.yaml", "workday-fiscal-quarters.yaml", "badge_data.json", "holidays.yaml", "vacations.yaml", "events.json"} {
+		if !st.Exists(f) {
+			t.Errorf("expected document %s to exist", f)
+		}
+	}
+	hd, err := data.LoadHolidayDataFromStore(st)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if hd.Len() == 0 {
+		t.Error("expected default holidays in store")
+	}
+}
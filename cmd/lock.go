@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"time"
+
+	"rto/data"
+)
+
+var lockWait time.Duration
+
+// SetLockWait sets how long commands wait for a data lock held by another
+// rto process before giving up (zero fails immediately).
+func SetLockWait(d time.Duration) {
+	lockWait = d
+}
+
+// acquireWriteLock takes the data lock on behalf of a CLI command that
+// modifies data. The TUI holds the same lock for its whole session.
+func acquireWriteLock() (*data.Lock, error) {
+	return data.AcquireStoreLock(data.GetStore(), "cli", lockWait)
+}
+
+// withWriteLock runs fn while holding the data lock, for commands that load,
+// modify and save data.
+func withWriteLock(fn func() error) error {
+	lock, err := acquireWriteLock()
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+	return fn()
+}
+
+// lockUnless runs fn under the data lock unless dryRun is set, for commands
+// whose dry run only reads data.
+func lockUnless(dryRun bool, fn func() error) error {
+	if dryRun {
+		return fn()
+	}
+	return withWriteLock(fn)
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"rto/data"
+)
+
+// maxDiffCells bounds the LCS table used for --dry-run diffs; larger
+// documents are shown as a full before/after replacement instead.
+const maxDiffCells = 4_000_000
+
+// RunMigrate upgrades every data file in the global store to the current
+// schema version. With dryRun, it prints a diff and writes nothing.
+func RunMigrate(dryRun bool) error {
+	return lockUnless(dryRun, func() error {
+		results, err := data.MigrateStore(data.GetStore(), dryRun)
+		if err != nil {
+			return fmt.Errorf("migrating: %w", err)
+		}
+		return WriteMigrationReport(results, dryRun, os.Stdout)
+	})
+}
+
+// WriteMigrationReport summarizes migration results, including a line diff
+// of each changed document when dryRun is set.
+func WriteMigrationReport(results []data.MigrationResult, dryRun bool, w io.Writer) error {
+	changed := 0
+	for _, r := range results {
+		if !r.Changed() {
+			fmt.Fprintf(w, "  %-32s  schema %d (up to date)\n", r.Name, r.FromVersion)
+			continue
+		}
+		changed++
+		verb := "migrated"
+		if dryRun {
+			verb = "would migrate"
+		}
+		fmt.Fprintf(w, "  %-32s  schema %d → %d (%s)\n", r.Name, r.FromVersion, r.ToVersion, verb)
+		if dryRun {
+			writeLineDiff(w, r.Name, string(r.Before), string(r.After))
+		}
+	}
+
+	fmt.Fprintln(w)
+	switch {
+	case changed == 0:
+		_, err := fmt.Fprintf(w, "All data files are at schema version %d.\n", data.CurrentSchemaVersion)
+		return err
+	case dryRun:
+		_, err := fmt.Fprintf(w, "%d file(s) need migrating. Run 'rto migrate' to apply.\n", changed)
+		return err
+	default:
+		_, err := fmt.Fprintf(w, "Migrated %d file(s) to schema version %d.\n", changed, data.CurrentSchemaVersion)
+		return err
+	}
+}
+
+// writeLineDiff writes a minimal unified-style diff (no hunk headers) of
+// before → after, showing only changed lines with one line of context.
+func writeLineDiff(w io.Writer, name, before, after string) {
+	a := splitLines(before)
+	b := splitLines(after)
+	fmt.Fprintf(w, "    --- %s\n    +++ %s (migrated)\n", name, name)
+
+	// Only the differing middle needs an LCS table; migrations usually touch
+	// a few lines near the top of otherwise identical files.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	var ops []diffOp
+	for _, l := range a[:prefix] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	if len(midA)*len(midB) > maxDiffCells {
+		for _, l := range midA {
+			ops = append(ops, diffOp{'-', l})
+		}
+		for _, l := range midB {
+			ops = append(ops, diffOp{'+', l})
+		}
+	} else {
+		ops = append(ops, diffLines(midA, midB)...)
+	}
+	for _, l := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', l})
+	}
+
+	const context = 1
+	for i, op := range ops {
+		if op.kind == ' ' {
+			near := false
+			for j := i - context; j <= i+context; j++ {
+				if j >= 0 && j < len(ops) && ops[j].kind != ' ' {
+					near = true
+					break
+				}
+			}
+			if !near {
+				continue
+			}
+		}
+		fmt.Fprintf(w, "    %c%s\n", op.kind, op.line)
+	}
+}
+
+type diffOp struct {
+	kind byte // ' ', '-', '+'
+	line string
+}
+
+// diffLines computes a line diff via longest common subsequence.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
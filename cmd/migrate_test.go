@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"rto/data"
+)
+
+func TestWriteMigrationReportUpToDate(t *testing.T) {
+	results := []data.MigrationResult{
+		{Name: "holidays.yaml", FromVersion: data.CurrentSchemaVersion, ToVersion: data.CurrentSchemaVersion},
+	}
+	var buf bytes.Buffer
+	if err := WriteMigrationReport(results, true, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "up to date") {
+		t.Errorf("expected up-to-date message, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteMigrationReportDryRunDiff(t *testing.T) {
+	results := []data.MigrationResult{{
+		Name:        "holidays.yaml",
+		FromVersion: 0,
+		ToVersion:   1,
+		Before:      []byte("holidays:\n- name: \"A\"\n  date: \"2025-01-01\"\n"),
+		After:       []byte("schema_version: 1\nholidays:\n- name: \"A\"\n  date: \"2025-01-01\"\n"),
+	}}
+	var buf bytes.Buffer
+	WriteMigrationReport(results, true, &buf)
+	out := buf.String()
+	if !strings.Contains(out, "+schema_version: 1") {
+		t.Errorf("expected added line in diff, got:\n%s", out)
+	}
+	if strings.Contains(out, "-holidays:") {
+		t.Errorf("unchanged lines should not be removed, got:\n%s", out)
+	}
+	if !strings.Contains(out, "would migrate") || !strings.Contains(out, "Run 'rto migrate'") {
+		t.Errorf("expected dry-run wording, got:\n%s", out)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	ops := diffLines([]string{"a", "b", "c"}, []string{"a", "x", "c"})
+	var got []string
+	for _, op := range ops {
+		got = append(got, string(op.kind)+op.line)
+	}
+	want := " a,-b,+x, c"
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected %q, got %q", want, strings.Join(got, ","))
+	}
+}
@@ -0,0 +1,719 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+	"rto/calc"
+	"rto/data"
+)
+
+// OutputFormat selects how read commands print their results.
+type OutputFormat string
+
+const (
+	OutputText OutputFormat = "text"
+	OutputJSON OutputFormat = "json"
+	OutputYAML OutputFormat = "yaml"
+	OutputCSV  OutputFormat = "csv"
+)
+
+// ParseOutputFormat validates a --output value. An empty string means text.
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch f := OutputFormat(s); f {
+	case "":
+		return OutputText, nil
+	case OutputText, OutputJSON, OutputYAML, OutputCSV:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown output format %q (expected text, json, yaml or csv)", s)
+}
+
+var outputFormat = OutputText
+
+// SetOutputFormat sets the format used by the read commands (stats,
+// vacations, holidays, events).
+func SetOutputFormat(f OutputFormat) {
+	outputFormat = f
+}
+
+// The types below are the machine-readable schema documented in the README.
+// Field names are part of rto's interface: add fields freely, but never
+// rename or remove one.
+
+// StatsOutput is the structured form of calc.PeriodStats.
+type StatsOutput struct {
+	Period                  string         `json:"period" yaml:"period"`
+	Name                    string         `json:"name" yaml:"name"`
+	StartDate               string         `json:"start_date" yaml:"start_date"`
+	EndDate                 string         `json:"end_date" yaml:"end_date"`
+	Policy                  string         `json:"policy" yaml:"policy"`
+	GoalPercent             int            `json:"goal_percent" yaml:"goal_percent"`
+	Status                  string         `json:"status" yaml:"status"`
+	DaysBadgedIn            int            `json:"days_badged_in" yaml:"days_badged_in"`
+	OfficeDays              int            `json:"office_days" yaml:"office_days"`
+	FlexDays                int            `json:"flex_days" yaml:"flex_days"`
+	FlexNotCounted          int            `json:"flex_not_counted" yaml:"flex_not_counted"`
+	PartialDays             int            `json:"partial_days" yaml:"partial_days"`
+	LeaveAttended           int            `json:"leave_attended" yaml:"leave_attended"`
+	FlexCap                 int            `json:"flex_cap" yaml:"flex_cap"`
+	FlexWeight              float64        `json:"flex_weight" yaml:"flex_weight"`
+	Credits                 float64        `json:"credits" yaml:"credits"`
+	DaysRequired            int            `json:"days_required" yaml:"days_required"`
+	DaysStillNeeded         int            `json:"days_still_needed" yaml:"days_still_needed"`
+	DaysAheadOfPace         int            `json:"days_ahead_of_pace" yaml:"days_ahead_of_pace"`
+	RemainingMissableDays   int            `json:"remaining_missable_days" yaml:"remaining_missable_days"`
+	DaysThusFar             int            `json:"days_thus_far" yaml:"days_thus_far"`
+	DaysLeft                int            `json:"days_left" yaml:"days_left"`
+	DaysOff                 int            `json:"days_off" yaml:"days_off"`
+	TotalDays               int            `json:"total_days" yaml:"total_days"`
+	AvailableWorkdays       int            `json:"available_workdays" yaml:"available_workdays"`
+	TotalCalendarDays       int            `json:"total_calendar_days" yaml:"total_calendar_days"`
+	Holidays                int            `json:"holidays" yaml:"holidays"`
+	VacationDays            int            `json:"vacation_days" yaml:"vacation_days"`
+	LeaveDays               map[string]int `json:"leave_days" yaml:"leave_days"`
+	CurrentAverage          float64        `json:"current_average" yaml:"current_average"`
+	RequiredFutureAverage   float64        `json:"required_future_average" yaml:"required_future_average"`
+	ProjectedCompletionDate *string        `json:"projected_completion_date" yaml:"projected_completion_date"`
+	Days                    []DayOutput    `json:"days" yaml:"days"`
+
+	// Committed is the period counting approved leave only; the fields
+	// above count pending leave too. The two match when none is pending.
+	PendingLeaveDays int             `json:"pending_leave_days" yaml:"pending_leave_days"`
+	Committed        CommittedOutput `json:"committed" yaml:"committed"`
+
+	// Planned office days don't count toward the fields above.
+	PlannedDays           int     `json:"planned_days" yaml:"planned_days"`
+	MissedPlans           int     `json:"missed_plans" yaml:"missed_plans"`
+	ProjectedCredits      float64 `json:"projected_credits" yaml:"projected_credits"`
+	PlannedCompletionDate *string `json:"planned_completion_date" yaml:"planned_completion_date"`
+
+	// Zero and empty unless settings.yaml has a weekly_minimum.
+	WeeklyMinimum int          `json:"weekly_minimum" yaml:"weekly_minimum"`
+	WeeksMet      int          `json:"weeks_met" yaml:"weeks_met"`
+	WeeksMissed   int          `json:"weeks_missed" yaml:"weeks_missed"`
+	Weeks         []WeekOutput `json:"weeks" yaml:"weeks"`
+}
+
+// CommittedOutput is the part of StatsOutput that changes if pending leave
+// is denied.
+type CommittedOutput struct {
+	Status                string `json:"status" yaml:"status"`
+	TotalDays             int    `json:"total_days" yaml:"total_days"`
+	VacationDays          int    `json:"vacation_days" yaml:"vacation_days"`
+	DaysRequired          int    `json:"days_required" yaml:"days_required"`
+	DaysStillNeeded       int    `json:"days_still_needed" yaml:"days_still_needed"`
+	DaysAheadOfPace       int    `json:"days_ahead_of_pace" yaml:"days_ahead_of_pace"`
+	RemainingMissableDays int    `json:"remaining_missable_days" yaml:"remaining_missable_days"`
+}
+
+func newCommittedOutput(stats *calc.PeriodStats) CommittedOutput {
+	if stats.Committed != nil {
+		stats = stats.Committed
+	}
+	return CommittedOutput{
+		Status:                stats.ComplianceStatus,
+		TotalDays:             stats.TotalDays,
+		VacationDays:          stats.VacationDays,
+		DaysRequired:          stats.DaysRequired,
+		DaysStillNeeded:       stats.DaysStillNeeded,
+		DaysAheadOfPace:       stats.DaysAheadOfPace,
+		RemainingMissableDays: stats.RemainingMissableDays,
+	}
+}
+
+// PlanOutput is the structured form of calc.Plan.
+type PlanOutput struct {
+	Period     string          `json:"period" yaml:"period"`
+	Needed     int             `json:"needed" yaml:"needed"`
+	Buffer     int             `json:"buffer" yaml:"buffer"`
+	Short      int             `json:"short" yaml:"short"`
+	WeeksShort int             `json:"weeks_short" yaml:"weeks_short"`
+	Applied    bool            `json:"applied" yaml:"applied"`
+	Days       []PlanDayOutput `json:"days" yaml:"days"`
+}
+
+// PlanDayOutput is one proposed office day.
+type PlanDayOutput struct {
+	Date     string `json:"date" yaml:"date"`
+	Weekday  string `json:"weekday" yaml:"weekday"`
+	HasEvent bool   `json:"has_event" yaml:"has_event"`
+}
+
+// WeekOutput is the structured form of one calc.WeekStats. Start and end
+// are clipped to the period.
+type WeekOutput struct {
+	Year          int     `json:"year" yaml:"year"`
+	Week          int     `json:"week" yaml:"week"`
+	StartDate     string  `json:"start_date" yaml:"start_date"`
+	EndDate       string  `json:"end_date" yaml:"end_date"`
+	WorkingDays   int     `json:"working_days" yaml:"working_days"`
+	AvailableDays int     `json:"available_days" yaml:"available_days"`
+	Required      int     `json:"required" yaml:"required"`
+	BadgedIn      float64 `json:"badged_in" yaml:"badged_in"`
+	Status        string  `json:"status" yaml:"status"`
+}
+
+// DayOutput is the structured form of one calc.Workday.
+type DayOutput struct {
+	Date         string  `json:"date" yaml:"date"`
+	Weekday      string  `json:"weekday" yaml:"weekday"`
+	IsWorkday    bool    `json:"is_workday" yaml:"is_workday"`
+	IsBadgedIn   bool    `json:"is_badged_in" yaml:"is_badged_in"`
+	IsFlexCredit bool    `json:"is_flex_credit" yaml:"is_flex_credit"`
+	IsHoliday    bool    `json:"is_holiday" yaml:"is_holiday"`
+	IsVacation   bool    `json:"is_vacation" yaml:"is_vacation"`
+	IsPartial    bool    `json:"is_partial" yaml:"is_partial"`
+	Leave        string  `json:"leave" yaml:"leave"`
+	Credit       float64 `json:"credit" yaml:"credit"`
+	IsPlanned    bool    `json:"is_planned" yaml:"is_planned"`
+	IsMissedPlan bool    `json:"is_missed_plan" yaml:"is_missed_plan"`
+}
+
+// RollingOutput is the structured form of calc.RollingStats: the window
+// ending today, and one entry per day of the period.
+type RollingOutput struct {
+	Period           string `json:"period" yaml:"period"`
+	WindowDays       int    `json:"window_days" yaml:"window_days"`
+	GoalPercent      int    `json:"goal_percent" yaml:"goal_percent"`
+	RollingDayOutput `yaml:",inline"`
+	Days             []RollingDayOutput `json:"days" yaml:"days"`
+}
+
+// RollingDayOutput is the structured form of one calc.RollingDay.
+type RollingDayOutput struct {
+	Date          string  `json:"date" yaml:"date"`
+	WindowStart   string  `json:"window_start" yaml:"window_start"`
+	AvailableDays int     `json:"available_days" yaml:"available_days"`
+	Required      int     `json:"required" yaml:"required"`
+	BadgedIn      float64 `json:"badged_in" yaml:"badged_in"`
+	Rate          float64 `json:"rate" yaml:"rate"`
+	Compliant     bool    `json:"compliant" yaml:"compliant"`
+	BelowGoalOn   *string `json:"below_goal_on" yaml:"below_goal_on"`
+}
+
+// HistoryOutput is the structured form of a calc.CalculateHistory result.
+type HistoryOutput struct {
+	File    string                `json:"file" yaml:"file"`
+	Periods []HistoryPeriodOutput `json:"periods" yaml:"periods"`
+}
+
+// HistoryPeriodOutput is the structured form of one calc.HistoryEntry.
+type HistoryPeriodOutput struct {
+	Period       string  `json:"period" yaml:"period"`
+	Name         string  `json:"name" yaml:"name"`
+	StartDate    string  `json:"start_date" yaml:"start_date"`
+	EndDate      string  `json:"end_date" yaml:"end_date"`
+	DaysRequired int     `json:"days_required" yaml:"days_required"`
+	DaysBadgedIn int     `json:"days_badged_in" yaml:"days_badged_in"`
+	FlexDays     int     `json:"flex_days" yaml:"flex_days"`
+	Credits      float64 `json:"credits" yaml:"credits"`
+	Rate         float64 `json:"rate" yaml:"rate"`
+	Margin       float64 `json:"margin" yaml:"margin"`
+	Status       string  `json:"status" yaml:"status"`
+	Complete     bool    `json:"complete" yaml:"complete"`
+}
+
+// AnalyticsOutput is the structured form of calc.Analytics. Period is empty
+// for a date range.
+type AnalyticsOutput struct {
+	Period         string          `json:"period" yaml:"period"`
+	StartDate      string          `json:"start_date" yaml:"start_date"`
+	EndDate        string          `json:"end_date" yaml:"end_date"`
+	Weekdays       []WeekdayOutput `json:"weekdays" yaml:"weekdays"`
+	LongestStreak  StreakOutput    `json:"longest_streak" yaml:"longest_streak"`
+	CurrentStreak  StreakOutput    `json:"current_streak" yaml:"current_streak"`
+	AverageArrival *string         `json:"average_arrival" yaml:"average_arrival"` // HH:MM
+	TimedBadgeIns  int             `json:"timed_badge_ins" yaml:"timed_badge_ins"`
+	Offices        []OfficeOutput  `json:"offices" yaml:"offices"`
+}
+
+// WeekdayOutput is the structured form of one calc.WeekdayStats.
+type WeekdayOutput struct {
+	Weekday       string  `json:"weekday" yaml:"weekday"`
+	AvailableDays int     `json:"available_days" yaml:"available_days"`
+	OfficeDays    int     `json:"office_days" yaml:"office_days"`
+	FlexDays      int     `json:"flex_days" yaml:"flex_days"`
+	Rate          float64 `json:"rate" yaml:"rate"`
+}
+
+// StreakOutput is the structured form of a calc.Streak; the dates are null
+// without one.
+type StreakOutput struct {
+	Days      int     `json:"days" yaml:"days"`
+	StartDate *string `json:"start_date" yaml:"start_date"`
+	EndDate   *string `json:"end_date" yaml:"end_date"`
+}
+
+// OfficeOutput is the structured form of a calc.OfficeCount.
+type OfficeOutput struct {
+	Office string `json:"office" yaml:"office"`
+	Days   int    `json:"days" yaml:"days"`
+}
+
+// VacationOutput is one vacation; Number is the SELECTOR # used by edit/rm.
+type VacationOutput struct {
+	Number      int    `json:"number" yaml:"number"`
+	Destination string `json:"destination" yaml:"destination"`
+	StartDate   string `json:"start_date" yaml:"start_date"`
+	EndDate     string `json:"end_date" yaml:"end_date"`
+	Approved    bool   `json:"approved" yaml:"approved"`
+	Type        string `json:"type" yaml:"type"`
+}
+
+// HolidayOutput is one holiday; Number is the SELECTOR # used by edit/rm.
+type HolidayOutput struct {
+	Number int    `json:"number" yaml:"number"`
+	Date   string `json:"date" yaml:"date"`
+	Name   string `json:"name" yaml:"name"`
+}
+
+// EventOutput is one event; Number is the SELECTOR # used by edit/rm.
+type EventOutput struct {
+	Number      int    `json:"number" yaml:"number"`
+	Date        string `json:"date" yaml:"date"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// NewStatsOutput converts stats to the output schema. Days are sorted by date.
+func NewStatsOutput(stats *calc.PeriodStats) StatsOutput {
+	out := StatsOutput{
+		Period:                stats.Key,
+		Name:                  stats.Name,
+		StartDate:             stats.StartDate.Format(data.BadgeDateFormat),
+		EndDate:               stats.EndDate.Format(data.BadgeDateFormat),
+		Policy:                stats.Policy,
+		GoalPercent:           stats.GoalPct,
+		Status:                stats.ComplianceStatus,
+		DaysBadgedIn:          stats.DaysBadgedIn,
+		OfficeDays:            stats.DaysBadgedIn - stats.FlexDays - stats.LeaveAttended,
+		FlexDays:              stats.FlexDays,
+		FlexNotCounted:        stats.FlexNotCounted,
+		PartialDays:           stats.PartialDays,
+		LeaveAttended:         stats.LeaveAttended,
+		FlexCap:               stats.FlexCap,
+		FlexWeight:            stats.FlexWeight,
+		Credits:               stats.Credits,
+		DaysRequired:          stats.DaysRequired,
+		DaysStillNeeded:       stats.DaysStillNeeded,
+		DaysAheadOfPace:       stats.DaysAheadOfPace,
+		RemainingMissableDays: stats.RemainingMissableDays,
+		DaysThusFar:           stats.DaysThusFar,
+		DaysLeft:              stats.DaysLeft,
+		DaysOff:               stats.DaysOff,
+		TotalDays:             stats.TotalDays,
+		AvailableWorkdays:     stats.AvailableWorkdays,
+		TotalCalendarDays:     stats.TotalCalendarDays,
+		Holidays:              stats.Holidays,
+		VacationDays:          stats.VacationDays,
+		LeaveDays:             map[string]int{},
+		CurrentAverage:        stats.CurrentAverage,
+		RequiredFutureAverage: stats.RequiredFutureAverage,
+		Days:                  []DayOutput{},
+		PendingLeaveDays:      stats.PendingLeaveDays,
+		Committed:             newCommittedOutput(stats),
+		PlannedDays:           stats.PlannedDays,
+		MissedPlans:           stats.MissedPlans,
+		ProjectedCredits:      stats.ProjectedCredits,
+	}
+	if stats.ProjectedCompletionDate != nil {
+		d := stats.ProjectedCompletionDate.Format(data.BadgeDateFormat)
+		out.ProjectedCompletionDate = &d
+	}
+	if stats.PlannedCompletionDate != nil {
+		d := stats.PlannedCompletionDate.Format(data.BadgeDateFormat)
+		out.PlannedCompletionDate = &d
+	}
+	for t, n := range stats.LeaveDays {
+		out.LeaveDays[string(t)] = n
+	}
+	for _, wd := range stats.WorkdayStats {
+		out.Days = append(out.Days, DayOutput{
+			Date:         wd.WorkDate,
+			Weekday:      wd.Date.Weekday().String(),
+			IsWorkday:    wd.IsWorkday,
+			IsBadgedIn:   wd.IsBadgedIn,
+			IsFlexCredit: wd.IsFlexCredit,
+			IsHoliday:    wd.IsHoliday,
+			IsVacation:   wd.IsVacation,
+			IsPartial:    wd.IsPartial,
+			Leave:        string(wd.Leave),
+			Credit:       wd.Credit,
+			IsPlanned:    wd.IsPlanned,
+			IsMissedPlan: wd.IsMissedPlan,
+		})
+	}
+	sort.Slice(out.Days, func(i, j int) bool { return out.Days[i].Date < out.Days[j].Date })
+	out.WeeklyMinimum = stats.WeeklyMinimum
+	out.WeeksMet = stats.WeeksMet
+	out.WeeksMissed = stats.WeeksMissed
+	out.Weeks = []WeekOutput{}
+	for _, w := range stats.Weeks {
+		out.Weeks = append(out.Weeks, WeekOutput{
+			Year:          w.Year,
+			Week:          w.Week,
+			StartDate:     w.Start.Format(data.BadgeDateFormat),
+			EndDate:       w.End.Format(data.BadgeDateFormat),
+			WorkingDays:   w.WorkingDays,
+			AvailableDays: w.AvailableDays,
+			Required:      w.Required,
+			BadgedIn:      w.BadgedIn,
+			Status:        string(w.Status),
+		})
+	}
+	return out
+}
+
+// WriteStatsOutput writes stats in the given format. For CSV, the summary is
+// written as a single row, or with days set, one row per day.
+func WriteStatsOutput(stats *calc.PeriodStats, format OutputFormat, days bool, w io.Writer) error {
+	if format == OutputText {
+		return WriteStats(stats, w)
+	}
+	out := NewStatsOutput(stats)
+	if format != OutputCSV {
+		return encodeOutput(w, format, out)
+	}
+
+	if days {
+		rows := make([][]string, len(out.Days))
+		for i, d := range out.Days {
+			rows[i] = []string{out.Period, d.Date, d.Weekday, fmtBool(d.IsWorkday), fmtBool(d.IsBadgedIn),
+				fmtBool(d.IsFlexCredit), fmtBool(d.IsHoliday), fmtBool(d.IsVacation), fmtBool(d.IsPartial), fmtFloat(d.Credit), d.Leave,
+				fmtBool(d.IsPlanned), fmtBool(d.IsMissedPlan)}
+		}
+		return writeCSV(w, []string{"period", "date", "weekday", "is_workday", "is_badged_in",
+			"is_flex_credit", "is_holiday", "is_vacation", "is_partial", "credit", "leave",
+			"is_planned", "is_missed_plan"}, rows)
+	}
+
+	projected, planned := "", ""
+	if out.ProjectedCompletionDate != nil {
+		projected = *out.ProjectedCompletionDate
+	}
+	if out.PlannedCompletionDate != nil {
+		planned = *out.PlannedCompletionDate
+	}
+	header := append([]string{}, statsCSVColumns...)
+	row := []string{out.Period, out.Name, out.StartDate, out.EndDate, strconv.Itoa(out.GoalPercent), out.Status,
+		strconv.Itoa(out.DaysBadgedIn), strconv.Itoa(out.OfficeDays), strconv.Itoa(out.FlexDays),
+		strconv.Itoa(out.DaysRequired), strconv.Itoa(out.DaysStillNeeded), strconv.Itoa(out.DaysAheadOfPace),
+		strconv.Itoa(out.RemainingMissableDays), strconv.Itoa(out.DaysThusFar), strconv.Itoa(out.DaysLeft),
+		strconv.Itoa(out.DaysOff), strconv.Itoa(out.TotalDays), strconv.Itoa(out.AvailableWorkdays),
+		strconv.Itoa(out.TotalCalendarDays), strconv.Itoa(out.Holidays), strconv.Itoa(out.VacationDays),
+		fmtFloat(out.CurrentAverage), fmtFloat(out.RequiredFutureAverage), projected, out.Policy,
+		strconv.Itoa(out.FlexNotCounted), strconv.Itoa(out.FlexCap), fmtFloat(out.FlexWeight), fmtFloat(out.Credits),
+		strconv.Itoa(out.PartialDays), strconv.Itoa(out.LeaveAttended),
+		strconv.Itoa(out.PendingLeaveDays), out.Committed.Status, strconv.Itoa(out.Committed.TotalDays),
+		strconv.Itoa(out.Committed.VacationDays), strconv.Itoa(out.Committed.DaysRequired),
+		strconv.Itoa(out.Committed.DaysStillNeeded), strconv.Itoa(out.Committed.DaysAheadOfPace),
+		strconv.Itoa(out.Committed.RemainingMissableDays), strconv.Itoa(out.PlannedDays),
+		strconv.Itoa(out.MissedPlans), fmtFloat(out.ProjectedCredits), planned}
+	// One column per leave type, after every fixed column.
+	for _, t := range data.LeaveTypes {
+		header = append(header, "leave_"+string(t))
+		row = append(row, strconv.Itoa(out.LeaveDays[string(t)]))
+	}
+	return writeCSV(w, header, [][]string{row})
+}
+
+// statsCSVColumns are the fixed columns of the stats summary CSV. Existing
+// columns keep their positions: a new one goes at the end of this list, and
+// the leave_<type> columns always follow it.
+var statsCSVColumns = []string{"period", "name", "start_date", "end_date", "goal_percent", "status",
+	"days_badged_in", "office_days", "flex_days", "days_required", "days_still_needed",
+	"days_ahead_of_pace", "remaining_missable_days", "days_thus_far", "days_left", "days_off",
+	"total_days", "available_workdays", "total_calendar_days", "holidays", "vacation_days",
+	"current_average", "required_future_average", "projected_completion_date", "policy", "flex_not_counted",
+	"flex_cap", "flex_weight", "credits", "partial_days", "leave_attended",
+	"pending_leave_days", "committed_status", "committed_total_days", "committed_vacation_days",
+	"committed_days_required", "committed_days_still_needed", "committed_days_ahead_of_pace",
+	"committed_remaining_missable_days", "planned_days", "missed_plans", "projected_credits",
+	"planned_completion_date"}
+
+// NewRollingOutput converts stats for period to the output schema.
+func NewRollingOutput(stats *calc.RollingStats, period string) RollingOutput {
+	out := RollingOutput{
+		Period:           period,
+		WindowDays:       stats.WindowDays,
+		GoalPercent:      stats.GoalPct,
+		RollingDayOutput: newRollingDayOutput(stats.Today),
+		Days:             []RollingDayOutput{},
+	}
+	for _, d := range stats.Days {
+		out.Days = append(out.Days, newRollingDayOutput(d))
+	}
+	return out
+}
+
+func newRollingDayOutput(d calc.RollingDay) RollingDayOutput {
+	out := RollingDayOutput{
+		Date:          d.Date.Format(data.BadgeDateFormat),
+		WindowStart:   d.WindowStart.Format(data.BadgeDateFormat),
+		AvailableDays: d.Available,
+		Required:      d.Required,
+		BadgedIn:      d.BadgedIn,
+		Rate:          d.Rate,
+		Compliant:     d.Compliant(),
+	}
+	if !d.BelowGoalOn.IsZero() {
+		s := d.BelowGoalOn.Format(data.BadgeDateFormat)
+		out.BelowGoalOn = &s
+	}
+	return out
+}
+
+// WriteRollingOutput writes rolling stats in the given format. For CSV, the
+// window ending today is written as a single row, or with days set, one row
+// per day of the period.
+func WriteRollingOutput(stats *calc.RollingStats, period string, format OutputFormat, days bool, w io.Writer) error {
+	if format == OutputText {
+		return WriteRolling(stats, w)
+	}
+	out := NewRollingOutput(stats, period)
+	if format != OutputCSV {
+		return encodeOutput(w, format, out)
+	}
+
+	rowOf := func(d RollingDayOutput) []string {
+		below := ""
+		if d.BelowGoalOn != nil {
+			below = *d.BelowGoalOn
+		}
+		return []string{out.Period, strconv.Itoa(out.WindowDays), strconv.Itoa(out.GoalPercent), d.Date, d.WindowStart,
+			strconv.Itoa(d.AvailableDays), strconv.Itoa(d.Required), formatDays(d.BadgedIn),
+			fmtFloat(d.Rate), fmtBool(d.Compliant), below}
+	}
+	rows := [][]string{rowOf(out.RollingDayOutput)}
+	if days {
+		rows = rows[:0]
+		for _, d := range out.Days {
+			rows = append(rows, rowOf(d))
+		}
+	}
+	return writeCSV(w, []string{"period", "window_days", "goal_percent", "date", "window_start", "available_days",
+		"required", "badged_in", "rate", "compliant", "below_goal_on"}, rows)
+}
+
+// WritePlanOutput writes plan in the given format. CSV has one row per
+// proposed day.
+func WritePlanOutput(plan *calc.Plan, stats *calc.PeriodStats, opts calc.PlanOptions, applied bool,
+	format OutputFormat, w io.Writer) error {
+	if format == OutputText {
+		return WritePlan(plan, stats, opts, applied, w)
+	}
+	out := PlanOutput{Period: stats.Key, Needed: plan.Needed, Buffer: opts.Buffer, Short: plan.Short,
+		WeeksShort: plan.WeeksShort, Applied: applied, Days: []PlanDayOutput{}}
+	for _, d := range plan.Days {
+		key := d.Format(data.BadgeDateFormat)
+		out.Days = append(out.Days, PlanDayOutput{Date: key, Weekday: d.Weekday().String(), HasEvent: opts.Avoid[key]})
+	}
+	if format != OutputCSV {
+		return encodeOutput(w, format, out)
+	}
+	rows := make([][]string, len(out.Days))
+	for i, d := range out.Days {
+		rows[i] = []string{out.Period, d.Date, d.Weekday, fmtBool(d.HasEvent), strconv.Itoa(out.Needed)}
+	}
+	return writeCSV(w, []string{"period", "date", "weekday", "has_event", "needed"}, rows)
+}
+
+// WriteHistoryOutput writes history, read from file, in the given format. CSV
+// has one row per period.
+func WriteHistoryOutput(history []calc.HistoryEntry, file string, format OutputFormat, w io.Writer) error {
+	if format == OutputText {
+		return WriteHistory(history, w)
+	}
+	out := HistoryOutput{File: file, Periods: []HistoryPeriodOutput{}}
+	for _, h := range history {
+		out.Periods = append(out.Periods, HistoryPeriodOutput{
+			Period:       h.Key,
+			Name:         h.Name,
+			StartDate:    h.StartDate.Format(data.BadgeDateFormat),
+			EndDate:      h.EndDate.Format(data.BadgeDateFormat),
+			DaysRequired: h.DaysRequired,
+			DaysBadgedIn: h.DaysBadgedIn,
+			FlexDays:     h.FlexDays,
+			Credits:      h.Credits,
+			Rate:         h.Rate,
+			Margin:       h.Margin,
+			Status:       h.ComplianceStatus,
+			Complete:     h.Complete,
+		})
+	}
+	if format != OutputCSV {
+		return encodeOutput(w, format, out)
+	}
+	rows := make([][]string, len(out.Periods))
+	for i, p := range out.Periods {
+		rows[i] = []string{p.Period, p.Name, p.StartDate, p.EndDate, strconv.Itoa(p.DaysRequired), strconv.Itoa(p.DaysBadgedIn),
+			strconv.Itoa(p.FlexDays), fmtFloat(p.Credits), fmtFloat(p.Rate), fmtFloat(p.Margin), p.Status, fmtBool(p.Complete)}
+	}
+	return writeCSV(w, []string{"period", "name", "start_date", "end_date", "days_required", "days_badged_in",
+		"flex_days", "credits", "rate", "margin", "status", "complete"}, rows)
+}
+
+// NewAnalyticsOutput converts a, for period, to the output schema.
+func NewAnalyticsOutput(a *calc.Analytics, period string) AnalyticsOutput {
+	out := AnalyticsOutput{
+		Period:        period,
+		StartDate:     a.Start.Format(data.BadgeDateFormat),
+		EndDate:       a.End.Format(data.BadgeDateFormat),
+		Weekdays:      []WeekdayOutput{},
+		LongestStreak: newStreakOutput(a.LongestStreak),
+		CurrentStreak: newStreakOutput(a.CurrentStreak),
+		TimedBadgeIns: a.TimedBadgeIns,
+		Offices:       []OfficeOutput{},
+	}
+	for _, ws := range a.Weekdays {
+		out.Weekdays = append(out.Weekdays, WeekdayOutput{
+			Weekday:       ws.Weekday.String(),
+			AvailableDays: ws.Available,
+			OfficeDays:    ws.Office,
+			FlexDays:      ws.Flex,
+			Rate:          ws.Rate,
+		})
+	}
+	if a.TimedBadgeIns > 0 {
+		s := calc.FormatTimeOfDay(a.AverageArrival)
+		out.AverageArrival = &s
+	}
+	for _, o := range a.Offices {
+		out.Offices = append(out.Offices, OfficeOutput{Office: o.Office, Days: o.Days})
+	}
+	return out
+}
+
+func newStreakOutput(s calc.Streak) StreakOutput {
+	out := StreakOutput{Days: s.Days}
+	if s.Days > 0 {
+		start, end := s.Start.Format(data.BadgeDateFormat), s.End.Format(data.BadgeDateFormat)
+		out.StartDate, out.EndDate = &start, &end
+	}
+	return out
+}
+
+// WriteAnalyticsOutput writes a, for period, in the given format. CSV has one
+// row per weekday; the streaks, arrival time and offices are left out.
+func WriteAnalyticsOutput(a *calc.Analytics, period string, format OutputFormat, w io.Writer) error {
+	if format == OutputText {
+		return WriteAnalytics(a, period, w)
+	}
+	out := NewAnalyticsOutput(a, period)
+	if format != OutputCSV {
+		return encodeOutput(w, format, out)
+	}
+	rows := make([][]string, len(out.Weekdays))
+	for i, wd := range out.Weekdays {
+		rows[i] = []string{out.Period, out.StartDate, out.EndDate, wd.Weekday, strconv.Itoa(wd.AvailableDays),
+			strconv.Itoa(wd.OfficeDays), strconv.Itoa(wd.FlexDays), fmtFloat(wd.Rate)}
+	}
+	return writeCSV(w, []string{"period", "start_date", "end_date", "weekday", "available_days",
+		"office_days", "flex_days", "rate"}, rows)
+}
+
+// WriteVacationsOutput writes vacations in the given format.
+func WriteVacationsOutput(vd *data.VacationData, format OutputFormat, w io.Writer) error {
+	if format == OutputText {
+		return WriteVacations(vd, w)
+	}
+	out := []VacationOutput{}
+	for i, v := range vd.All() {
+		out = append(out, VacationOutput{Number: i + 1, Destination: v.Destination, StartDate: v.StartDate, EndDate: v.EndDate,
+			Approved: v.Approved, Type: string(v.Leave())})
+	}
+	if format != OutputCSV {
+		return encodeOutput(w, format, struct {
+			Vacations []VacationOutput `json:"vacations" yaml:"vacations"`
+		}{out})
+	}
+	rows := make([][]string, len(out))
+	for i, v := range out {
+		rows[i] = []string{strconv.Itoa(v.Number), v.Destination, v.StartDate, v.EndDate, fmtBool(v.Approved), v.Type}
+	}
+	return writeCSV(w, []string{"number", "destination", "start_date", "end_date", "approved", "type"}, rows)
+}
+
+// WriteHolidaysOutput writes holidays in the given format.
+func WriteHolidaysOutput(hd *data.HolidayData, format OutputFormat, w io.Writer) error {
+	if format == OutputText {
+		return WriteHolidays(hd, w)
+	}
+	out := []HolidayOutput{}
+	for i, h := range hd.All() {
+		out = append(out, HolidayOutput{Number: i + 1, Date: h.Date, Name: h.Name})
+	}
+	if format != OutputCSV {
+		return encodeOutput(w, format, struct {
+			Holidays []HolidayOutput `json:"holidays" yaml:"holidays"`
+		}{out})
+	}
+	rows := make([][]string, len(out))
+	for i, h := range out {
+		rows[i] = []string{strconv.Itoa(h.Number), h.Date, h.Name}
+	}
+	return writeCSV(w, []string{"number", "date", "name"}, rows)
+}
+
+// WriteEventsOutput writes events in the given format.
+func WriteEventsOutput(ed *data.EventData, format OutputFormat, w io.Writer) error {
+	if format == OutputText {
+		return WriteEvents(ed, w)
+	}
+	out := []EventOutput{}
+	for i, ev := range ed.All() {
+		out = append(out, EventOutput{Number: i + 1, Date: ev.Date, Description: ev.Description})
+	}
+	if format != OutputCSV {
+		return encodeOutput(w, format, struct {
+			Events []EventOutput `json:"events" yaml:"events"`
+		}{out})
+	}
+	rows := make([][]string, len(out))
+	for i, ev := range out {
+		rows[i] = []string{strconv.Itoa(ev.Number), ev.Date, ev.Description}
+	}
+	return writeCSV(w, []string{"number", "date", "description"}, rows)
+}
+
+// encodeOutput writes v as indented JSON or YAML.
+func encodeOutput(w io.Writer, format OutputFormat, v interface{}) error {
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case OutputYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("output format %q is not supported here", format)
+}
+
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func fmtBool(b bool) string {
+	return strconv.FormatBool(b)
+}
+
+func fmtFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
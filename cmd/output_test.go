@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+	"rto/data"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	for _, s := range []string{"", "text", "json", "yaml", "csv"} {
+		if _, err := ParseOutputFormat(s); err != nil {
+			t.Errorf("%q: unexpected error %v", s, err)
+		}
+	}
+	if _, err := ParseOutputFormat("xml"); err == nil {
+		t.Error("expected error for xml")
+	}
+}
+
+func TestWriteStatsOutputJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteStatsOutput(makeTestStats(), OutputJSON, false, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if got["period"] != "Q1_2025" || got["goal_percent"] != 50.0 || got["days_badged_in"] != 3.0 || got["flex_days"] != 1.0 {
+		t.Errorf("unexpected summary fields: %v", got)
+	}
+	days, ok := got["days"].([]interface{})
+	if !ok || len(days) == 0 {
+		t.Fatalf("expected per-day entries, got %v", got["days"])
+	}
+	first := days[0].(map[string]interface{})
+	if first["date"] != "2025-01-01" || first["is_holiday"] != true {
+		t.Errorf("expected days sorted from the New Year holiday, got %v", first)
+	}
+}
+
+func TestWriteStatsOutputYAMLMatchesJSON(t *testing.T) {
+	stats := makeTestStats()
+	var jbuf, ybuf bytes.Buffer
+	WriteStatsOutput(stats, OutputJSON, false, &jbuf)
+	WriteStatsOutput(stats, OutputYAML, false, &ybuf)
+
+	var fromJSON, fromYAML StatsOutput
+	if err := json.Unmarshal(jbuf.Bytes(), &fromJSON); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if err := yaml.Unmarshal(ybuf.Bytes(), &fromYAML); err != nil {
+		t.Fatalf("yaml: %v", err)
+	}
+	if fromJSON.Period != fromYAML.Period || len(fromJSON.Days) != len(fromYAML.Days) || fromJSON.DaysRequired != fromYAML.DaysRequired {
+		t.Errorf("JSON and YAML disagree:\n%+v\n%+v", fromJSON, fromYAML)
+	}
+}
+
+func TestWriteStatsOutputCSV(t *testing.T) {
+	stats := makeTestStats()
+	var buf bytes.Buffer
+	WriteStatsOutput(stats, OutputCSV, false, &buf)
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(records) != 2 || records[0][0] != "period" || records[1][0] != "Q1_2025" {
+		t.Errorf("expected header + one summary row, got %v", records)
+	}
+
+	buf.Reset()
+	WriteStatsOutput(stats, OutputCSV, true, &buf)
+	records, _ = csv.NewReader(&buf).ReadAll()
+	if len(records) != len(stats.WorkdayStats)+1 || records[0][1] != "date" {
+		t.Errorf("expected header + one row per day, got %d rows", len(records))
+	}
+}
+
+func TestWriteStatsOutputCSVHeader(t *testing.T) {
+	var buf bytes.Buffer
+	WriteStatsOutput(makeTestStats(), OutputCSV, false, &buf)
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	header := records[0]
+	if len(header) != len(records[1]) {
+		t.Fatalf("header has %d columns, row has %d", len(header), len(records[1]))
+	}
+
+	// The columns of the original schema keep their positions.
+	first := []string{"period", "name", "start_date", "end_date", "goal_percent", "status",
+		"days_badged_in", "office_days", "flex_days", "days_required", "days_still_needed",
+		"days_ahead_of_pace", "remaining_missable_days", "days_thus_far", "days_left", "days_off",
+		"total_days", "available_workdays", "total_calendar_days", "holidays", "vacation_days",
+		"current_average", "required_future_average", "projected_completion_date"}
+	for i, name := range first {
+		if header[i] != name {
+			t.Errorf("column %d: got %q, want %q", i, header[i], name)
+		}
+	}
+
+	// Every fixed column comes before the leave_<type> columns, which are last.
+	fixed := len(header) - len(data.LeaveTypes)
+	for i, name := range header {
+		isLeaveType := strings.HasPrefix(name, "leave_") && name != "leave_attended"
+		if isLeaveType != (i >= fixed) {
+			t.Errorf("column %d (%s) is out of place", i, name)
+		}
+	}
+	for i, lt := range data.LeaveTypes {
+		if header[fixed+i] != "leave_"+string(lt) {
+			t.Errorf("column %d: got %q, want leave_%s", fixed+i, header[fixed+i], lt)
+		}
+	}
+}
+
+func TestWriteListOutputs(t *testing.T) {
+	vd := data.NewVacationData()
+	vd.Add(data.Vacation{Destination: "Paris, France", StartDate: "2025-07-14", EndDate: "2025-07-18", Approved: true})
+	var buf bytes.Buffer
+	WriteVacationsOutput(vd, OutputCSV, &buf)
+	if !strings.Contains(buf.String(), `1,"Paris, France",2025-07-14,2025-07-18,true`) {
+		t.Errorf("unexpected vacations CSV:\n%s", buf.String())
+	}
+
+	hd := data.NewHolidayData()
+	buf.Reset()
+	WriteHolidaysOutput(hd, OutputJSON, &buf)
+	if strings.TrimSpace(buf.String()) != "{\n  \"holidays\": []\n}" {
+		t.Errorf("empty list should encode as [], got:\n%s", buf.String())
+	}
+
+	ed := data.NewEventData()
+	ed.Add(data.Event{Date: "2025-03-12", Description: "Offsite"})
+	buf.Reset()
+	WriteEventsOutput(ed, OutputYAML, &buf)
+	if !strings.Contains(buf.String(), "events:\n  - number: 1\n    date: \"2025-03-12\"\n    description: Offsite\n") {
+		t.Errorf("unexpected events YAML:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"rto/data"
+)
+
+// PeriodsGenerateOptions configures RunPeriodsGenerate.
+type PeriodsGenerateOptions struct {
+	Spec     data.PeriodSpec
+	File     string // time period file to write; "" for PeriodsFilename(Spec)
+	Register bool   // add File to time_periods in settings.yaml
+	DryRun   bool
+}
+
+// RunPeriodsGenerate generates the periods of opts.Spec and merges them into
+// a time period file, creating it if needed. Periods whose key is already in
+// the file, or whose dates overlap one of its periods, are skipped.
+func RunPeriodsGenerate(opts PeriodsGenerateOptions) error {
+	periods, err := data.GeneratePeriods(opts.Spec)
+	if err != nil {
+		return err
+	}
+	file := opts.File
+	if file == "" {
+		file = PeriodsFilename(opts.Spec)
+	}
+
+	return lockUnless(opts.DryRun, func() error {
+		st := data.GetStore()
+		td := data.NewTimePeriodDataWithFile(file)
+		td.SetCalendarDisplayColumns(data.CalendarColumnsFor(periods))
+		if st.Exists(file) {
+			if td, err = data.LoadTimePeriodDataFromStore(st, file); err != nil {
+				return fmt.Errorf("loading %s: %w", file, err)
+			}
+		}
+		results := MergePeriods(td, periods)
+		if !opts.DryRun && countAdded(results) > 0 {
+			if err := td.Save(); err != nil {
+				return fmt.Errorf("saving %s: %w", file, err)
+			}
+		}
+		fmt.Printf("%s:\n", file)
+		if err := WriteImportReport(results, "time periods", opts.DryRun, os.Stdout); err != nil {
+			return err
+		}
+		if opts.Register {
+			return registerTimePeriodFile(file, opts.DryRun)
+		}
+		return nil
+	})
+}
+
+// RunPeriodsRollover extends each time period file in settings.yaml whose
+// last period today is past, following the calendar the file already uses.
+// Without yes, it only shows the periods it would add.
+func RunPeriodsRollover(yes bool) error {
+	return lockUnless(!yes, func() error {
+		settings, err := data.LoadAppSettings()
+		if err != nil {
+			return fmt.Errorf("loading settings: %w", err)
+		}
+		files := settings.TimePeriods
+		if len(files) == 0 {
+			files = []string{settings.ActiveTimePeriodFile(0)}
+		}
+		pending := false
+		for _, file := range files {
+			td, err := data.LoadTimePeriodDataFromStore(data.GetStore(), file)
+			if err != nil {
+				return fmt.Errorf("loading %s: %w", file, err)
+			}
+			periods, err := td.Rollover(today())
+			switch {
+			case err != nil:
+				fmt.Printf("%s: %v\n", file, err)
+			case periods == nil:
+				fmt.Printf("%s: today is covered.\n", file)
+			default:
+				if err := extendPeriods(td, periods, !yes, os.Stdout); err != nil {
+					return err
+				}
+				pending = pending || !yes
+			}
+		}
+		if pending {
+			fmt.Println("Run with --yes to add them.")
+		}
+		return nil
+	})
+}
+
+// CurrentPeriodKey returns the key of the period holding today in the active
+// time period file. If today is past the file's last period, yes extends the
+// file first, reporting the added periods on stderr; without it, the error
+// says what would be added.
+func CurrentPeriodKey(yes bool) (string, error) {
+	td, err := data.LoadTimePeriodData()
+	if err != nil {
+		return "", err
+	}
+	tp, err := td.GetCurrentPeriod()
+	if err == nil {
+		return tp.Key, nil
+	}
+	periods, rerr := td.Rollover(today())
+	if rerr != nil {
+		return "", fmt.Errorf("cannot determine current period: %w", rerr)
+	}
+	if periods == nil {
+		return "", fmt.Errorf("cannot determine current period: %w (try specifying a period key)", err)
+	}
+	if !yes {
+		keys := periods[0].Key
+		if len(periods) > 1 {
+			keys += " to " + periods[len(periods)-1].Key
+		}
+		return "", fmt.Errorf("today is past the last period in %s; rerun with --yes to add %s, or specify a period key",
+			td.Filename(), keys)
+	}
+
+	err = withWriteLock(func() error {
+		if td, err = data.LoadTimePeriodData(); err != nil {
+			return err
+		}
+		if periods, err = td.Rollover(today()); err != nil || periods == nil {
+			return err
+		}
+		return extendPeriods(td, periods, false, os.Stderr)
+	})
+	if err != nil {
+		return "", err
+	}
+	if tp, err = td.GetPeriodByDate(today()); err != nil {
+		return "", fmt.Errorf("cannot determine current period: %w", err)
+	}
+	return tp.Key, nil
+}
+
+// extendPeriods merges periods into td, saves it unless dryRun, and reports
+// what was added to w.
+func extendPeriods(td *data.TimePeriodData, periods []data.TimePeriod, dryRun bool, w io.Writer) error {
+	results := MergePeriods(td, periods)
+	if !dryRun && countAdded(results) > 0 {
+		if err := td.Save(); err != nil {
+			return fmt.Errorf("saving %s: %w", td.Filename(), err)
+		}
+	}
+	fmt.Fprintf(w, "%s: today is past the last period.\n", td.Filename())
+	return WriteImportReport(results, "time periods", dryRun, w)
+}
+
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// MergePeriods adds each of periods whose key isn't in td yet and whose dates
+// don't overlap any of td's periods.
+func MergePeriods(td *data.TimePeriodData, periods []data.TimePeriod) []ImportResult {
+	var results []ImportResult
+	for _, tp := range periods {
+		r := ImportResult{Date: tp.StartDateRaw, EndDate: tp.EndDateRaw, Name: tp.Key + "  " + tp.Name}
+		for _, prev := range td.All() {
+			if prev.Key == tp.Key {
+				r.Reason = "key already in file"
+				break
+			}
+			if !tp.StartDate.After(prev.EndDate) && !tp.EndDate.Before(prev.StartDate) {
+				r.Reason = "overlaps " + prev.Key
+				break
+			}
+		}
+		if r.Reason == "" {
+			td.Add(tp)
+			r.Added = true
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// PeriodsFilename returns the default time period file name for s, e.g.
+// "calendar-quarters.yaml", "fiscal-oct-months.yaml",
+// "retail-4-4-5-weeks.yaml" or "iso-weeks.yaml".
+func PeriodsFilename(s data.PeriodSpec) string {
+	switch {
+	case s.Unit == data.UnitWeeks && s.Pattern == "":
+		return "iso-weeks.yaml"
+	case s.Pattern != "":
+		return fmt.Sprintf("retail-%s-%s.yaml", s.Pattern, s.Unit)
+	case s.Fiscal():
+		return fmt.Sprintf("fiscal-%s-%s.yaml", strings.ToLower(s.StartMonth.String()[:3]), s.Unit)
+	}
+	return fmt.Sprintf("calendar-%s.yaml", s.Unit)
+}
+
+// registerTimePeriodFile adds file to the time_periods list in settings.yaml
+// unless it is already there.
+func registerTimePeriodFile(file string, dryRun bool) error {
+	settings, err := data.LoadAppSettings()
+	if err != nil {
+		return fmt.Errorf("loading settings: %w", err)
+	}
+	for _, f := range settings.TimePeriods {
+		if f == file {
+			fmt.Printf("%s is already in time_periods.\n", file)
+			return nil
+		}
+	}
+	if dryRun {
+		fmt.Printf("Would add %s to time_periods.\n", file)
+		return nil
+	}
+	settings.TimePeriods = append(settings.TimePeriods, file)
+	if err := settings.Save(); err != nil {
+		return fmt.Errorf("saving settings: %w", err)
+	}
+	fmt.Printf("Added %s to time_periods; press space in the TUI to switch to it.\n", file)
+	return nil
+}
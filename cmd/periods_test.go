@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"rto/data"
+)
+
+func TestMergePeriods(t *testing.T) {
+	td := data.NewTimePeriodData()
+	for _, tp := range data.DefaultTimePeriods() {
+		td.Add(tp)
+	}
+	periods, err := data.GeneratePeriods(data.PeriodSpec{Unit: data.UnitQuarters, Year: 2026, Years: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := MergePeriods(td, periods)
+	if countAdded(results) != 4 || td.Len() != 12 {
+		t.Errorf("expected the 4 quarters of 2027 added, got %d (%d total)", countAdded(results), td.Len())
+	}
+	if results[0].Added || results[0].Reason != "key already in file" {
+		t.Errorf("expected Q1_2026 skipped as a duplicate key, got %+v", results[0])
+	}
+
+	months, _ := data.GeneratePeriods(data.PeriodSpec{Unit: data.UnitMonths, Year: 2027})
+	if results := MergePeriods(td, months); countAdded(results) != 0 || results[0].Reason != "overlaps Q1_2027" {
+		t.Errorf("expected months overlapping quarters to be skipped, got %+v", results[0])
+	}
+}
+
+func TestRunPeriodsGenerate(t *testing.T) {
+	dir := t.TempDir()
+	if err := RunInitInDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	data.SetDataDir(dir)
+	defer data.SetDataDir("")
+
+	opts := PeriodsGenerateOptions{
+		Spec:     data.PeriodSpec{Unit: data.UnitMonths, StartMonth: time.February, Pattern: "4-4-5", Year: 2026},
+		Register: true,
+	}
+	if err := RunPeriodsGenerate(opts); err != nil {
+		t.Fatal(err)
+	}
+	td, err := data.LoadTimePeriodDataFrom(dir, "retail-4-4-5-months.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// P3 runs from Mar 31 to May 4, so a period can touch three months.
+	if td.Len() != 12 || td.CalendarDisplayColumns() != 3 {
+		t.Errorf("expected 12 periods shown in 3 columns, got %d in %d", td.Len(), td.CalendarDisplayColumns())
+	}
+	settings, _ := data.LoadAppSettingsFrom(dir)
+	if n := len(settings.TimePeriods); n != 2 || settings.TimePeriods[1] != "retail-4-4-5-months.yaml" {
+		t.Errorf("expected the file registered second, got %v", settings.TimePeriods)
+	}
+
+	if err := RunPeriodsGenerate(opts); err != nil {
+		t.Fatal(err)
+	}
+	if settings, _ := data.LoadAppSettingsFrom(dir); len(settings.TimePeriods) != 2 {
+		t.Errorf("registering twice should not duplicate the file, got %v", settings.TimePeriods)
+	}
+}
+
+func TestCurrentPeriodKeyRollover(t *testing.T) {
+	dir := t.TempDir()
+	if err := RunInitInDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	old, _ := data.GeneratePeriods(data.PeriodSpec{Unit: data.UnitQuarters, Year: 2020})
+	td := data.NewTimePeriodData()
+	for _, tp := range old {
+		td.Add(tp)
+	}
+	if err := td.SaveTo(dir); err != nil {
+		t.Fatal(err)
+	}
+	data.SetDataDir(dir)
+	defer data.SetDataDir("")
+
+	if _, err := CurrentPeriodKey(false); err == nil || !strings.Contains(err.Error(), "--yes") || !strings.Contains(err.Error(), "Q1_2021") {
+		t.Errorf("expected an error offering --yes from Q1_2021, got %v", err)
+	}
+	key, err := CurrentPeriodKey(true)
+	now := time.Now()
+	if want := data.TimePeriodKey(fmt.Sprintf("Q%d", (int(now.Month())+2)/3), now.Year()); err != nil || key != want {
+		t.Errorf("CurrentPeriodKey(true) = %q, %v; want %s", key, err, want)
+	}
+	if td, _ := data.LoadTimePeriodDataFrom(dir, ""); td.Len() != 4*(now.Year()-2019) {
+		t.Errorf("expected every quarter through %d, got %d periods", now.Year(), td.Len())
+	}
+}
+
+func TestPeriodsFilename(t *testing.T) {
+	for want, s := range map[string]data.PeriodSpec{
+		"calendar-quarters.yaml":   {Unit: data.UnitQuarters},
+		"calendar-halves.yaml":     {Unit: data.UnitHalves, StartMonth: time.January},
+		"fiscal-oct-years.yaml":    {Unit: data.UnitYears, StartMonth: time.October},
+		"retail-5-4-4-months.yaml": {Unit: data.UnitMonths, StartMonth: time.February, Pattern: "5-4-4"},
+		"iso-weeks.yaml":           {Unit: data.UnitWeeks, StartMonth: time.July},
+	} {
+		if got := PeriodsFilename(s); got != want {
+			t.Errorf("PeriodsFilename(%+v) = %q, want %q", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"rto/calc"
+	"rto/data"
+)
+
+// PlanOptions override the planner block of settings.yaml for RunPlan.
+type PlanOptions struct {
+	Weekdays  []string // preferred office days; replaces planner.weekdays when set
+	Blackouts []string // YYYY-MM-DD dates added to planner.blackouts
+	Buffer    *int     // replaces planner.buffer when set
+	Apply     bool     // record the proposed days as planned office days
+}
+
+// RunPlan proposes the office days that get the given period to its goal,
+// prints them in the selected output format and, with opts.Apply, records
+// them.
+func RunPlan(periodKey string, opts PlanOptions) error {
+	return lockUnless(!opts.Apply, func() error { return runPlan(periodKey, opts) })
+}
+
+func runPlan(periodKey string, opts PlanOptions) error {
+	td, err := data.LoadTimePeriodData()
+	if err != nil {
+		return fmt.Errorf("loading time periods: %w", err)
+	}
+	tp, err := td.GetPeriodByKey(periodKey)
+	if err != nil {
+		return fmt.Errorf("time period %q not found — run 'rto init' to create data files", periodKey)
+	}
+
+	badges, err := data.LoadBadgeEntryData()
+	if err != nil {
+		return fmt.Errorf("loading badge data: %w", err)
+	}
+	holidays, err := data.LoadHolidayData()
+	if err != nil {
+		return fmt.Errorf("loading holidays: %w", err)
+	}
+	vacations, err := data.LoadVacationData()
+	if err != nil {
+		return fmt.Errorf("loading vacations: %w", err)
+	}
+	events, err := data.LoadEventData()
+	if err != nil {
+		return fmt.Errorf("loading events: %w", err)
+	}
+	settings, err := data.LoadAppSettings()
+	if err != nil {
+		return fmt.Errorf("loading settings: %w", err)
+	}
+	policy, err := data.LoadPolicy(settings)
+	if err != nil {
+		return fmt.Errorf("loading policy: %w", err)
+	}
+
+	planner := settings.Planner
+	if len(opts.Weekdays) > 0 {
+		planner.Weekdays = opts.Weekdays
+	}
+	planner.Blackouts = append(planner.Blackouts, opts.Blackouts...)
+	if opts.Buffer != nil {
+		planner.Buffer = *opts.Buffer
+	}
+	planOpts, err := calc.NewPlanOptions(planner, events)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	stats, err := periodStats(tp, badges, holidays, vacations, settings, policy, now)
+	if err != nil {
+		return fmt.Errorf("calculating stats: %w", err)
+	}
+	plan := calc.PlanAttendance(stats, now, planOpts)
+
+	if opts.Apply && len(plan.Days) > 0 {
+		ApplyPlan(badges, plan, settings)
+		if err := badges.Save(); err != nil {
+			return fmt.Errorf("saving badge data: %w", err)
+		}
+	}
+	return WritePlanOutput(plan, stats, planOpts, opts.Apply, outputFormat, os.Stdout)
+}
+
+// ApplyPlan records every day of plan as a planned office day at the
+// default office.
+func ApplyPlan(badges *data.BadgeEntryData, plan *calc.Plan, settings *data.AppSettings) {
+	for _, d := range plan.Days {
+		badges.SetBadge(data.NewPlannedBadge(d, settings.DefaultOffice), false)
+	}
+}
+
+// WritePlan writes plan, made for the period in stats, as text. applied
+// reports whether the days were recorded.
+func WritePlan(plan *calc.Plan, stats *calc.PeriodStats, opts calc.PlanOptions, applied bool, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Plan for %s  (%s – %s)\n", stats.Name,
+		stats.StartDate.Format("Jan 2, 2006"), stats.EndDate.Format("Jan 2, 2006"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Still needed:         %d office days", stats.DaysStillNeeded)
+	if opts.Buffer > 0 {
+		fmt.Fprintf(w, " + %d buffer", opts.Buffer)
+	}
+	fmt.Fprintln(w)
+	if stats.PlannedDays > 0 {
+		fmt.Fprintf(w, "  Already planned:      %d days\n", stats.PlannedDays)
+	}
+	if len(opts.Weekdays) > 0 {
+		names := make([]string, len(opts.Weekdays))
+		for i, d := range opts.Weekdays {
+			names[i] = d.String()[:3]
+		}
+		fmt.Fprintf(w, "  Preferred days:       %s\n", strings.Join(names, ", "))
+	}
+	if len(opts.Blackouts) > 0 {
+		fmt.Fprintf(w, "  Blackout dates:       %d\n", len(opts.Blackouts))
+	}
+	if extra := len(plan.Days) - plan.Needed; extra > 0 {
+		fmt.Fprintf(w, "  Weekly minimum:       %d more days to meet it every week\n", extra)
+	}
+
+	fmt.Fprintln(w)
+	if len(plan.Days) == 0 {
+		fmt.Fprintln(w, "  Nothing to plan: the goal is already covered.")
+	} else {
+		fmt.Fprintf(w, "  Proposed office days (%d):\n", len(plan.Days))
+		for _, d := range plan.Days {
+			note := ""
+			if opts.Avoid[d.Format(data.BadgeDateFormat)] {
+				note = "  (has an event)"
+			}
+			fmt.Fprintf(w, "    %s%s\n", d.Format("Mon Jan 2, 2006"), note)
+		}
+	}
+	if plan.Short > 0 {
+		fmt.Fprintf(w, "\n  Short by %d days: not enough open days left in the period.\n", plan.Short)
+	}
+	if plan.WeeksShort > 0 {
+		fmt.Fprintf(w, "  %d weeks can't reach the weekly minimum.\n", plan.WeeksShort)
+	}
+	if applied && len(plan.Days) > 0 {
+		fmt.Fprintf(w, "\nRecorded %d planned office days.\n", len(plan.Days))
+	}
+	return nil
+}
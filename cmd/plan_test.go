@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"rto/calc"
+	"rto/data"
+)
+
+func TestWritePlan(t *testing.T) {
+	tp := &data.TimePeriod{Key: "Jan", Name: "Jan", StartDateRaw: "2025-01-06", EndDateRaw: "2025-01-17"}
+	_ = tp.ParseDates()
+	badges := data.NewBadgeEntryData()
+	events := data.NewEventData()
+	events.Add(data.Event{Date: "2025-01-14", Description: "Dentist"})
+	today := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+	stats, err := calc.CalculatePeriodStats(tp, badges, data.NewHolidayData(), data.NewVacationData(), 40, &today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := calc.NewPlanOptions(data.PlannerSettings{Weekdays: []string{"tue", "thu"}, Buffer: 1}, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 4 required plus the buffer takes every day left, the one with an event
+	// included.
+	plan := calc.PlanAttendance(stats, today, opts)
+
+	var buf bytes.Buffer
+	if err := WritePlanOutput(plan, stats, opts, false, OutputText, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Still needed:         4 office days + 1 buffer", "Preferred days:       Tue, Thu",
+		"Proposed office days (5):", "Tue Jan 14, 2025  (has an event)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := WritePlanOutput(plan, stats, opts, false, OutputJSON, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var got PlanOutput
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Needed != 5 || len(got.Days) != 5 || got.Days[0].Date != "2025-01-13" || !got.Days[1].HasEvent {
+		t.Errorf("unexpected plan output: %+v", got)
+	}
+
+	ApplyPlan(badges, plan, &data.AppSettings{DefaultOffice: "HQ"})
+	if e, ok := badges.Get("2025-01-16"); !ok || !e.Planned || e.IsBadgedIn || e.Office != "HQ" {
+		t.Errorf("expected a planned office day, got %+v", e)
+	}
+	stats, err = calc.CalculatePeriodStats(tp, badges, data.NewHolidayData(), data.NewVacationData(), 40, &today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.DaysBadgedIn != 0 || stats.PlannedDays != 5 {
+		t.Errorf("applied days should be planned, not badged in: %d badged, %d planned", stats.DaysBadgedIn, stats.PlannedDays)
+	}
+	if again := calc.PlanAttendance(stats, today, opts); len(again.Days) != 0 {
+		t.Errorf("planned days shouldn't be proposed again, got %v", again.Days)
+	}
+}
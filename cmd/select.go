@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// resolveSelector maps a SELECTOR argument to an index into a list. A
+// selector is either the 1-based # shown by the list commands or a date
+// (YYYY-MM-DD), matched against dates[i]. A date that matches more than one
+// entry is rejected with the candidates' numbers so the caller can pick one.
+func resolveSelector(sel, what string, dates []string, describe func(int) string) (int, error) {
+	sel = strings.TrimSpace(sel)
+	if n, err := strconv.Atoi(sel); err == nil {
+		if n < 1 || n > len(dates) {
+			return 0, fmt.Errorf("no %s #%d (there are %d)", what, n, len(dates))
+		}
+		return n - 1, nil
+	}
+
+	var matches []int
+	for i, d := range dates {
+		if d == sel {
+			matches = append(matches, i)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("no %s on %s", what, sel)
+	case 1:
+		return matches[0], nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s matches %d %ss; use its # instead:", sel, len(matches), what)
+	for _, i := range matches {
+		fmt.Fprintf(&b, "\n  #%d  %s", i+1, describe(i))
+	}
+	return 0, fmt.Errorf("%s", b.String())
+}
+
+// indexOf returns the index of the first element equal to x, ignoring index
+// skip (pass -1 to consider every element), or -1 if there is none.
+func indexOf[T comparable](list []T, x T, skip int) int {
+	for i, v := range list {
+		if i != skip && v == x {
+			return i
+		}
+	}
+	return -1
+}
@@ -4,13 +4,17 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"rto/calc"
 	"rto/data"
 )
 
-// RunStats prints statistics for the given period key to stdout.
-func RunStats(periodKey string) error {
+// RunStats prints statistics for the given period key to stdout in the
+// selected output format. days selects per-day rows for CSV output.
+func RunStats(periodKey string, days bool) error {
 	td, err := data.LoadTimePeriodData()
 	if err != nil {
 		return fmt.Errorf("loading time periods: %w", err)
@@ -37,13 +41,72 @@ func RunStats(periodKey string) error {
 	if err != nil {
 		return fmt.Errorf("loading settings: %w", err)
 	}
+	policy, err := data.LoadPolicy(settings)
+	if err != nil {
+		return fmt.Errorf("loading policy: %w", err)
+	}
 
-	stats, err := calc.CalculatePeriodStats(tp, badges, holidays, vacations, settings.Goal, nil)
+	stats, err := periodStats(tp, badges, holidays, vacations, settings, policy, time.Now())
 	if err != nil {
 		return fmt.Errorf("calculating stats: %w", err)
 	}
 
-	return WriteStats(stats, os.Stdout)
+	return WriteStatsOutput(stats, outputFormat, days, os.Stdout)
+}
+
+// RunRollingStats prints the rolling-window attendance as of today, with the
+// trailing rate for every day of the given period, in the selected output
+// format. window is a length such as "12w" or "90d".
+func RunRollingStats(periodKey, window string, days bool) error {
+	windowDays, err := data.ParseWindow(window)
+	if err != nil {
+		return err
+	}
+	td, err := data.LoadTimePeriodData()
+	if err != nil {
+		return fmt.Errorf("loading time periods: %w", err)
+	}
+	tp, err := td.GetPeriodByKey(periodKey)
+	if err != nil {
+		return fmt.Errorf("time period %q not found — run 'rto init' to create data files", periodKey)
+	}
+
+	badges, err := data.LoadBadgeEntryData()
+	if err != nil {
+		return fmt.Errorf("loading badge data: %w", err)
+	}
+	holidays, err := data.LoadHolidayData()
+	if err != nil {
+		return fmt.Errorf("loading holidays: %w", err)
+	}
+	vacations, err := data.LoadVacationData()
+	if err != nil {
+		return fmt.Errorf("loading vacations: %w", err)
+	}
+	settings, err := data.LoadAppSettings()
+	if err != nil {
+		return fmt.Errorf("loading settings: %w", err)
+	}
+	policy, err := data.LoadPolicy(settings)
+	if err != nil {
+		return fmt.Errorf("loading policy: %w", err)
+	}
+
+	stats := calc.CalculateRolling(badges, holidays, vacations, policy, windowDays,
+		tp.StartDate, tp.EndDate, time.Now())
+	return WriteRollingOutput(stats, tp.Key, outputFormat, days, os.Stdout)
+}
+
+// periodStats calculates the stats for tp under policy and, if settings has
+// one, the weekly minimum.
+func periodStats(tp *data.TimePeriod, badges *data.BadgeEntryData, holidays *data.HolidayData,
+	vacations *data.VacationData, settings *data.AppSettings, policy *data.Policy, today time.Time) (*calc.PeriodStats, error) {
+	stats, err := calc.CalculatePolicyStats(tp, badges, holidays, vacations, policy, &today)
+	if err != nil {
+		return nil, err
+	}
+	calc.CalculateWeeks(stats, settings.WeeklyMinimum, today)
+	return stats, nil
 }
 
 // WriteStats formats and writes PeriodStats to the given writer.
@@ -58,6 +121,9 @@ func WriteStats(stats *calc.PeriodStats, w io.Writer) error {
 	}
 
 	fmt.Fprintln(w)
+	if stats.Policy != "" {
+		fmt.Fprintf(w, "  Policy:               %s\n", stats.Policy)
+	}
 	fmt.Fprintf(w, "  Status:               %s\n", stats.ComplianceStatus)
 	fmt.Fprintf(w, "  Days ahead of pace:   %+d\n", stats.DaysAheadOfPace)
 	if stats.RemainingMissableDays >= 0 {
@@ -65,13 +131,55 @@ func WriteStats(stats *calc.PeriodStats, w io.Writer) error {
 	}
 
 	fmt.Fprintln(w)
-	fmt.Fprintf(w, "  Required badge-ins:   %d of %d total days (50%%)\n", stats.DaysRequired, stats.TotalDays)
+	fmt.Fprintf(w, "  Required badge-ins:   %d of %d total days (%d%%)\n", stats.DaysRequired, stats.TotalDays, stats.GoalPct)
 	fmt.Fprintf(w, "  Badged in:            %d\n", stats.DaysBadgedIn)
+	if stats.Credits != float64(stats.DaysBadgedIn) {
+		fmt.Fprintf(w, "  Credited:             %s days\n", formatDays(stats.Credits))
+	}
 	fmt.Fprintf(w, "  Still needed:         %d\n", stats.DaysStillNeeded)
+	if stats.PlannedDays > 0 {
+		fmt.Fprintf(w, "  Planned:              %d  (projected %s days", stats.PlannedDays, formatDays(stats.ProjectedCredits))
+		if stats.PlannedCompletionDate != nil {
+			fmt.Fprintf(w, ", goal reached %s", stats.PlannedCompletionDate.Format("Mon Jan 2"))
+		}
+		fmt.Fprintln(w, ")")
+	}
+	if stats.MissedPlans > 0 {
+		fmt.Fprintf(w, "  Missed plans:         %d\n", stats.MissedPlans)
+	}
+
+	if stats.Committed != nil {
+		fmt.Fprintln(w)
+		writeCommitted(stats, w)
+	}
 
-	officeDays := stats.DaysBadgedIn - stats.FlexDays
+	officeDays := stats.DaysBadgedIn - stats.FlexDays - stats.LeaveAttended
 	fmt.Fprintln(w)
-	fmt.Fprintf(w, "  Badge-ins:            %d  (%d office, %d flex)\n", stats.DaysBadgedIn, officeDays, stats.FlexDays)
+	if stats.LeaveAttended > 0 {
+		fmt.Fprintf(w, "  Badge-ins:            %d  (%d office, %d flex, %d leave counted as office)\n",
+			stats.DaysBadgedIn, officeDays, stats.FlexDays, stats.LeaveAttended)
+	} else {
+		fmt.Fprintf(w, "  Badge-ins:            %d  (%d office, %d flex)\n", stats.DaysBadgedIn, officeDays, stats.FlexDays)
+	}
+	if stats.FlexCap > 0 || stats.FlexWeight != 1 {
+		allowed := "no limit"
+		if stats.FlexCap > 0 {
+			allowed = fmt.Sprintf("%d allowed", stats.FlexCap)
+		}
+		if stats.FlexWeight != 1 {
+			allowed += fmt.Sprintf(", %s days each", formatDays(stats.FlexWeight))
+		}
+		fmt.Fprintf(w, "  Flex credits used:    %d / %s\n", stats.FlexDays, allowed)
+	}
+	if stats.FlexNotCounted > 0 {
+		fmt.Fprintf(w, "  Flex not counted:     %d  (policy limit)\n", stats.FlexNotCounted)
+	}
+	if stats.PartialDays > 0 {
+		fmt.Fprintf(w, "  Partial days:         %d\n", stats.PartialDays)
+	}
+	if leave := formatLeave(stats.LeaveDays); leave != "" {
+		fmt.Fprintf(w, "  Leave:                %s\n", leave)
+	}
 
 	fmt.Fprintln(w)
 	fmt.Fprintf(w, "  Days worked so far:   %d\n", stats.DaysThusFar)
@@ -88,6 +196,11 @@ func WriteStats(stats *calc.PeriodStats, w io.Writer) error {
 		fmt.Fprintf(w, "  Projected completion: %s\n", stats.ProjectedCompletionDate.Format("Jan 2, 2006"))
 	}
 
+	if len(stats.Weeks) > 0 {
+		fmt.Fprintln(w)
+		writeWeeks(stats, w)
+	}
+
 	fmt.Fprintln(w)
 	fmt.Fprintf(w, "  Holidays:             %d\n", stats.Holidays)
 	fmt.Fprintf(w, "  Vacation days:        %d\n", stats.VacationDays)
@@ -96,3 +209,84 @@ func WriteStats(stats *calc.PeriodStats, w io.Writer) error {
 
 	return nil
 }
+
+// writeCommitted writes the stats with pending leave (tentative) beside
+// those with approved leave only (committed).
+func writeCommitted(stats *calc.PeriodStats, w io.Writer) {
+	c := stats.Committed
+	fmt.Fprintf(w, "  Pending leave:        %d days not yet approved\n", stats.PendingLeaveDays)
+	fmt.Fprintf(w, "  %-20s  %-12s  %s\n", "", "Tentative", "Committed")
+	row := func(label, tentative, committed string) {
+		fmt.Fprintf(w, "  %-20s  %-12s  %s\n", label+":", tentative, committed)
+	}
+	row("Total days", strconv.Itoa(stats.TotalDays), strconv.Itoa(c.TotalDays))
+	row("Required badge-ins", strconv.Itoa(stats.DaysRequired), strconv.Itoa(c.DaysRequired))
+	row("Still needed", strconv.Itoa(stats.DaysStillNeeded), strconv.Itoa(c.DaysStillNeeded))
+	row("Days ahead of pace", fmt.Sprintf("%+d", stats.DaysAheadOfPace), fmt.Sprintf("%+d", c.DaysAheadOfPace))
+	row("Status", stats.ComplianceStatus, c.ComplianceStatus)
+}
+
+// writeWeeks writes the weekly-minimum summary and one line per week.
+func writeWeeks(stats *calc.PeriodStats, w io.Writer) {
+	counts := map[calc.WeekStatus]int{}
+	for _, wk := range stats.Weeks {
+		counts[wk.Status]++
+	}
+	fmt.Fprintf(w, "  Weekly minimum:       %d days\n", stats.WeeklyMinimum)
+	fmt.Fprintf(w, "  Weeks met:            %d of %d  (%d missed, %d in progress, %d upcoming, %d exempt)\n",
+		stats.WeeksMet, len(stats.Weeks), stats.WeeksMissed,
+		counts[calc.WeekInProgress], counts[calc.WeekUpcoming], counts[calc.WeekExempt])
+	for _, wk := range stats.Weeks {
+		fmt.Fprintf(w, "    W%02d  %s – %s  %s / %d  %s\n", wk.Week,
+			wk.Start.Format("Jan 2"), wk.End.Format("Jan 2"), formatDays(wk.BadgedIn), wk.Required, strings.ReplaceAll(string(wk.Status), "_", " "))
+	}
+}
+
+// WriteRolling formats and writes the rolling window ending today.
+func WriteRolling(stats *calc.RollingStats, w io.Writer) error {
+	t := stats.Today
+	_, err := fmt.Fprintf(w, "Rolling window: %s  (%s – %s)\n",
+		data.FormatWindow(stats.WindowDays), t.WindowStart.Format("Jan 2, 2006"), t.Date.Format("Jan 2, 2006"))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Status:               %s\n", rollingStatus(t))
+	fmt.Fprintf(w, "  Required badge-ins:   %d of %d available days (%d%%)\n", t.Required, t.Available, stats.GoalPct)
+	fmt.Fprintf(w, "  Badged in:            %s\n", formatDays(t.BadgedIn))
+	fmt.Fprintf(w, "  Rolling average:      %.1f%%\n", t.Rate*100)
+	switch {
+	case t.BelowGoalOn.IsZero():
+		fmt.Fprintf(w, "  Below goal from:      never\n")
+	case t.BelowGoalOn.Equal(t.Date):
+		fmt.Fprintf(w, "  Below goal from:      today\n")
+	default:
+		fmt.Fprintf(w, "  Below goal from:      %s  (if you stop badging in)\n", t.BelowGoalOn.Format("Jan 2, 2006"))
+	}
+	return nil
+}
+
+func rollingStatus(d calc.RollingDay) string {
+	if d.Compliant() {
+		return "Compliant"
+	}
+	return "Below Goal"
+}
+
+// formatDays formats a possibly fractional number of days without trailing
+// zeros, e.g. 3, 2.5.
+// formatLeave lists days of leave by type, e.g. "3 vacation, 1 sick", in
+// the order of data.LeaveTypes.
+func formatLeave(days map[data.LeaveType]int) string {
+	var parts []string
+	for _, t := range data.LeaveTypes {
+		if n := days[t]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, strings.ToLower(t.Label())))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatDays(d float64) string {
+	return strconv.FormatFloat(d, 'f', -1, 64)
+}
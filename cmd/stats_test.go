@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
@@ -176,7 +177,7 @@ func TestRunStatsWithTempDir(t *testing.T) {
 	data.SetDataDir(dir)
 	defer data.SetDataDir("")
 
-	err := RunStats("Q1_2025")
+	err := RunStats("Q1_2025", false)
 	if err != nil {
 		t.Errorf("expected no error, got: %v", err)
 	}
@@ -198,7 +199,7 @@ func TestRunStatsPeriodNotFound(t *testing.T) {
 	data.SetDataDir(dir)
 	defer data.SetDataDir("")
 
-	err := RunStats("Q99_2025")
+	err := RunStats("Q99_2025", false)
 	if err == nil {
 		t.Error("expected error for missing period")
 	}
@@ -224,3 +225,143 @@ func TestWriteStatsNoCurrentAverage(t *testing.T) {
 		t.Error("should not show current average when DaysThusFar is 0")
 	}
 }
+
+func TestWriteStatsWeeklyMinimum(t *testing.T) {
+	stats := makeTestStats()
+	if strings.Contains(writeStatsString(t, stats), "Weekly minimum") {
+		t.Error("weekly section should be omitted without a weekly policy")
+	}
+
+	calc.CalculateWeeks(stats, data.WeeklyMinimum{Days: 2}, time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC))
+	out := writeStatsString(t, stats)
+	for _, want := range []string{"Weekly minimum:       2 days", "Weeks met:            1 of 14",
+		"W01  Jan 1 – Jan 5  2 / 1  met", "W02  Jan 6 – Jan 12  1 / 2  in progress"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q:\n%s", want, out)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStatsOutput(stats, OutputJSON, false, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var got StatsOutput
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.WeeklyMinimum != 2 || got.WeeksMet != 1 || len(got.Weeks) != 14 || got.Weeks[1].Status != "in_progress" {
+		t.Errorf("unexpected weekly output: %+v", got.Weeks)
+	}
+}
+
+func TestWriteStatsFlexCap(t *testing.T) {
+	if strings.Contains(writeStatsString(t, makeTestStats()), "Flex credits used") {
+		t.Error("flex usage should be omitted without a cap or weight")
+	}
+
+	tp := &data.TimePeriod{Key: "Q1_2025", Name: "Q1", StartDateRaw: "2025-01-01", EndDateRaw: "2025-03-31"}
+	_ = tp.ParseDates()
+	badges := data.NewBadgeEntryData()
+	for _, d := range []string{"2025-01-02", "2025-01-03", "2025-01-06"} {
+		badges.Add(data.BadgeEntry{EntryDate: d, IsBadgedIn: true, IsFlexCredit: true})
+	}
+	policy := data.DefaultPolicy(50)
+	policy.Flex = data.FlexPolicy{Cap: 2, Weight: 0.5}
+	today := time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)
+	stats, err := calc.CalculatePolicyStats(tp, badges, data.NewHolidayData(), data.NewVacationData(), policy, &today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := writeStatsString(t, stats)
+	for _, want := range []string{"Flex credits used:    2 / 2 allowed, 0.5 days each", "Flex not counted:     1", "Credited:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteStatsPendingLeave(t *testing.T) {
+	if strings.Contains(writeStatsString(t, makeTestStats()), "Pending leave") {
+		t.Error("pending leave should be omitted when none is pending")
+	}
+
+	tp := &data.TimePeriod{Key: "Q1_2025", Name: "Q1", StartDateRaw: "2025-01-01", EndDateRaw: "2025-03-31"}
+	_ = tp.ParseDates()
+	vacations := data.NewVacationData()
+	vacations.Add(data.Vacation{Destination: "Ski", StartDate: "2025-02-03", EndDate: "2025-02-14"})
+	today := time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)
+	stats, err := calc.CalculatePeriodStats(tp, data.NewBadgeEntryData(), data.NewHolidayData(), vacations, 50, &today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := writeStatsString(t, stats)
+	for _, want := range []string{"Pending leave:        10 days not yet approved", "Tentative     Committed",
+		"Total days:           54            64", "Required badge-ins:   27            32"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q:\n%s", want, out)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStatsOutput(stats, OutputJSON, false, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var got StatsOutput
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.PendingLeaveDays != 10 || got.Committed.TotalDays != 64 || got.Committed.DaysRequired != 32 {
+		t.Errorf("unexpected pending leave output: %d pending, %+v", got.PendingLeaveDays, got.Committed)
+	}
+}
+
+func writeStatsString(t *testing.T, stats *calc.PeriodStats) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteStats(stats, &buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestWriteRolling(t *testing.T) {
+	badges := data.NewBadgeEntryData()
+	for _, d := range []string{"2025-01-06", "2025-01-07", "2025-01-08", "2025-01-09", "2025-01-10", "2025-01-13"} {
+		badges.Add(data.BadgeEntry{EntryDate: d, IsBadgedIn: true})
+	}
+	from := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)
+	stats := calc.CalculateRolling(badges, data.NewHolidayData(), data.NewVacationData(), data.DefaultPolicy(50), 14, from, today, today)
+
+	var buf bytes.Buffer
+	if err := WriteRollingOutput(stats, "Jan", OutputText, false, &buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Rolling window: 2 weeks  (Jan 4, 2025 – Jan 17, 2025)", "Compliant",
+		"5 of 10 available days", "60.0%", "Below goal from:      Jan 21, 2025"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output should contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := WriteRollingOutput(stats, "Jan", OutputJSON, false, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var got RollingOutput
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.WindowDays != 14 || got.Date != "2025-01-17" || got.BelowGoalOn == nil || *got.BelowGoalOn != "2025-01-21" || len(got.Days) != 5 {
+		t.Errorf("unexpected JSON: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteRollingOutput(stats, "Jan", OutputCSV, true, &buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 || !strings.HasPrefix(lines[1], "Jan,14,50,2025-01-13,2024-12-31,") {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
@@ -2,13 +2,19 @@ package cmd
 
 import (
 	"fmt"
-	"log"
 
 	tea "charm.land/bubbletea/v2"
+	"rto/data"
 	"rto/ui/app"
 )
 
 func RunBubbleteaTUI() error {
+	lock, err := data.AcquireStoreLock(data.GetStore(), "tui", lockWait)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	model, err := app.New()
 	if err != nil {
 		return fmt.Errorf("could not create app model: %w", err)
@@ -16,7 +22,7 @@ func RunBubbleteaTUI() error {
 
 	p := tea.NewProgram(model)
 	if _, err := p.Run(); err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("running TUI: %w", err)
 	}
 
 	// After the TUI exits, save the data
@@ -32,7 +38,7 @@ func RunBubbleteaTUI() error {
 	if err := model.HolidayData().Save(); err != nil {
 		return fmt.Errorf("saving holidays: %w", err)
 	}
-	if err := model.GetSettings().SaveTo(model.DataDir()); err != nil {
+	if err := model.GetSettings().Save(); err != nil {
 		return fmt.Errorf("saving settings: %w", err)
 	}
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"rto/data"
 )
@@ -14,7 +15,7 @@ func RunVacations() error {
 	if err != nil {
 		return fmt.Errorf("loading vacations: %w", err)
 	}
-	return WriteVacations(vd, os.Stdout)
+	return WriteVacationsOutput(vd, outputFormat, os.Stdout)
 }
 
 // WriteVacations formats and writes vacation data to the given writer.
@@ -26,21 +27,21 @@ func WriteVacations(vd *data.VacationData, w io.Writer) error {
 	}
 
 	// Header
-	_, err := fmt.Fprintf(w, "%-4s  %-30s  %-12s  %-12s  %s\n",
-		"#", "Destination", "Start", "End", "Approved")
+	_, err := fmt.Fprintf(w, "%-4s  %-30s  %-15s  %-12s  %-12s  %s\n",
+		"#", "Destination", "Type", "Start", "End", "Approved")
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(w, "%-4s  %-30s  %-12s  %-12s  %s\n",
-		"----", "------------------------------", "------------", "------------", "--------")
+	fmt.Fprintf(w, "%-4s  %-30s  %-15s  %-12s  %-12s  %s\n",
+		"----", "------------------------------", "---------------", "------------", "------------", "--------")
 
 	for i, v := range all {
 		approved := "No"
 		if v.Approved {
 			approved = "Yes"
 		}
-		_, err := fmt.Fprintf(w, "%-4d  %-30s  %-12s  %-12s  %s\n",
-			i+1, truncate(v.Destination, 30), v.StartDate, v.EndDate, approved)
+		_, err := fmt.Fprintf(w, "%-4d  %-30s  %-15s  %-12s  %-12s  %s\n",
+			i+1, truncate(v.Destination, 30), v.Leave().Label(), v.StartDate, v.EndDate, approved)
 		if err != nil {
 			return err
 		}
@@ -56,3 +57,149 @@ func truncate(s string, maxLen int) string {
 	}
 	return string(runes[:maxLen-3]) + "..."
 }
+
+// VacationEdit lists the fields to change on an existing vacation; nil
+// fields are left as they are.
+type VacationEdit struct {
+	Destination *string
+	StartDate   *string
+	EndDate     *string
+	Approved    *bool
+	Type        *data.LeaveType
+}
+
+// AddVacation validates v and appends it, rejecting exact duplicates.
+func AddVacation(vd *data.VacationData, v data.Vacation) error {
+	if err := v.Validate(); err != nil {
+		return err
+	}
+	if indexOf(vd.All(), v, -1) >= 0 {
+		return fmt.Errorf("vacation %q (%s – %s) already exists", v.Destination, v.StartDate, v.EndDate)
+	}
+	vd.Add(v)
+	return nil
+}
+
+// EditVacation applies edit to the vacation chosen by sel (a # or start date)
+// and returns the updated vacation.
+func EditVacation(vd *data.VacationData, sel string, edit VacationEdit) (data.Vacation, error) {
+	i, err := selectVacation(vd, sel)
+	if err != nil {
+		return data.Vacation{}, err
+	}
+	v := vd.All()[i]
+	if edit.Destination != nil {
+		v.Destination = strings.TrimSpace(*edit.Destination)
+	}
+	if edit.StartDate != nil {
+		v.StartDate = strings.TrimSpace(*edit.StartDate)
+	}
+	if edit.EndDate != nil {
+		v.EndDate = strings.TrimSpace(*edit.EndDate)
+	}
+	if edit.Approved != nil {
+		v.Approved = *edit.Approved
+	}
+	if edit.Type != nil {
+		v.Type = *edit.Type
+	}
+	if err := v.Validate(); err != nil {
+		return data.Vacation{}, err
+	}
+	if indexOf(vd.All(), v, i) >= 0 {
+		return data.Vacation{}, fmt.Errorf("vacation %q (%s – %s) already exists", v.Destination, v.StartDate, v.EndDate)
+	}
+	vd.Update(i, v)
+	return v, nil
+}
+
+// RemoveVacation deletes the vacation chosen by sel and returns it.
+func RemoveVacation(vd *data.VacationData, sel string) (data.Vacation, error) {
+	i, err := selectVacation(vd, sel)
+	if err != nil {
+		return data.Vacation{}, err
+	}
+	v := vd.All()[i]
+	vd.RemoveAt(i)
+	return v, nil
+}
+
+// RunVacationAdd adds a vacation to the global store.
+func RunVacationAdd(v data.Vacation) error {
+	return modifyVacations(func(vd *data.VacationData) (string, error) {
+		if err := AddVacation(vd, v); err != nil {
+			return "", err
+		}
+		return "Added vacation: " + formatVacation(v), nil
+	})
+}
+
+// RunVacationEdit edits a vacation in the global store.
+func RunVacationEdit(sel string, edit VacationEdit) error {
+	return modifyVacations(func(vd *data.VacationData) (string, error) {
+		v, err := EditVacation(vd, sel, edit)
+		if err != nil {
+			return "", err
+		}
+		return "Updated vacation: " + formatVacation(v), nil
+	})
+}
+
+// RunVacationRemove removes a vacation from the global store.
+func RunVacationRemove(sel string) error {
+	return modifyVacations(func(vd *data.VacationData) (string, error) {
+		v, err := RemoveVacation(vd, sel)
+		if err != nil {
+			return "", err
+		}
+		return "Removed vacation: " + formatVacation(v), nil
+	})
+}
+
+func modifyVacations(fn func(vd *data.VacationData) (string, error)) error {
+	return withWriteLock(func() error {
+		vd, err := data.LoadVacationData()
+		if err != nil {
+			return fmt.Errorf("loading vacations: %w", err)
+		}
+		msg, err := fn(vd)
+		if err != nil {
+			return err
+		}
+		if err := vd.Save(); err != nil {
+			return fmt.Errorf("saving vacations: %w", err)
+		}
+		fmt.Println(msg)
+		return nil
+	})
+}
+
+func selectVacation(vd *data.VacationData, sel string) (int, error) {
+	all := vd.All()
+	dates := make([]string, len(all))
+	for i, v := range all {
+		dates[i] = v.StartDate
+	}
+	return resolveSelector(sel, "vacation", dates, func(i int) string { return formatVacation(all[i]) })
+}
+
+// ParseLeaveFlag parses a --type flag for a vacation. A vacation is stored
+// without a type, as vacations.yaml has always had them.
+func ParseLeaveFlag(s string) (data.LeaveType, error) {
+	t, err := data.ParseLeaveType(s)
+	if t == data.LeaveVacation {
+		t = ""
+	}
+	return t, err
+}
+
+func formatVacation(v data.Vacation) string {
+	s := fmt.Sprintf("%s (%s – %s)", v.Destination, v.StartDate, v.EndDate)
+	if v.Leave() != data.LeaveVacation {
+		s += ", " + strings.ToLower(v.Leave().Label())
+	}
+	if v.Approved {
+		s += ", approved"
+	}
+	return s
+}
@@ -86,3 +86,43 @@ func TestTruncate(t *testing.T) {
 		t.Error("truncated string should end with ...")
 	}
 }
+
+func TestVacationCRUD(t *testing.T) {
+	vd := data.NewVacationData()
+	if err := AddVacation(vd, data.Vacation{Destination: "Beach", StartDate: "2025-06-10", EndDate: "2025-06-01"}); err == nil {
+		t.Error("expected inverted range error")
+	}
+	if err := AddVacation(vd, data.Vacation{StartDate: "2025-06-10", EndDate: "2025-06-12"}); err == nil {
+		t.Error("expected missing destination error")
+	}
+	if err := AddVacation(vd, data.Vacation{Destination: "Beach", StartDate: "2025-06-10", EndDate: "2025-06-12"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	approved := true
+	end := "2025-06-09"
+	if _, err := EditVacation(vd, "2025-06-10", VacationEdit{EndDate: &end}); err == nil {
+		t.Error("edit should be validated too")
+	}
+	v, err := EditVacation(vd, "1", VacationEdit{Approved: &approved})
+	if err != nil || !v.Approved || v.Destination != "Beach" {
+		t.Errorf("edit: got %+v, %v", v, err)
+	}
+	sick, err := ParseLeaveFlag("sick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = EditVacation(vd, "1", VacationEdit{Type: &sick})
+	if err != nil || v.Leave() != data.LeaveSick || !strings.Contains(formatVacation(v), "sick") {
+		t.Errorf("edit type: got %+v, %v", v, err)
+	}
+	if lt, _ := ParseLeaveFlag("vacation"); lt != "" {
+		t.Errorf("a vacation should be stored without a type, got %q", lt)
+	}
+	if _, err := RemoveVacation(vd, "2025-01-01"); err == nil {
+		t.Error("expected no-match error")
+	}
+	if _, err := RemoveVacation(vd, "2025-06-10"); err != nil || vd.Len() != 0 {
+		t.Errorf("remove: %v (len %d)", err, vd.Len())
+	}
+}
@@ -1,5 +1,10 @@
 package data
 
+import (
+	"fmt"
+	"time"
+)
+
 const settingsFilename = "settings.yaml"
 const settingsDefaultOffice = "McLean, VA"
 const settingsDefaultFlex = "Flex Credit"
@@ -12,6 +17,53 @@ type AppSettings struct {
 	FlexCredit    string   `yaml:"flex_credit"`
 	Goal          int      `yaml:"goal"`
 	TimePeriods   []string `yaml:"time_periods"`
+
+	WorkSchedule  []WorkWeek          `yaml:"work_schedule,omitempty"`
+	WeeklyMinimum WeeklyMinimum       `yaml:"weekly_minimum,omitempty"`
+	RollingWindow RollingWindow       `yaml:"rolling_window,omitempty"`
+	BadgeImport   BadgeImportSettings `yaml:"badge_import,omitempty"`
+	Planner       PlannerSettings     `yaml:"planner,omitempty"`
+}
+
+// BadgeImportSettings maps the columns of a badge-swipe CSV export for
+// rto import badges. Columns are named by header or by 1-based number.
+type BadgeImportSettings struct {
+	TimestampColumn string `yaml:"timestamp_column,omitempty"` // default "timestamp"
+	TimestampFormat string `yaml:"timestamp_format,omitempty"` // Go layout; empty tries common formats
+	Timezone        string `yaml:"timezone,omitempty"`         // IANA zone; empty means the local zone
+	LocationColumn  string `yaml:"location_column,omitempty"`  // door/building; empty records default_office
+	CardColumn      string `yaml:"card_column,omitempty"`
+	CardID          string `yaml:"card_id,omitempty"` // only import swipes of this card
+
+	// Offices maps a location (exactly, or as a case-insensitive substring)
+	// to an office name. Unmapped locations are recorded as default_office.
+	Offices map[string]string `yaml:"offices,omitempty"`
+}
+
+// PlannerSettings are the preferences rto plan and the TUI's planner use to
+// propose office days.
+type PlannerSettings struct {
+	Weekdays  []string `yaml:"weekdays,omitempty"`  // preferred office days, e.g. [tue, wed, thu]
+	Blackouts []string `yaml:"blackouts,omitempty"` // YYYY-MM-DD dates never planned
+	Buffer    int      `yaml:"buffer,omitempty"`    // office days to plan beyond the goal
+}
+
+// Validate checks the weekday names, blackout dates and buffer.
+func (p PlannerSettings) Validate() error {
+	for _, name := range p.Weekdays {
+		if _, err := ParseWeekday(name); err != nil {
+			return fmt.Errorf("planner: %w", err)
+		}
+	}
+	for _, d := range p.Blackouts {
+		if _, err := time.Parse(BadgeDateFormat, d); err != nil {
+			return fmt.Errorf("planner: invalid blackout %q (expected YYYY-MM-DD)", d)
+		}
+	}
+	if p.Buffer < 0 {
+		return fmt.Errorf("planner: buffer can't be negative, got %d", p.Buffer)
+	}
+	return nil
 }
 
 // DefaultAppSettings returns settings with sensible defaults.
@@ -24,16 +76,21 @@ func DefaultAppSettings() AppSettings {
 	}
 }
 
-// LoadAppSettings reads settings from the global data directory.
+// LoadAppSettings reads settings from the global store.
 func LoadAppSettings() (*AppSettings, error) {
-	return LoadAppSettingsFrom(GetDataDir())
+	return LoadAppSettingsFromStore(GetStore())
 }
 
 // LoadAppSettingsFrom reads settings from the specified directory.
 func LoadAppSettingsFrom(dir string) (*AppSettings, error) {
+	return LoadAppSettingsFromStore(NewDirStore(dir))
+}
+
+// LoadAppSettingsFromStore reads settings from the given store.
+func LoadAppSettingsFromStore(st Store) (*AppSettings, error) {
 	s := DefaultAppSettings()
 	var loaded AppSettings
-	if err := LoadYAMLFrom(dir, settingsFilename, &loaded); err != nil {
+	if err := loadDocument(st, settingsFilename, &loaded); err != nil {
 		return nil, err
 	}
 	if loaded.DefaultOffice != "" {
@@ -48,17 +105,32 @@ func LoadAppSettingsFrom(dir string) (*AppSettings, error) {
 	if len(loaded.TimePeriods) > 0 {
 		s.TimePeriods = loaded.TimePeriods
 	}
+	s.WorkSchedule = loaded.WorkSchedule
+	s.WeeklyMinimum = loaded.WeeklyMinimum
+	s.RollingWindow = loaded.RollingWindow
+	s.BadgeImport = loaded.BadgeImport
+	s.Planner = loaded.Planner
 	return &s, nil
 }
 
-// Save writes settings to the global data directory.
+// Save writes settings to the global store.
 func (s *AppSettings) Save() error {
-	return s.SaveTo(GetDataDir())
+	return s.SaveToStore(GetStore())
 }
 
 // SaveTo writes settings to the specified directory.
 func (s *AppSettings) SaveTo(dir string) error {
-	return SaveYAMLTo(dir, settingsFilename, s)
+	return s.SaveToStore(NewDirStore(dir))
+}
+
+// SaveToStore writes settings to the given store.
+func (s *AppSettings) SaveToStore(st Store) error {
+	return saveDocument(st, settingsFilename, s)
+}
+
+// Schedule parses WorkSchedule; an empty list means Monday–Friday.
+func (s *AppSettings) Schedule() (*WorkSchedule, error) {
+	return ParseWorkSchedule(s.WorkSchedule)
 }
 
 // ActiveTimePeriodFile returns the filename for the given index (0-based) in
@@ -158,3 +158,47 @@ func TestAppSettingsGoalDefaultWhenZero(t *testing.T) {
 		t.Errorf("expected default goal %d when saved as 0, got %d", settingsDefaultGoal, loaded.Goal)
 	}
 }
+
+func TestAppSettingsBadgeImportSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	s := DefaultAppSettings()
+	s.BadgeImport = BadgeImportSettings{
+		TimestampColumn: "Event Time",
+		Timezone:        "America/New_York",
+		LocationColumn:  "3",
+		Offices:         map[string]string{"HQ-": "McLean, VA"},
+	}
+	if err := s.SaveTo(dir); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+
+	loaded, err := LoadAppSettingsFrom(dir)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if loaded.BadgeImport.TimestampColumn != "Event Time" || loaded.BadgeImport.Offices["HQ-"] != "McLean, VA" {
+		t.Errorf("badge_import not round-tripped: %+v", loaded.BadgeImport)
+	}
+}
+
+func TestPlannerSettings(t *testing.T) {
+	dir := t.TempDir()
+	s := DefaultAppSettings()
+	s.Planner = PlannerSettings{Weekdays: []string{"tue", "thu"}, Blackouts: []string{"2025-12-24"}, Buffer: 2}
+	if err := s.SaveTo(dir); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	loaded, err := LoadAppSettingsFrom(dir)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if len(loaded.Planner.Weekdays) != 2 || loaded.Planner.Buffer != 2 || loaded.Planner.Validate() != nil {
+		t.Errorf("planner not round-tripped: %+v", loaded.Planner)
+	}
+
+	for _, bad := range []PlannerSettings{{Weekdays: []string{"someday"}}, {Blackouts: []string{"12/24"}}, {Buffer: -1}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
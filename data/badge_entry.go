@@ -46,10 +46,34 @@ type BadgeEntry struct {
 	Office       string   `json:"office"`
 	IsBadgedIn   bool     `json:"is_badged_in"`
 	IsFlexCredit bool     `json:"is_flex_credit"`
+	// Credit is the fraction of the day credited, e.g. 0.5 for a half day.
+	// Zero (the default, and what older files hold) means a full day.
+	Credit float64 `json:"credit,omitempty"`
+	// Planned marks an office day that is planned rather than attended.
+	// IsBadgedIn is false until it is converted by recording the day.
+	Planned bool `json:"planned,omitempty"`
+}
+
+// HalfDay is the credit the calendar's c key gives a partial day.
+const HalfDay = 0.5
+
+// DayCredit returns the fraction of the day the entry is credited for:
+// Credit if it is between 0 and 1, a full day otherwise.
+func (e BadgeEntry) DayCredit() float64 {
+	if e.Credit <= 0 || e.Credit >= 1 {
+		return 1
+	}
+	return e.Credit
+}
+
+// IsPartial reports whether the entry is credited for less than a full day.
+func (e BadgeEntry) IsPartial() bool {
+	return e.DayCredit() < 1
 }
 
 type badgeDataFile struct {
-	BadgeData []BadgeEntry `json:"badge_data"`
+	SchemaVersion int          `json:"schema_version"`
+	BadgeData     []BadgeEntry `json:"badge_data"`
 }
 
 // BadgeEntryData is the in-memory container for all badge entries.
@@ -62,15 +86,20 @@ func NewBadgeEntryData() *BadgeEntryData {
 	return &BadgeEntryData{}
 }
 
-// Load reads badge data from the global data directory.
+// LoadBadgeEntryData reads badge data from the global store.
 func LoadBadgeEntryData() (*BadgeEntryData, error) {
-	return LoadBadgeEntryDataFrom(GetDataDir())
+	return LoadBadgeEntryDataFromStore(GetStore())
 }
 
 // LoadBadgeEntryDataFrom reads badge data from the specified directory.
 func LoadBadgeEntryDataFrom(dir string) (*BadgeEntryData, error) {
+	return LoadBadgeEntryDataFromStore(NewDirStore(dir))
+}
+
+// LoadBadgeEntryDataFromStore reads badge data from the given store.
+func LoadBadgeEntryDataFromStore(s Store) (*BadgeEntryData, error) {
 	var file badgeDataFile
-	if err := LoadJSONFrom(dir, badgeDataFilename, &file); err != nil {
+	if err := loadVersioned(s, badgeDataFilename, DocBadges, &file); err != nil {
 		return nil, err
 	}
 	if file.BadgeData == nil {
@@ -79,15 +108,23 @@ func LoadBadgeEntryDataFrom(dir string) (*BadgeEntryData, error) {
 	return &BadgeEntryData{entries: file.BadgeData}, nil
 }
 
-// Save writes badge data to the global data directory.
+// Save writes badge data to the global store.
 func (b *BadgeEntryData) Save() error {
-	return b.SaveTo(GetDataDir())
+	return b.SaveToStore(GetStore())
 }
 
 // SaveTo writes badge data to the specified directory.
 func (b *BadgeEntryData) SaveTo(dir string) error {
-	file := badgeDataFile{BadgeData: b.entries}
-	return SaveJSONTo(dir, badgeDataFilename, &file)
+	return b.SaveToStore(NewDirStore(dir))
+}
+
+// SaveToStore writes badge data to the given store.
+func (b *BadgeEntryData) SaveToStore(s Store) error {
+	return saveDocument(s, badgeDataFilename, b.fileDocument())
+}
+
+func (b *BadgeEntryData) fileDocument() interface{} {
+	return &badgeDataFile{SchemaVersion: CurrentSchemaVersion, BadgeData: b.entries}
 }
 
 // Has returns true if a badge entry exists for the given date key (YYYY-MM-DD).
@@ -126,6 +163,164 @@ func (b *BadgeEntryData) Remove(key string) {
 	b.entries = filtered
 }
 
+// BadgeChange reports the outcome of ToggleBadge or SetBadge.
+type BadgeChange int
+
+const (
+	BadgeUnchanged BadgeChange = iota // the date was already in the requested state
+	BadgeAdded
+	BadgeRemoved
+	BadgeUpdated  // an existing entry's office or credit was changed
+	BadgeConflict // the date holds an entry of the other kind (office vs. flex)
+)
+
+// NewOfficeBadge returns an in-office badge entry for date.
+func NewOfficeBadge(date time.Time, office string) BadgeEntry {
+	return BadgeEntry{
+		EntryDate:  date.Format(BadgeDateFormat),
+		DateTime:   FlexTime{Time: date},
+		Office:     office,
+		IsBadgedIn: true,
+	}
+}
+
+// NewPlannedBadge returns a planned office day for date.
+func NewPlannedBadge(date time.Time, office string) BadgeEntry {
+	e := NewOfficeBadge(date, office)
+	e.IsBadgedIn = false
+	e.Planned = true
+	return e
+}
+
+// NewFlexBadge returns a flex-credit entry for date; label is the office name
+// recorded for flex days (AppSettings.FlexCredit).
+func NewFlexBadge(date time.Time, label string) BadgeEntry {
+	e := NewOfficeBadge(date, label)
+	e.IsFlexCredit = true
+	return e
+}
+
+// ToggleBadge applies the calendar's b/f rule for entry's date: an existing
+// entry of the same kind (office or flex) is removed, an empty date gets
+// entry, and a date holding the other kind is left alone (BadgeConflict) —
+// office and flex days are mutually exclusive. A planned day is replaced by
+// entry (BadgeUpdated).
+func (b *BadgeEntryData) ToggleBadge(entry BadgeEntry) BadgeChange {
+	existing, ok := b.Get(entry.EntryDate)
+	switch {
+	case !ok:
+		b.Add(entry)
+		return BadgeAdded
+	case existing.Planned && !entry.Planned:
+		b.replace(entry)
+		return BadgeUpdated
+	case existing.IsFlexCredit != entry.IsFlexCredit:
+		return BadgeConflict
+	default:
+		b.Remove(entry.EntryDate)
+		return BadgeRemoved
+	}
+}
+
+// CycleCredit applies the calendar's c key for entry's date: an empty date
+// gets entry as a full day, a full day becomes a half day, and a partial day
+// is cleared. An existing entry keeps its kind and office. A planned day
+// is replaced by entry as a full day.
+func (b *BadgeEntryData) CycleCredit(entry BadgeEntry) BadgeChange {
+	existing, ok := b.Get(entry.EntryDate)
+	switch {
+	case !ok:
+		entry.Credit = 0
+		b.Add(entry)
+		return BadgeAdded
+	case existing.Planned:
+		entry.Credit = 0
+		b.replace(entry)
+		return BadgeUpdated
+	case existing.IsPartial():
+		b.Remove(entry.EntryDate)
+		return BadgeRemoved
+	default:
+		for i := range b.entries {
+			if b.entries[i].EntryDate == entry.EntryDate {
+				b.entries[i].Credit = HalfDay
+			}
+		}
+		return BadgeUpdated
+	}
+}
+
+// SetBadge is the idempotent form of ToggleBadge. Without remove it ensures
+// the date holds entry (updating the office and credit of an existing entry
+// of the same kind); with remove it ensures the date holds no entry of
+// entry's kind. A date holding the other kind is never changed. Recording
+// a day that was planned replaces the plan, but planning a day that was
+// recorded is a BadgeConflict; removal only touches an entry that matches
+// entry in being planned or not.
+func (b *BadgeEntryData) SetBadge(entry BadgeEntry, remove bool) BadgeChange {
+	existing, ok := b.Get(entry.EntryDate)
+	switch {
+	case !ok && remove:
+		return BadgeUnchanged
+	case !ok:
+		b.Add(entry)
+		return BadgeAdded
+	case existing.Planned != entry.Planned:
+		switch {
+		case remove:
+			return BadgeUnchanged
+		case entry.Planned:
+			return BadgeConflict
+		}
+		b.replace(entry)
+		return BadgeUpdated
+	case existing.IsFlexCredit != entry.IsFlexCredit:
+		return BadgeConflict
+	case remove:
+		b.Remove(entry.EntryDate)
+		return BadgeRemoved
+	case entry.Office != "" && existing.Office != entry.Office, existing.DayCredit() != entry.DayCredit():
+		for i := range b.entries {
+			if b.entries[i].EntryDate == entry.EntryDate {
+				if entry.Office != "" {
+					b.entries[i].Office = entry.Office
+				}
+				b.entries[i].Credit = entry.Credit
+			}
+		}
+		return BadgeUpdated
+	default:
+		return BadgeUnchanged
+	}
+}
+
+// TogglePlanned applies the calendar's planned-day key for entry's date: an
+// empty date gets entry as a planned office day, a planned day is cleared,
+// and a recorded day is left alone (BadgeConflict).
+func (b *BadgeEntryData) TogglePlanned(entry BadgeEntry) BadgeChange {
+	existing, ok := b.Get(entry.EntryDate)
+	switch {
+	case !ok:
+		entry.IsBadgedIn, entry.IsFlexCredit, entry.Planned = false, false, true
+		b.Add(entry)
+		return BadgeAdded
+	case existing.Planned:
+		b.Remove(entry.EntryDate)
+		return BadgeRemoved
+	default:
+		return BadgeConflict
+	}
+}
+
+// replace swaps the entry on entry's date for entry.
+func (b *BadgeEntryData) replace(entry BadgeEntry) {
+	for i := range b.entries {
+		if b.entries[i].EntryDate == entry.EntryDate {
+			b.entries[i] = entry
+		}
+	}
+}
+
 // Len returns the number of entries.
 func (b *BadgeEntryData) Len() int {
 	return len(b.entries)
@@ -190,3 +190,141 @@ func TestBadgeEntryAllEmpty(t *testing.T) {
 		t.Errorf("expected 0, got %d", len(all))
 	}
 }
+
+func TestToggleBadge(t *testing.T) {
+	b := NewBadgeEntryData()
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	if got := b.ToggleBadge(NewOfficeBadge(day, "HQ")); got != BadgeAdded {
+		t.Fatalf("expected BadgeAdded, got %v", got)
+	}
+	if got := b.ToggleBadge(NewFlexBadge(day, "Flex")); got != BadgeConflict {
+		t.Errorf("flex on an office day should conflict, got %v", got)
+	}
+	if e, _ := b.Get("2025-01-06"); e.IsFlexCredit {
+		t.Error("conflict must leave the office entry in place")
+	}
+	if got := b.ToggleBadge(NewOfficeBadge(day, "HQ")); got != BadgeRemoved {
+		t.Errorf("expected BadgeRemoved, got %v", got)
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected no entries, got %d", b.Len())
+	}
+}
+
+func TestSetBadge(t *testing.T) {
+	b := NewBadgeEntryData()
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	if got := b.SetBadge(NewOfficeBadge(day, "HQ"), false); got != BadgeAdded {
+		t.Fatalf("expected BadgeAdded, got %v", got)
+	}
+	if got := b.SetBadge(NewOfficeBadge(day, "HQ"), false); got != BadgeUnchanged {
+		t.Errorf("second set should be a no-op, got %v", got)
+	}
+	if got := b.SetBadge(NewOfficeBadge(day, "Annex"), false); got != BadgeUpdated {
+		t.Errorf("expected BadgeUpdated, got %v", got)
+	}
+	if e, _ := b.Get("2025-01-06"); e.Office != "Annex" {
+		t.Errorf("expected office Annex, got %q", e.Office)
+	}
+	if got := b.SetBadge(NewFlexBadge(day, "Flex"), true); got != BadgeConflict {
+		t.Errorf("removing flex from an office day should conflict, got %v", got)
+	}
+	if got := b.SetBadge(NewOfficeBadge(day, ""), true); got != BadgeRemoved {
+		t.Errorf("expected BadgeRemoved, got %v", got)
+	}
+	if got := b.SetBadge(NewOfficeBadge(day, ""), true); got != BadgeUnchanged {
+		t.Errorf("removing a missing entry should be a no-op, got %v", got)
+	}
+}
+
+func TestCycleCredit(t *testing.T) {
+	b := NewBadgeEntryData()
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	if got := b.CycleCredit(NewOfficeBadge(day, "HQ")); got != BadgeAdded {
+		t.Fatalf("expected BadgeAdded, got %v", got)
+	}
+	if e, _ := b.Get("2025-01-06"); e.IsPartial() || e.DayCredit() != 1 {
+		t.Errorf("a new entry should be a full day, got %+v", e)
+	}
+	if got := b.CycleCredit(NewOfficeBadge(day, "HQ")); got != BadgeUpdated {
+		t.Errorf("expected BadgeUpdated, got %v", got)
+	}
+	if e, _ := b.Get("2025-01-06"); e.DayCredit() != HalfDay {
+		t.Errorf("expected a half day, got %+v", e)
+	}
+	if got := b.CycleCredit(NewOfficeBadge(day, "HQ")); got != BadgeRemoved || b.Len() != 0 {
+		t.Errorf("expected BadgeRemoved and no entries, got %v and %d", got, b.Len())
+	}
+
+	b.Add(NewFlexBadge(day, "Flex"))
+	b.CycleCredit(NewOfficeBadge(day, "HQ"))
+	if e, _ := b.Get("2025-01-06"); !e.IsFlexCredit || !e.IsPartial() {
+		t.Errorf("a flex credit should stay flex and become a half day, got %+v", e)
+	}
+}
+
+func TestSetBadgeCredit(t *testing.T) {
+	b := NewBadgeEntryData()
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	half := NewOfficeBadge(day, "HQ")
+	half.Credit = HalfDay
+
+	b.SetBadge(NewOfficeBadge(day, "HQ"), false)
+	if got := b.SetBadge(half, false); got != BadgeUpdated {
+		t.Errorf("expected BadgeUpdated, got %v", got)
+	}
+	if got := b.SetBadge(half, false); got != BadgeUnchanged {
+		t.Errorf("second set should be a no-op, got %v", got)
+	}
+	if e, _ := b.Get("2025-01-06"); e.Credit != HalfDay || e.Office != "HQ" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestPlannedBadge(t *testing.T) {
+	b := NewBadgeEntryData()
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	planned := NewPlannedBadge(day, "HQ")
+	if planned.IsBadgedIn || !planned.Planned {
+		t.Fatalf("a planned day isn't badged in: %+v", planned)
+	}
+
+	if got := b.TogglePlanned(NewOfficeBadge(day, "HQ")); got != BadgeAdded {
+		t.Errorf("expected BadgeAdded, got %v", got)
+	}
+	if e, _ := b.Get("2025-01-06"); !e.Planned || e.IsBadgedIn {
+		t.Errorf("TogglePlanned should add a planned day, got %+v", e)
+	}
+	if got := b.ToggleBadge(NewFlexBadge(day, "WFH")); got != BadgeUpdated {
+		t.Errorf("recording a planned day should replace the plan, got %v", got)
+	}
+	if e, _ := b.Get("2025-01-06"); e.Planned || !e.IsFlexCredit || !e.IsBadgedIn {
+		t.Errorf("expected a recorded flex credit, got %+v", e)
+	}
+	if got := b.SetBadge(planned, false); got != BadgeConflict {
+		t.Errorf("planning a recorded day should conflict, got %v", got)
+	}
+	if got := b.TogglePlanned(planned); got != BadgeConflict {
+		t.Errorf("TogglePlanned should leave a recorded day alone, got %v", got)
+	}
+
+	b.Remove("2025-01-06")
+	b.SetBadge(planned, false)
+	if got := b.SetBadge(NewOfficeBadge(day, "HQ"), false); got != BadgeUpdated {
+		t.Errorf("expected the plan converted, got %v", got)
+	}
+	if e, _ := b.Get("2025-01-06"); e.Planned || !e.IsBadgedIn {
+		t.Errorf("expected a recorded office day, got %+v", e)
+	}
+	if got := b.SetBadge(planned, true); got != BadgeUnchanged || !b.Has("2025-01-06") {
+		t.Errorf("removing a plan shouldn't touch a recorded day, got %v", got)
+	}
+	b.Remove("2025-01-06")
+	b.SetBadge(planned, false)
+	if got := b.TogglePlanned(planned); got != BadgeRemoved || b.Has("2025-01-06") {
+		t.Errorf("TogglePlanned should clear a planned day, got %v", got)
+	}
+}
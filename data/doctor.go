@@ -0,0 +1,421 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// flexTimeCanonicalFormat is the format FlexTime.MarshalJSON writes.
+const flexTimeCanonicalFormat = "2006-01-02T15:04:05"
+
+// Issue is a single problem found in a data document.
+type Issue struct {
+	File    string
+	Line    int // 1-based; 0 when the problem isn't tied to one line
+	Message string
+	Fixable bool
+	// Warning marks data that is unusual but may well be right, such as a
+	// badge on a day off the work schedule. It is never fixed and doesn't
+	// count as a problem.
+	Warning bool
+}
+
+func (i Issue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.File, i.Message)
+}
+
+// Diagnose loads every data document in s and reports problems: unparseable
+// or inverted dates, duplicate or out-of-order entries, badges on weekends or
+// holidays, non-canonical date_time values, and overlapping or gapped time
+// periods. A badge on a weekday off the work schedule is only a warning. With fix, the mechanical problems (Fixable issues) are repaired and
+// the affected documents saved; the returned issues still describe what was
+// found, with line numbers referring to the files as they were before fixing.
+func Diagnose(s Store, fix bool) ([]Issue, error) {
+	holidays, err := LoadHolidayDataFromStore(s)
+	if err != nil {
+		return nil, fmt.Errorf("loading holidays: %w", err)
+	}
+
+	var issues []Issue
+	steps := []func(Store, *HolidayData, bool) ([]Issue, error){
+		diagnoseBadges,
+		diagnoseHolidays,
+		diagnoseVacations,
+		diagnoseEvents,
+		diagnoseTimePeriods,
+	}
+	for _, step := range steps {
+		found, err := step(s, holidays, fix)
+		if err != nil {
+			return issues, err
+		}
+		issues = append(issues, found...)
+	}
+	return issues, nil
+}
+
+func diagnoseBadges(s Store, holidays *HolidayData, fix bool) ([]Issue, error) {
+	raw, err := s.Read(badgeDataFilename)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	bd, err := LoadBadgeEntryDataFromStore(s)
+	if err != nil {
+		return []Issue{{File: badgeDataFilename, Message: err.Error()}}, nil
+	}
+
+	// date_time as written, to spot non-canonical formats that FlexTime
+	// silently accepts on load.
+	var rawFile struct {
+		BadgeData []struct {
+			DateTime string `json:"date_time"`
+		} `json:"badge_data"`
+	}
+	_ = json.Unmarshal(raw, &rawFile)
+	lines := jsonKeyLines(raw, "entry_date")
+	holidayMap := holidays.GetHolidayMap()
+
+	var issues []Issue
+	issue := func(i int, fixable bool, format string, args ...interface{}) {
+		issues = append(issues, Issue{File: badgeDataFilename, Line: lineAt(lines, i), Message: fmt.Sprintf(format, args...), Fixable: fixable})
+	}
+	warn := func(i int, format string, args ...interface{}) {
+		issues = append(issues, Issue{File: badgeDataFilename, Line: lineAt(lines, i), Message: fmt.Sprintf(format, args...), Warning: true})
+	}
+
+	seen := map[string]bool{}
+	kept := make([]BadgeEntry, 0, len(bd.entries))
+	for i, e := range bd.entries {
+		d, err := time.Parse(BadgeDateFormat, e.EntryDate)
+		if err != nil {
+			issue(i, false, "unparseable entry_date %q", e.EntryDate)
+			kept = append(kept, e)
+			continue
+		}
+		if seen[e.EntryDate] {
+			issue(i, true, "duplicate badge entry for %s", e.EntryDate)
+			continue
+		}
+		seen[e.EntryDate] = true
+
+		if IsWeekend(d) && !IsWorkday(d) {
+			issue(i, true, "badge entry on a weekend (%s, %s)", e.EntryDate, d.Weekday())
+			continue
+		}
+		if h, ok := holidayMap[e.EntryDate]; ok {
+			issue(i, true, "badge entry on a holiday (%s, %s)", e.EntryDate, h.Name)
+			continue
+		}
+		// Going in on a weekday off the schedule is real attendance, so
+		// it's kept.
+		if !IsWorkday(d) {
+			warn(i, "badge entry on a non-working day (%s, %s)", e.EntryDate, d.Weekday())
+		}
+
+		rawDT := ""
+		if i < len(rawFile.BadgeData) {
+			rawDT = rawFile.BadgeData[i].DateTime
+		}
+		switch {
+		case e.DateTime.IsZero():
+			issue(i, true, "missing date_time for %s", e.EntryDate)
+			e.DateTime = FlexTime{d}
+		case e.DateTime.Format(BadgeDateFormat) != e.EntryDate:
+			issue(i, true, "date_time %q does not match entry_date %s", rawDT, e.EntryDate)
+			e.DateTime = FlexTime{time.Date(d.Year(), d.Month(), d.Day(),
+				e.DateTime.Hour(), e.DateTime.Minute(), e.DateTime.Second(), 0, time.UTC)}
+		case rawDT != "" && rawDT != e.DateTime.Format(flexTimeCanonicalFormat):
+			issue(i, true, "date_time %q is not in canonical YYYY-MM-DDTHH:MM:SS format", rawDT)
+		}
+		kept = append(kept, e)
+	}
+
+	if !sort.SliceIsSorted(kept, func(i, j int) bool { return kept[i].EntryDate < kept[j].EntryDate }) {
+		issues = append(issues, Issue{File: badgeDataFilename, Message: "entries are not sorted by date", Fixable: true})
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].EntryDate < kept[j].EntryDate })
+	}
+
+	if fix && hasFixable(issues) {
+		bd.entries = kept
+		if err := bd.SaveToStore(s); err != nil {
+			return issues, err
+		}
+	}
+	return issues, nil
+}
+
+func diagnoseHolidays(s Store, _ *HolidayData, fix bool) ([]Issue, error) {
+	raw, err := s.Read(holidaysFilename)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	hd, err := LoadHolidayDataFromStore(s)
+	if err != nil {
+		return []Issue{{File: holidaysFilename, Message: err.Error()}}, nil
+	}
+	lines := yamlSeqItemLines(raw, "holidays")
+
+	var issues []Issue
+	seen := map[Holiday]bool{}
+	names := map[string]string{}
+	kept := make([]Holiday, 0, len(hd.holidays))
+	for i, h := range hd.holidays {
+		line := lineAt(lines, i)
+		if _, err := time.Parse(BadgeDateFormat, h.Date); err != nil {
+			issues = append(issues, Issue{File: holidaysFilename, Line: line, Message: fmt.Sprintf("unparseable date %q for %q", h.Date, h.Name)})
+		}
+		if seen[h] {
+			issues = append(issues, Issue{File: holidaysFilename, Line: line, Message: fmt.Sprintf("duplicate holiday %q on %s", h.Name, h.Date), Fixable: true})
+			continue
+		}
+		seen[h] = true
+		if other, ok := names[h.Date]; ok {
+			issues = append(issues, Issue{File: holidaysFilename, Line: line, Message: fmt.Sprintf("%s has two holidays: %q and %q", h.Date, other, h.Name)})
+		}
+		names[h.Date] = h.Name
+		kept = append(kept, h)
+	}
+
+	if !sort.SliceIsSorted(kept, func(i, j int) bool { return kept[i].Date < kept[j].Date }) {
+		issues = append(issues, Issue{File: holidaysFilename, Message: "holidays are not sorted by date", Fixable: true})
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].Date < kept[j].Date })
+	}
+
+	if fix && hasFixable(issues) {
+		hd.holidays = kept
+		if err := hd.SaveToStore(s); err != nil {
+			return issues, err
+		}
+	}
+	return issues, nil
+}
+
+func diagnoseVacations(s Store, _ *HolidayData, fix bool) ([]Issue, error) {
+	raw, err := s.Read(vacationsFilename)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	vd, err := LoadVacationDataFromStore(s)
+	if err != nil {
+		return []Issue{{File: vacationsFilename, Message: err.Error()}}, nil
+	}
+	lines := yamlSeqItemLines(raw, "vacations")
+
+	var issues []Issue
+	seen := map[Vacation]bool{}
+	kept := make([]Vacation, 0, len(vd.vacations))
+	for i, v := range vd.vacations {
+		line := lineAt(lines, i)
+		start, serr := time.Parse(BadgeDateFormat, v.StartDate)
+		end, eerr := time.Parse(BadgeDateFormat, v.EndDate)
+		if serr != nil {
+			issues = append(issues, Issue{File: vacationsFilename, Line: line, Message: fmt.Sprintf("unparseable start_date %q for %q", v.StartDate, v.Destination)})
+		}
+		if eerr != nil {
+			issues = append(issues, Issue{File: vacationsFilename, Line: line, Message: fmt.Sprintf("unparseable end_date %q for %q", v.EndDate, v.Destination)})
+		}
+		if serr == nil && eerr == nil && end.Before(start) {
+			issues = append(issues, Issue{File: vacationsFilename, Line: line, Message: fmt.Sprintf("end_date %s is before start_date %s for %q", v.EndDate, v.StartDate, v.Destination)})
+		}
+		if !v.Leave().Valid() {
+			issues = append(issues, Issue{File: vacationsFilename, Line: line, Message: fmt.Sprintf("unknown leave type %q for %q (expected %s)", v.Type, v.Destination, leaveTypeList())})
+		}
+		if seen[v] {
+			issues = append(issues, Issue{File: vacationsFilename, Line: line, Message: fmt.Sprintf("duplicate vacation %q (%s – %s)", v.Destination, v.StartDate, v.EndDate), Fixable: true})
+			continue
+		}
+		seen[v] = true
+		kept = append(kept, v)
+	}
+
+	if !sort.SliceIsSorted(kept, func(i, j int) bool { return kept[i].StartDate < kept[j].StartDate }) {
+		issues = append(issues, Issue{File: vacationsFilename, Message: "vacations are not sorted by start_date", Fixable: true})
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].StartDate < kept[j].StartDate })
+	}
+
+	if fix && hasFixable(issues) {
+		vd.vacations = kept
+		if err := vd.SaveToStore(s); err != nil {
+			return issues, err
+		}
+	}
+	return issues, nil
+}
+
+func diagnoseEvents(s Store, _ *HolidayData, fix bool) ([]Issue, error) {
+	raw, err := s.Read(eventsFilename)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	ed, err := LoadEventDataFromStore(s)
+	if err != nil {
+		return []Issue{{File: eventsFilename, Message: err.Error()}}, nil
+	}
+	lines := jsonKeyLines(raw, "date")
+
+	var issues []Issue
+	seen := map[Event]bool{}
+	kept := make([]Event, 0, len(ed.events))
+	for i, ev := range ed.events {
+		line := lineAt(lines, i)
+		if _, err := time.Parse(BadgeDateFormat, ev.Date); err != nil {
+			issues = append(issues, Issue{File: eventsFilename, Line: line, Message: fmt.Sprintf("unparseable date %q for %q", ev.Date, ev.Description)})
+		}
+		if seen[ev] {
+			issues = append(issues, Issue{File: eventsFilename, Line: line, Message: fmt.Sprintf("duplicate event %q on %s", ev.Description, ev.Date), Fixable: true})
+			continue
+		}
+		seen[ev] = true
+		kept = append(kept, ev)
+	}
+
+	if !sort.SliceIsSorted(kept, func(i, j int) bool { return kept[i].Date < kept[j].Date }) {
+		issues = append(issues, Issue{File: eventsFilename, Message: "events are not sorted by date", Fixable: true})
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].Date < kept[j].Date })
+	}
+
+	if fix && hasFixable(issues) {
+		ed.events = kept
+		if err := ed.SaveToStore(s); err != nil {
+			return issues, err
+		}
+	}
+	return issues, nil
+}
+
+func diagnoseTimePeriods(s Store, _ *HolidayData, _ bool) ([]Issue, error) {
+	settings, err := LoadAppSettingsFromStore(s)
+	if err != nil {
+		return []Issue{{File: settingsFilename, Message: err.Error()}}, nil
+	}
+
+	var issues []Issue
+	seenFile := map[string]bool{}
+	for _, name := range settings.TimePeriods {
+		if seenFile[name] {
+			issues = append(issues, Issue{File: settingsFilename, Message: fmt.Sprintf("time period file %s is listed twice", name)})
+			continue
+		}
+		seenFile[name] = true
+
+		raw, err := s.Read(name)
+		if err != nil {
+			return issues, err
+		}
+		if raw == nil {
+			issues = append(issues, Issue{File: settingsFilename, Message: fmt.Sprintf("time period file %s does not exist", name)})
+			continue
+		}
+		td, err := LoadTimePeriodDataFromStore(s, name)
+		if err != nil {
+			issues = append(issues, Issue{File: name, Message: err.Error()})
+			continue
+		}
+		lines := yamlSeqItemLines(raw, "timeperiods")
+
+		type indexed struct {
+			tp   TimePeriod
+			line int
+		}
+		periods := make([]indexed, len(td.periods))
+		keys := map[string]bool{}
+		for i, tp := range td.periods {
+			periods[i] = indexed{tp, lineAt(lines, i)}
+			if keys[tp.Key] {
+				issues = append(issues, Issue{File: name, Line: periods[i].line, Message: fmt.Sprintf("duplicate key %s", tp.Key)})
+			}
+			keys[tp.Key] = true
+			if tp.EndDate.Before(tp.StartDate) {
+				issues = append(issues, Issue{File: name, Line: periods[i].line, Message: fmt.Sprintf("%s ends (%s) before it starts (%s)", tp.Key, tp.EndDateRaw, tp.StartDateRaw)})
+			}
+		}
+
+		sort.SliceStable(periods, func(i, j int) bool { return periods[i].tp.StartDate.Before(periods[j].tp.StartDate) })
+		for i := 1; i < len(periods); i++ {
+			prev, cur := periods[i-1], periods[i]
+			switch {
+			case !cur.tp.StartDate.After(prev.tp.EndDate):
+				issues = append(issues, Issue{File: name, Line: cur.line, Message: fmt.Sprintf("%s overlaps %s (%s – %s)", cur.tp.Key, prev.tp.Key, cur.tp.StartDateRaw, prev.tp.EndDateRaw)})
+			case cur.tp.StartDate.After(prev.tp.EndDate.AddDate(0, 0, 1)):
+				gapStart := prev.tp.EndDate.AddDate(0, 0, 1).Format(BadgeDateFormat)
+				gapEnd := cur.tp.StartDate.AddDate(0, 0, -1).Format(BadgeDateFormat)
+				issues = append(issues, Issue{File: name, Line: cur.line, Message: fmt.Sprintf("gap between %s and %s (%s – %s not covered)", prev.tp.Key, cur.tp.Key, gapStart, gapEnd)})
+			}
+		}
+	}
+	return issues, nil
+}
+
+func hasFixable(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Fixable {
+			return true
+		}
+	}
+	return false
+}
+
+func lineAt(lines []int, i int) int {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return 0
+}
+
+// jsonKeyLines returns the 1-based line number of each occurrence of the
+// object key "key" in raw JSON, in document order.
+func jsonKeyLines(raw []byte, key string) []int {
+	needle := []byte(`"` + key + `"`)
+	var lines []int
+	line := 1
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '\n' {
+			line++
+			continue
+		}
+		if raw[i] != '"' || !bytes.HasPrefix(raw[i:], needle) {
+			continue
+		}
+		j := i + len(needle)
+		for j < len(raw) && (raw[j] == ' ' || raw[j] == '\t') {
+			j++
+		}
+		if j < len(raw) && raw[j] == ':' {
+			lines = append(lines, line)
+		}
+		i += len(needle) - 1
+	}
+	return lines
+}
+
+// yamlSeqItemLines returns the line number of each item in the top-level
+// sequence under key.
+func yamlSeqItemLines(raw []byte, key string) []int {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != key || root.Content[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		var lines []int
+		for _, item := range root.Content[i+1].Content {
+			lines = append(lines, item.Line)
+		}
+		return lines
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package data
+
+import (
+	"strings"
+	"testing"
+)
+
+func writeDoc(t *testing.T, s Store, name, body string) {
+	t.Helper()
+	if err := s.Write(name, []byte(body)); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func findIssue(issues []Issue, file, substr string) *Issue {
+	for i := range issues {
+		if issues[i].File == file && strings.Contains(issues[i].Message, substr) {
+			return &issues[i]
+		}
+	}
+	return nil
+}
+
+func doctorStore(t *testing.T) Store {
+	t.Helper()
+	s := NewDirStore(t.TempDir())
+	writeDoc(t, s, settingsFilename, "time_periods:\n- \"periods.yaml\"\n")
+	writeDoc(t, s, holidaysFilename, "holidays:\n- name: \"New Year\"\n  date: \"2025-01-01\"\n")
+	writeDoc(t, s, "periods.yaml", "timeperiods:\n- key: \"A\"\n  name: \"A\"\n  start_date: \"2025-01-01\"\n  end_date: \"2025-03-31\"\n")
+	return s
+}
+
+func TestDiagnoseCleanData(t *testing.T) {
+	s := doctorStore(t)
+	issues, err := Diagnose(s, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+}
+
+func TestDiagnoseBadgesWithLines(t *testing.T) {
+	s := doctorStore(t)
+	writeDoc(t, s, badgeDataFilename, `{
+  "badge_data": [
+    {
+      "entry_date": "2025-01-07",
+      "date_time": "2025-01-07T09:00:00Z",
+      "office": "HQ",
+      "is_badged_in": true
+    },
+    {
+      "entry_date": "2025-01-06",
+      "date_time": "2025-01-06T09:00:00",
+      "office": "HQ",
+      "is_badged_in": true
+    },
+    {
+      "entry_date": "2025-01-06",
+      "date_time": "2025-01-06T10:00:00",
+      "office": "HQ",
+      "is_badged_in": true
+    },
+    {
+      "entry_date": "2025-01-04",
+      "date_time": "2025-01-04T09:00:00",
+      "office": "HQ",
+      "is_badged_in": true
+    },
+    {
+      "entry_date": "2025-01-01",
+      "date_time": "2025-01-01T09:00:00",
+      "office": "HQ",
+      "is_badged_in": true
+    },
+    {
+      "entry_date": "Jan 8",
+      "date_time": "2025-01-08T09:00:00",
+      "office": "HQ",
+      "is_badged_in": true
+    }
+  ]
+}
+`)
+	issues, err := Diagnose(s, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		substr  string
+		line    int
+		fixable bool
+	}{
+		{"not in canonical", 4, true},
+		{"duplicate badge entry for 2025-01-06", 16, true},
+		{"weekend", 22, true},
+		{"holiday (2025-01-01, New Year)", 28, true},
+		{`unparseable entry_date "Jan 8"`, 34, false},
+		{"not sorted", 0, true},
+	}
+	for _, c := range cases {
+		is := findIssue(issues, badgeDataFilename, c.substr)
+		if is == nil {
+			t.Errorf("expected issue %q, got %v", c.substr, issues)
+			continue
+		}
+		if is.Line != c.line || is.Fixable != c.fixable {
+			t.Errorf("%q: got line %d fixable %v, want line %d fixable %v", c.substr, is.Line, is.Fixable, c.line, c.fixable)
+		}
+	}
+}
+
+func TestDiagnoseFixBadges(t *testing.T) {
+	s := doctorStore(t)
+	writeDoc(t, s, badgeDataFilename, `{"badge_data": [
+  {"entry_date": "2025-01-07", "date_time": "2025-01-07T09:00:00Z"},
+  {"entry_date": "2025-01-06", "date_time": "2025-01-06T09:00:00"},
+  {"entry_date": "2025-01-06", "date_time": "2025-01-06T10:00:00"},
+  {"entry_date": "2025-01-04", "date_time": "2025-01-04T09:00:00"},
+  {"entry_date": "2025-01-08", "date_time": "2025-01-09T08:30:00"},
+  {"entry_date": "2025-01-09"}
+]}`)
+	if _, err := Diagnose(s, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bd, err := LoadBadgeEntryDataFromStore(s)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	got := bd.All()
+	want := []string{"2025-01-06", "2025-01-07", "2025-01-08", "2025-01-09"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(want), len(got), got)
+	}
+	for i, e := range got {
+		if e.EntryDate != want[i] {
+			t.Errorf("entry %d: got %s, want %s", i, e.EntryDate, want[i])
+		}
+	}
+	if got[0].DateTime.Hour() != 9 {
+		t.Errorf("duplicate should keep first entry, got %v", got[0].DateTime)
+	}
+	if d := got[2].DateTime.Format("2006-01-02T15:04:05"); d != "2025-01-08T08:30:00" {
+		t.Errorf("mismatched date_time should take entry_date, got %s", d)
+	}
+	if d := got[3].DateTime.Format(BadgeDateFormat); d != "2025-01-09" {
+		t.Errorf("missing date_time should be filled from entry_date, got %s", d)
+	}
+
+	issues, _ := Diagnose(s, false)
+	if len(issues) != 0 {
+		t.Errorf("expected clean data after fix, got %v", issues)
+	}
+}
+
+func TestDiagnoseBadgeOnNonWorkingWeekday(t *testing.T) {
+	ws, _ := ParseWorkSchedule([]WorkWeek{{Days: []string{"mon", "tue", "wed", "thu"}}})
+	SetWorkSchedule(ws)
+	defer SetWorkSchedule(nil)
+
+	s := doctorStore(t)
+	writeDoc(t, s, badgeDataFilename, `{"badge_data": [
+  {"entry_date": "2025-01-10", "date_time": "2025-01-10T09:00:00", "is_badged_in": true}
+]}`)
+	issues, err := Diagnose(s, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	is := findIssue(issues, badgeDataFilename, "non-working day (2025-01-10, Friday)")
+	if is == nil || !is.Warning || is.Fixable {
+		t.Fatalf("expected a Friday badge to be a warning under a 4-day week, got %v", issues)
+	}
+
+	if _, err := Diagnose(s, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bd, err := LoadBadgeEntryDataFromStore(s)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, ok := bd.Get("2025-01-10"); !ok {
+		t.Errorf("--fix should keep a badge on a non-working weekday, got %+v", bd.All())
+	}
+}
+
+func TestDiagnoseVacationsAndHolidays(t *testing.T) {
+	s := doctorStore(t)
+	writeDoc(t, s, holidaysFilename, `holidays:
+- name: "B"
+  date: "2025-07-04"
+- name: "A"
+  date: "2025-01-01"
+- name: "A"
+  date: "2025-01-01"
+`)
+	writeDoc(t, s, vacationsFilename, `vacations:
+- destination: "Beach"
+  start_date: "2025-06-10"
+  end_date: "2025-06-01"
+`)
+	issues, err := Diagnose(s, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if is := findIssue(issues, vacationsFilename, "before start_date"); is == nil || is.Line != 2 || is.Fixable {
+		t.Errorf("expected unfixable inverted vacation at line 2, got %v", issues)
+	}
+	if is := findIssue(issues, holidaysFilename, "duplicate holiday"); is == nil || is.Line != 6 {
+		t.Errorf("expected duplicate holiday at line 6, got %v", issues)
+	}
+
+	hd, _ := LoadHolidayDataFromStore(s)
+	all := hd.All()
+	if len(all) != 2 || all[0].Date != "2025-01-01" {
+		t.Errorf("expected deduped, sorted holidays, got %+v", all)
+	}
+}
+
+func TestDiagnoseTimePeriodOverlapAndGap(t *testing.T) {
+	s := doctorStore(t)
+	writeDoc(t, s, "periods.yaml", `timeperiods:
+- key: "Q1"
+  name: "Q1"
+  start_date: "2025-01-01"
+  end_date: "2025-03-31"
+- key: "Q2"
+  name: "Q2"
+  start_date: "2025-03-15"
+  end_date: "2025-06-30"
+- key: "Q4"
+  name: "Q4"
+  start_date: "2025-10-01"
+  end_date: "2025-12-31"
+`)
+	issues, err := Diagnose(s, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if is := findIssue(issues, "periods.yaml", "Q2 overlaps Q1"); is == nil || is.Line != 6 {
+		t.Errorf("expected overlap at line 6, got %v", issues)
+	}
+	if is := findIssue(issues, "periods.yaml", "2025-07-01 – 2025-09-30 not covered"); is == nil || is.Line != 10 {
+		t.Errorf("expected gap at line 10, got %v", issues)
+	}
+}
+
+func TestJSONKeyLinesExactKey(t *testing.T) {
+	raw := []byte("{\n \"date\": 1,\n \"date_time\": 2,\n \"x\": \"date\"\n}")
+	lines := jsonKeyLines(raw, "date")
+	if len(lines) != 1 || lines[0] != 2 {
+		t.Errorf("expected [2], got %v", lines)
+	}
+}
@@ -1,7 +1,10 @@
 package data
 
 import (
+	"fmt"
 	"sort"
+	"strings"
+	"time"
 )
 
 const eventsFilename = "events.json"
@@ -10,10 +13,23 @@ const eventsFilename = "events.json"
 type Event struct {
 	Date        string `json:"date"`
 	Description string `json:"description"`
+	UID         string `json:"uid,omitempty"` // iCalendar UID, set when imported
+}
+
+// Validate checks that e has a valid date and a description.
+func (e Event) Validate() error {
+	if _, err := time.Parse(BadgeDateFormat, e.Date); err != nil {
+		return fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", e.Date)
+	}
+	if strings.TrimSpace(e.Description) == "" {
+		return fmt.Errorf("event description is required")
+	}
+	return nil
 }
 
 type eventDataFile struct {
-	Events []Event `json:"events"`
+	SchemaVersion int     `json:"schema_version"`
+	Events        []Event `json:"events"`
 }
 
 // EventData is the in-memory container for events.
@@ -26,15 +42,20 @@ func NewEventData() *EventData {
 	return &EventData{}
 }
 
-// LoadEventData reads event data from the global data directory.
+// LoadEventData reads event data from the global store.
 func LoadEventData() (*EventData, error) {
-	return LoadEventDataFrom(GetDataDir())
+	return LoadEventDataFromStore(GetStore())
 }
 
 // LoadEventDataFrom reads event data from the specified directory.
 func LoadEventDataFrom(dir string) (*EventData, error) {
+	return LoadEventDataFromStore(NewDirStore(dir))
+}
+
+// LoadEventDataFromStore reads event data from the given store.
+func LoadEventDataFromStore(s Store) (*EventData, error) {
 	var file eventDataFile
-	if err := LoadJSONFrom(dir, eventsFilename, &file); err != nil {
+	if err := loadVersioned(s, eventsFilename, DocEvents, &file); err != nil {
 		return nil, err
 	}
 	if file.Events == nil {
@@ -43,21 +64,44 @@ func LoadEventDataFrom(dir string) (*EventData, error) {
 	return &EventData{events: file.Events}, nil
 }
 
-// Save writes event data to the global data directory.
+// Save writes event data to the global store.
 func (e *EventData) Save() error {
-	return e.SaveTo(GetDataDir())
+	return e.SaveToStore(GetStore())
 }
 
 // SaveTo writes event data to the specified directory.
 func (e *EventData) SaveTo(dir string) error {
-	file := eventDataFile{Events: e.events}
-	return SaveJSONTo(dir, eventsFilename, &file)
+	return e.SaveToStore(NewDirStore(dir))
+}
+
+// SaveToStore writes event data to the given store.
+func (e *EventData) SaveToStore(s Store) error {
+	return saveDocument(s, eventsFilename, e.fileDocument())
+}
+
+func (e *EventData) fileDocument() interface{} {
+	return &eventDataFile{SchemaVersion: CurrentSchemaVersion, Events: e.events}
 }
 
 // Add appends an event and sorts by date.
 func (e *EventData) Add(event Event) {
 	e.events = append(e.events, event)
-	sort.Slice(e.events, func(i, j int) bool {
+	e.sort()
+}
+
+// Update replaces the event at index i (as ordered by All) and re-sorts.
+func (e *EventData) Update(i int, event Event) {
+	e.events[i] = event
+	e.sort()
+}
+
+// RemoveAt deletes the event at index i (as ordered by All).
+func (e *EventData) RemoveAt(i int) {
+	e.events = append(e.events[:i], e.events[i+1:]...)
+}
+
+func (e *EventData) sort() {
+	sort.SliceStable(e.events, func(i, j int) bool {
 		return e.events[i].Date < e.events[j].Date
 	})
 }
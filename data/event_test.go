@@ -124,3 +124,17 @@ func TestEventAll(t *testing.T) {
 		t.Error("All() should return a copy")
 	}
 }
+
+func TestEventUpdateResorts(t *testing.T) {
+	e := NewEventData()
+	e.Add(Event{Date: "2025-01-01", Description: "A"})
+	e.Add(Event{Date: "2025-02-01", Description: "B"})
+	e.Update(0, Event{Date: "2025-03-01", Description: "A"})
+	all := e.All()
+	if all[0].Description != "B" || all[1].Date != "2025-03-01" {
+		t.Errorf("expected re-sorted events, got %+v", all)
+	}
+	if err := (Event{Date: "2025-03-01", Description: " "}).Validate(); err == nil {
+		t.Error("expected error for blank description")
+	}
+}
@@ -1,15 +1,34 @@
 package data
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 const holidaysFilename = "holidays.yaml"
 
 // Holiday represents a single holiday.
 type Holiday struct {
 	Name string `yaml:"name" json:"name"`
 	Date string `yaml:"date" json:"date"`
+	UID  string `yaml:"uid,omitempty" json:"uid,omitempty"` // iCalendar UID, set when imported
+}
+
+// Validate checks that h has a name and a valid date.
+func (h Holiday) Validate() error {
+	if strings.TrimSpace(h.Name) == "" {
+		return fmt.Errorf("holiday name is required")
+	}
+	if _, err := time.Parse(BadgeDateFormat, h.Date); err != nil {
+		return fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", h.Date)
+	}
+	return nil
 }
 
 type holidayDataFile struct {
-	Holidays []Holiday `yaml:"holidays"`
+	SchemaVersion int       `yaml:"schema_version"`
+	Holidays      []Holiday `yaml:"holidays"`
 }
 
 // HolidayData is the in-memory container for holidays.
@@ -22,15 +41,20 @@ func NewHolidayData() *HolidayData {
 	return &HolidayData{}
 }
 
-// LoadHolidayData reads holiday data from the global data directory.
+// LoadHolidayData reads holiday data from the global store.
 func LoadHolidayData() (*HolidayData, error) {
-	return LoadHolidayDataFrom(GetDataDir())
+	return LoadHolidayDataFromStore(GetStore())
 }
 
 // LoadHolidayDataFrom reads holiday data from the specified directory.
 func LoadHolidayDataFrom(dir string) (*HolidayData, error) {
+	return LoadHolidayDataFromStore(NewDirStore(dir))
+}
+
+// LoadHolidayDataFromStore reads holiday data from the given store.
+func LoadHolidayDataFromStore(s Store) (*HolidayData, error) {
 	var file holidayDataFile
-	if err := LoadYAMLFrom(dir, holidaysFilename, &file); err != nil {
+	if err := loadVersioned(s, holidaysFilename, DocHolidays, &file); err != nil {
 		return nil, err
 	}
 	if file.Holidays == nil {
@@ -39,15 +63,23 @@ func LoadHolidayDataFrom(dir string) (*HolidayData, error) {
 	return &HolidayData{holidays: file.Holidays}, nil
 }
 
-// Save writes holiday data to the global data directory.
+// Save writes holiday data to the global store.
 func (h *HolidayData) Save() error {
-	return h.SaveTo(GetDataDir())
+	return h.SaveToStore(GetStore())
 }
 
 // SaveTo writes holiday data to the specified directory.
 func (h *HolidayData) SaveTo(dir string) error {
-	file := holidayDataFile{Holidays: h.holidays}
-	return SaveYAMLTo(dir, holidaysFilename, &file)
+	return h.SaveToStore(NewDirStore(dir))
+}
+
+// SaveToStore writes holiday data to the given store.
+func (h *HolidayData) SaveToStore(s Store) error {
+	return saveDocument(s, holidaysFilename, h.fileDocument())
+}
+
+func (h *HolidayData) fileDocument() interface{} {
+	return &holidayDataFile{SchemaVersion: CurrentSchemaVersion, Holidays: h.holidays}
 }
 
 // Add appends a holiday.
@@ -55,6 +87,16 @@ func (h *HolidayData) Add(holiday Holiday) {
 	h.holidays = append(h.holidays, holiday)
 }
 
+// Update replaces the holiday at index i (as ordered by All).
+func (h *HolidayData) Update(i int, holiday Holiday) {
+	h.holidays[i] = holiday
+}
+
+// RemoveAt deletes the holiday at index i (as ordered by All).
+func (h *HolidayData) RemoveAt(i int) {
+	h.holidays = append(h.holidays[:i], h.holidays[i+1:]...)
+}
+
 // All returns a copy of all holidays.
 func (h *HolidayData) All() []Holiday {
 	result := make([]Holiday, len(h.holidays))
@@ -0,0 +1,305 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Observed rules for HolidayRule.Observed: where a holiday falling on a
+// Saturday or Sunday is observed.
+const (
+	ObserveNone    = ""        // on its date, weekend or not
+	ObserveNearest = "nearest" // Saturday moves to Friday, Sunday to Monday (US)
+	ObserveNext    = "next"    // the next weekday that isn't already a holiday (UK, Canada)
+)
+
+// HolidayRule describes the date of a recurring holiday in one of three ways:
+//
+//   - a fixed date: Month and Day;
+//   - the Nth Weekday of Month, counted from its start (Nth 1 to 5) or end
+//     (Nth -1 to -5). With Day set, counting starts at Day instead, e.g.
+//     nth -1 and day 24 is the last Weekday on or before the 24th;
+//   - Easter-relative: Easter days after Easter Sunday, e.g. -2 for Good
+//     Friday.
+type HolidayRule struct {
+	Name     string `yaml:"name"`
+	Month    int    `yaml:"month,omitempty"`
+	Day      int    `yaml:"day,omitempty"`
+	Weekday  string `yaml:"weekday,omitempty"`
+	Nth      int    `yaml:"nth,omitempty"`
+	Easter   *int   `yaml:"easter,omitempty"`
+	Observed string `yaml:"observed,omitempty"` // ObserveNone, ObserveNearest or ObserveNext
+	From     int    `yaml:"from,omitempty"`     // first year the holiday applies; 0 for always
+	Until    int    `yaml:"until,omitempty"`    // last year the holiday applies; 0 for always
+}
+
+// Validate checks that r describes exactly one kind of date.
+func (r HolidayRule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch r.Observed {
+	case ObserveNone, ObserveNearest, ObserveNext:
+	default:
+		return fmt.Errorf("%s: unknown observed rule %q (expected nearest or next)", r.Name, r.Observed)
+	}
+	if r.From != 0 && r.Until != 0 && r.Until < r.From {
+		return fmt.Errorf("%s: until %d is before from %d", r.Name, r.Until, r.From)
+	}
+	if r.Easter != nil {
+		if r.Month != 0 || r.Day != 0 || r.Weekday != "" || r.Nth != 0 {
+			return fmt.Errorf("%s: an easter rule can't also set month, day, weekday or nth", r.Name)
+		}
+		return nil
+	}
+	if r.Month < 1 || r.Month > 12 {
+		return fmt.Errorf("%s: month must be 1 to 12", r.Name)
+	}
+	if r.Day < 0 || r.Day > daysIn(time.Month(r.Month), 2024) {
+		return fmt.Errorf("%s: day %d isn't in month %d", r.Name, r.Day, r.Month)
+	}
+	if r.Weekday == "" {
+		if r.Nth != 0 {
+			return fmt.Errorf("%s: nth needs a weekday", r.Name)
+		}
+		if r.Day == 0 {
+			return fmt.Errorf("%s: day is required without a weekday", r.Name)
+		}
+		return nil
+	}
+	if _, err := ParseWeekday(r.Weekday); err != nil {
+		return fmt.Errorf("%s: %w", r.Name, err)
+	}
+	if r.Nth == 0 || r.Nth < -5 || r.Nth > 5 {
+		return fmt.Errorf("%s: nth must be 1 to 5, or -1 to -5 counting back", r.Name)
+	}
+	return nil
+}
+
+// Date returns the date of the holiday in year, before any weekend shift.
+// It reports false if the rule doesn't apply that year or the date doesn't
+// exist, e.g. a fifth Monday or February 29 in a common year.
+func (r HolidayRule) Date(year int) (time.Time, bool) {
+	if (r.From != 0 && year < r.From) || (r.Until != 0 && year > r.Until) {
+		return time.Time{}, false
+	}
+	if r.Easter != nil {
+		return EasterSunday(year).AddDate(0, 0, *r.Easter), true
+	}
+	month := time.Month(r.Month)
+	if r.Weekday == "" {
+		if r.Day > daysIn(month, year) {
+			return time.Time{}, false
+		}
+		return time.Date(year, month, r.Day, 0, 0, 0, 0, time.UTC), true
+	}
+
+	wd, err := ParseWeekday(r.Weekday)
+	if err != nil {
+		return time.Time{}, false
+	}
+	var d time.Time
+	if r.Nth > 0 {
+		day := max(r.Day, 1)
+		d = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		d = d.AddDate(0, 0, (int(wd)-int(d.Weekday())+7)%7+7*(r.Nth-1))
+	} else {
+		day := r.Day
+		if day == 0 {
+			day = daysIn(month, year)
+		}
+		d = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		d = d.AddDate(0, 0, -((int(d.Weekday())-int(wd)+7)%7 + 7*(-r.Nth-1)))
+	}
+	if d.Month() != month {
+		return time.Time{}, false
+	}
+	return d, true
+}
+
+// HolidaySet is a named list of holiday rules, such as a country's public
+// holidays.
+type HolidaySet struct {
+	Name  string        `yaml:"name"`
+	Rules []HolidayRule `yaml:"rules"`
+}
+
+// Validate checks every rule in s.
+func (s HolidaySet) Validate() error {
+	if len(s.Rules) == 0 {
+		return fmt.Errorf("holiday set %q has no rules", s.Name)
+	}
+	for i, r := range s.Rules {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ParseHolidaySet parses and validates a YAML holiday rules file.
+func ParseHolidaySet(raw []byte) (HolidaySet, error) {
+	var s HolidaySet
+	if err := yaml.Unmarshal(raw, &s); err != nil {
+		return HolidaySet{}, err
+	}
+	if err := s.Validate(); err != nil {
+		return HolidaySet{}, err
+	}
+	return s, nil
+}
+
+// Generate returns the holidays s observes in year, sorted by date. A
+// holiday shifted off a weekend is listed on the day it is observed, named
+// "<name> (observed)"; one observed in a neighbouring year, like New Year's
+// Day moved back to December 31, belongs to the year it is observed in.
+func (s HolidaySet) Generate(year int) []Holiday {
+	type occurrence struct {
+		rule HolidayRule
+		date time.Time
+	}
+	var occs []occurrence
+	taken := map[time.Time]bool{}
+	for y := year - 1; y <= year+1; y++ {
+		for _, r := range s.Rules {
+			d, ok := r.Date(y)
+			if !ok {
+				continue
+			}
+			occs = append(occs, occurrence{r, d})
+			if !isWeekend(d) {
+				taken[d] = true
+			}
+		}
+	}
+	sort.SliceStable(occs, func(i, j int) bool { return occs[i].date.Before(occs[j].date) })
+
+	var result []Holiday
+	for _, o := range occs {
+		name, d := o.rule.Name, o.date
+		if isWeekend(d) && o.rule.Observed != ObserveNone {
+			name += " (observed)"
+			switch {
+			case o.rule.Observed == ObserveNearest && d.Weekday() == time.Saturday:
+				d = d.AddDate(0, 0, -1)
+			case o.rule.Observed == ObserveNearest:
+				d = d.AddDate(0, 0, 1)
+			default:
+				for isWeekend(d) || taken[d] {
+					d = d.AddDate(0, 0, 1)
+				}
+				taken[d] = true
+			}
+		}
+		if d.Year() == year {
+			result = append(result, Holiday{Name: name, Date: d.Format(BadgeDateFormat)})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
+
+// EasterSunday returns the date of Easter Sunday in year (Gregorian
+// calendar), by the anonymous Gregorian algorithm.
+func EasterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// HolidaySets lists the built-in holiday sets for `rto holidays generate`.
+var HolidaySets = map[string]HolidaySet{
+	"us-federal": {Name: "US federal holidays", Rules: []HolidayRule{
+		{Name: "New Year's Day", Month: 1, Day: 1, Observed: ObserveNearest},
+		{Name: "MLK Day", Month: 1, Weekday: "mon", Nth: 3},
+		{Name: "Presidents' Day", Month: 2, Weekday: "mon", Nth: 3},
+		{Name: "Memorial Day", Month: 5, Weekday: "mon", Nth: -1},
+		{Name: "Juneteenth", Month: 6, Day: 19, Observed: ObserveNearest, From: 2021},
+		{Name: "Independence Day", Month: 7, Day: 4, Observed: ObserveNearest},
+		{Name: "Labor Day", Month: 9, Weekday: "mon", Nth: 1},
+		{Name: "Columbus Day", Month: 10, Weekday: "mon", Nth: 2},
+		{Name: "Veterans Day", Month: 11, Day: 11, Observed: ObserveNearest},
+		{Name: "Thanksgiving Day", Month: 11, Weekday: "thu", Nth: 4},
+		{Name: "Christmas Day", Month: 12, Day: 25, Observed: ObserveNearest},
+	}},
+	"uk": {Name: "UK bank holidays (England and Wales)", Rules: []HolidayRule{
+		{Name: "New Year's Day", Month: 1, Day: 1, Observed: ObserveNext},
+		{Name: "Good Friday", Easter: easterOffset(-2)},
+		{Name: "Easter Monday", Easter: easterOffset(1)},
+		{Name: "Early May bank holiday", Month: 5, Weekday: "mon", Nth: 1},
+		{Name: "Spring bank holiday", Month: 5, Weekday: "mon", Nth: -1},
+		{Name: "Summer bank holiday", Month: 8, Weekday: "mon", Nth: -1},
+		{Name: "Christmas Day", Month: 12, Day: 25, Observed: ObserveNext},
+		{Name: "Boxing Day", Month: 12, Day: 26, Observed: ObserveNext},
+	}},
+	"canada": {Name: "Canada federal statutory holidays", Rules: []HolidayRule{
+		{Name: "New Year's Day", Month: 1, Day: 1, Observed: ObserveNext},
+		{Name: "Good Friday", Easter: easterOffset(-2)},
+		{Name: "Victoria Day", Month: 5, Day: 24, Weekday: "mon", Nth: -1},
+		{Name: "Canada Day", Month: 7, Day: 1, Observed: ObserveNext},
+		{Name: "Labour Day", Month: 9, Weekday: "mon", Nth: 1},
+		{Name: "National Day for Truth and Reconciliation", Month: 9, Day: 30, Observed: ObserveNext, From: 2021},
+		{Name: "Thanksgiving", Month: 10, Weekday: "mon", Nth: 2},
+		{Name: "Remembrance Day", Month: 11, Day: 11, Observed: ObserveNext},
+		{Name: "Christmas Day", Month: 12, Day: 25, Observed: ObserveNext},
+		{Name: "Boxing Day", Month: 12, Day: 26, Observed: ObserveNext},
+	}},
+	"germany": {Name: "Germany national holidays", Rules: []HolidayRule{
+		{Name: "Neujahr", Month: 1, Day: 1},
+		{Name: "Karfreitag", Easter: easterOffset(-2)},
+		{Name: "Ostermontag", Easter: easterOffset(1)},
+		{Name: "Tag der Arbeit", Month: 5, Day: 1},
+		{Name: "Christi Himmelfahrt", Easter: easterOffset(39)},
+		{Name: "Pfingstmontag", Easter: easterOffset(50)},
+		{Name: "Tag der Deutschen Einheit", Month: 10, Day: 3},
+		{Name: "1. Weihnachtstag", Month: 12, Day: 25},
+		{Name: "2. Weihnachtstag", Month: 12, Day: 26},
+	}},
+	"france": {Name: "France public holidays", Rules: []HolidayRule{
+		{Name: "Jour de l'an", Month: 1, Day: 1},
+		{Name: "Lundi de Pâques", Easter: easterOffset(1)},
+		{Name: "Fête du Travail", Month: 5, Day: 1},
+		{Name: "Victoire 1945", Month: 5, Day: 8},
+		{Name: "Ascension", Easter: easterOffset(39)},
+		{Name: "Lundi de Pentecôte", Easter: easterOffset(50)},
+		{Name: "Fête nationale", Month: 7, Day: 14},
+		{Name: "Assomption", Month: 8, Day: 15},
+		{Name: "Toussaint", Month: 11, Day: 1},
+		{Name: "Armistice 1918", Month: 11, Day: 11},
+		{Name: "Noël", Month: 12, Day: 25},
+	}},
+}
+
+// HolidaySetNames returns the names of the built-in holiday sets, sorted.
+func HolidaySetNames() []string {
+	names := make([]string, 0, len(HolidaySets))
+	for name := range HolidaySets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func easterOffset(days int) *int { return &days }
+
+func isWeekend(d time.Time) bool {
+	return d.Weekday() == time.Saturday || d.Weekday() == time.Sunday
+}
+
+// daysIn returns the number of days in month of year.
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
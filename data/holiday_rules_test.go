@@ -0,0 +1,118 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDefaultHolidaysMatchUSFederal(t *testing.T) {
+	// The literal list DefaultHolidays replaced.
+	want := []Holiday{
+		{Name: "New Year's Day", Date: "2025-01-01"},
+		{Name: "MLK Day", Date: "2025-01-20"},
+		{Name: "Presidents' Day", Date: "2025-02-17"},
+		{Name: "Memorial Day", Date: "2025-05-26"},
+		{Name: "Juneteenth", Date: "2025-06-19"},
+		{Name: "Independence Day", Date: "2025-07-04"},
+		{Name: "Labor Day", Date: "2025-09-01"},
+		{Name: "Columbus Day", Date: "2025-10-13"},
+		{Name: "Veterans Day", Date: "2025-11-11"},
+		{Name: "Thanksgiving Day", Date: "2025-11-27"},
+		{Name: "Christmas Day", Date: "2025-12-25"},
+		{Name: "New Year's Day", Date: "2026-01-01"},
+		{Name: "MLK Day", Date: "2026-01-19"},
+		{Name: "Presidents' Day", Date: "2026-02-16"},
+		{Name: "Memorial Day", Date: "2026-05-25"},
+		{Name: "Juneteenth", Date: "2026-06-19"},
+		{Name: "Independence Day (observed)", Date: "2026-07-03"},
+		{Name: "Labor Day", Date: "2026-09-07"},
+		{Name: "Columbus Day", Date: "2026-10-12"},
+		{Name: "Veterans Day", Date: "2026-11-11"},
+		{Name: "Thanksgiving Day", Date: "2026-11-26"},
+		{Name: "Christmas Day", Date: "2026-12-25"},
+	}
+	if got := DefaultHolidays(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DefaultHolidays() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestGenerateObservedShifts(t *testing.T) {
+	// 2027: Christmas is a Saturday and Boxing Day a Sunday; New Year's Day
+	// 2028 is a Saturday too.
+	dates := func(hs []Holiday) map[string]string {
+		m := map[string]string{}
+		for _, h := range hs {
+			m[h.Date] = h.Name
+		}
+		return m
+	}
+	us := dates(HolidaySets["us-federal"].Generate(2027))
+	if us["2027-12-24"] != "Christmas Day (observed)" || us["2027-12-31"] != "New Year's Day (observed)" {
+		t.Errorf("expected US Christmas on Friday and 2028 New Year's Day on Dec 31, got %v", us)
+	}
+	uk := dates(HolidaySets["uk"].Generate(2027))
+	if uk["2027-12-27"] != "Christmas Day (observed)" || uk["2027-12-28"] != "Boxing Day (observed)" {
+		t.Errorf("expected UK substitute days on Dec 27 and 28, got %v", uk)
+	}
+	if uk["2027-03-26"] != "Good Friday" || uk["2027-03-29"] != "Easter Monday" || uk["2027-05-31"] != "Spring bank holiday" {
+		t.Errorf("unexpected UK Easter or May dates: %v", uk)
+	}
+	// 2022: Christmas on Sunday moves past Boxing Day on Monday.
+	uk = dates(HolidaySets["uk"].Generate(2022))
+	if uk["2022-12-26"] != "Boxing Day" || uk["2022-12-27"] != "Christmas Day (observed)" {
+		t.Errorf("expected Christmas substituted on Dec 27 2022, got %v", uk)
+	}
+	ca := dates(HolidaySets["canada"].Generate(2026))
+	if ca["2026-05-18"] != "Victoria Day" {
+		t.Errorf("expected Victoria Day on the Monday before May 25, got %v", ca)
+	}
+}
+
+func TestEasterSunday(t *testing.T) {
+	for year, want := range map[int]string{2024: "2024-03-31", 2025: "2025-04-20", 2026: "2026-04-05", 2027: "2027-03-28", 2038: "2038-04-25"} {
+		if got := EasterSunday(year).Format(BadgeDateFormat); got != want {
+			t.Errorf("EasterSunday(%d) = %s, want %s", year, got, want)
+		}
+	}
+}
+
+func TestParseHolidaySet(t *testing.T) {
+	doc := `name: Acme
+rules:
+  - name: Founders' Day
+    month: 3
+    day: 1
+    observed: next
+  - name: Summer Friday
+    month: 8
+    weekday: fri
+    nth: -1
+  - name: Spring Break
+    easter: 1
+    from: 2027
+`
+	s, err := ParseHolidaySet([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Generate(2026)
+	want := []Holiday{{Name: "Founders' Day (observed)", Date: "2026-03-02"}, {Name: "Summer Friday", Date: "2026-08-28"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Generate(2026) = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{
+		"name: Empty\n",
+		"rules:\n  - month: 1\n    day: 1\n",
+		"rules:\n  - name: X\n    month: 13\n    day: 1\n",
+		"rules:\n  - name: X\n    month: 2\n    day: 30\n",
+		"rules:\n  - name: X\n    month: 1\n    weekday: mon\n",
+		"rules:\n  - name: X\n    month: 1\n    weekday: funday\n    nth: 1\n",
+		"rules:\n  - name: X\n    easter: 1\n    month: 4\n",
+		"rules:\n  - name: X\n    month: 1\n    day: 1\n    observed: later\n",
+	} {
+		if _, err := ParseHolidaySet([]byte(bad)); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
@@ -90,3 +90,15 @@ func TestHolidayDuplicateDate(t *testing.T) {
 		t.Error("expected last holiday to win for duplicate date")
 	}
 }
+
+func TestHolidayValidate(t *testing.T) {
+	if err := (Holiday{Name: "New Year", Date: "2025-01-01"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (Holiday{Name: "", Date: "2025-01-01"}).Validate(); err == nil {
+		t.Error("expected error for missing name")
+	}
+	if err := (Holiday{Name: "New Year", Date: "2025-1-1"}).Validate(); err == nil {
+		t.Error("expected error for malformed date")
+	}
+}
@@ -0,0 +1,83 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LeaveType is the kind of leave a Vacation records.
+type LeaveType string
+
+const (
+	LeaveVacation    LeaveType = "vacation" // default
+	LeaveSick        LeaveType = "sick"
+	LeaveJuryDuty    LeaveType = "jury_duty"
+	LeaveTravel      LeaveType = "travel" // business travel, e.g. to another office
+	LeaveBereavement LeaveType = "bereavement"
+	LeaveUnpaid      LeaveType = "unpaid"
+)
+
+// LeaveTypes lists every leave type in display order.
+var LeaveTypes = []LeaveType{LeaveVacation, LeaveSick, LeaveJuryDuty, LeaveTravel, LeaveBereavement, LeaveUnpaid}
+
+var leaveLabels = map[LeaveType]string{
+	LeaveVacation:    "Vacation",
+	LeaveSick:        "Sick",
+	LeaveJuryDuty:    "Jury duty",
+	LeaveTravel:      "Business travel",
+	LeaveBereavement: "Bereavement",
+	LeaveUnpaid:      "Unpaid",
+}
+
+// Label returns the display name of t.
+func (t LeaveType) Label() string {
+	if l, ok := leaveLabels[t]; ok {
+		return l
+	}
+	return string(t)
+}
+
+// Valid reports whether t is one of LeaveTypes.
+func (t LeaveType) Valid() bool {
+	_, ok := leaveLabels[t]
+	return ok
+}
+
+// ParseLeaveType parses a leave type name such as "sick" or "jury-duty".
+// An empty string is a vacation.
+func ParseLeaveType(s string) (LeaveType, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return LeaveVacation, nil
+	}
+	t := LeaveType(strings.NewReplacer("-", "_", " ", "_").Replace(s))
+	if !t.Valid() {
+		return "", fmt.Errorf("unknown leave type %q (expected %s)", s, leaveTypeList())
+	}
+	return t, nil
+}
+
+func leaveTypeList() string {
+	names := make([]string, len(LeaveTypes))
+	for i, t := range LeaveTypes {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Leave rules for Policy.Leave: how a day of leave counts toward the goal.
+const (
+	LeaveExclude = "exclude" // the day leaves the total days, like a holiday
+	LeaveAttend  = "attend"  // the day counts as an office day
+	LeaveAbsent  = "absent"  // the day stays in the total days without credit
+)
+
+// defaultLeaveRules are the rules for leave types policy.yaml doesn't list.
+var defaultLeaveRules = map[LeaveType]string{
+	LeaveVacation:    LeaveExclude,
+	LeaveSick:        LeaveExclude,
+	LeaveJuryDuty:    LeaveExclude,
+	LeaveTravel:      LeaveAttend,
+	LeaveBereavement: LeaveExclude,
+	LeaveUnpaid:      LeaveAbsent,
+}
@@ -0,0 +1,174 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LockFilename is the advisory lock file created in the data directory.
+const LockFilename = ".rto.lock"
+
+const lockPollInterval = 100 * time.Millisecond
+
+// errLockHeld is returned by tryLockFile when another process holds the lock.
+var errLockHeld = errors.New("lock held")
+
+// LockedError is returned when another live rto process holds the lock.
+type LockedError struct {
+	Path  string
+	PID   int // 0 if the holder hasn't recorded its PID yet
+	Owner string
+}
+
+func (e *LockedError) Error() string {
+	holder := "pid unknown"
+	if e.PID > 0 {
+		holder = fmt.Sprintf("pid %d", e.PID)
+	}
+	if e.Owner != "" {
+		holder += ", " + e.Owner
+	}
+	return fmt.Sprintf("data directory is locked by another rto process (%s); lock file: %s", holder, e.Path)
+}
+
+// Lock is an exclusive advisory lock on a data directory. It is an OS file
+// lock (flock, or LockFileEx on Windows) on LockFilename, which records the
+// holder's PID for error messages. The OS drops the lock when its holder
+// exits, so a lock file left behind by a crash is simply locked again.
+type Lock struct {
+	path string
+	f    *os.File
+}
+
+// AcquireLock takes the lock in dir on behalf of owner (e.g. "tui").
+// If the lock is held, it polls until wait elapses; a zero wait fails
+// immediately. The returned error is a *LockedError when another process
+// still holds the lock.
+func AcquireLock(dir, owner string, wait time.Duration) (*Lock, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating directory %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, LockFilename)
+	deadline := time.Now().Add(wait)
+
+	for {
+		l, err := tryAcquire(path, owner)
+		if err == nil {
+			return l, nil
+		}
+		if !errors.Is(err, errLockHeld) {
+			return nil, err
+		}
+		if !time.Now().Before(deadline) {
+			pid, holder := readLockFile(path)
+			return nil, &LockedError{Path: path, PID: pid, Owner: holder}
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// tryAcquire makes one attempt at the lock on path. It returns errLockHeld
+// if another process holds it, or if the file was released and removed
+// while this attempt was locking it.
+func tryAcquire(path, owner string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file %s: %w", path, err)
+	}
+	if err := tryLockFile(f); err != nil {
+		f.Close()
+		if errors.Is(err, errLockHeld) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+
+	// The holder removes the file on release; a lock on the removed file
+	// locks nothing, so start over on whatever is at path now.
+	held, err := f.Stat()
+	current, serr := os.Stat(path)
+	if err != nil || serr != nil || !os.SameFile(held, current) {
+		unlockFile(f)
+		f.Close()
+		return nil, errLockHeld
+	}
+
+	if err := writeLockFile(f, owner); err != nil {
+		unlockFile(f)
+		f.Close()
+		return nil, fmt.Errorf("writing lock file %s: %w", path, err)
+	}
+	return &Lock{path: path, f: f}, nil
+}
+
+// writeLockFile replaces the contents of a held lock file with this
+// process's PID and owner.
+func writeLockFile(f *os.File, owner string) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt([]byte(fmt.Sprintf("%d\n%s\n", os.Getpid(), owner)), 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// AcquireStoreLock locks the directory that backs the given store.
+func AcquireStoreLock(s Store, owner string, wait time.Duration) (*Lock, error) {
+	return AcquireLock(storeLockDir(s), owner, wait)
+}
+
+// storeLockDir returns the directory that holds the lock file for a store.
+func storeLockDir(s Store) string {
+	switch st := s.(type) {
+	case *DirStore:
+		return st.Dir
+	case *SQLiteStore:
+		return filepath.Dir(st.path)
+	}
+	return GetDataDir()
+}
+
+// Release removes the lock file and drops the lock. It is safe to call on a
+// nil Lock. The file is removed while still locked, so a waiter that opened
+// it can tell it is gone and retries on a fresh one.
+func (l *Lock) Release() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	rerr := os.Remove(l.path)
+	if rerr != nil && (os.IsNotExist(rerr) || removeBlocked(rerr)) {
+		rerr = nil
+	}
+	uerr := unlockFile(l.f)
+	cerr := l.f.Close()
+	l.f = nil
+	if err := errors.Join(rerr, uerr, cerr); err != nil {
+		return fmt.Errorf("releasing lock file %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// readLockFile returns the PID and owner recorded in a lock file, or 0 if
+// the file can't be read or parsed, e.g. while its holder is still writing.
+func readLockFile(path string) (int, string) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, ""
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return 0, ""
+	}
+	owner := ""
+	if len(lines) > 1 {
+		owner = strings.TrimSpace(lines[1])
+	}
+	return pid, owner
+}
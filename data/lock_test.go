@@ -0,0 +1,143 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAcquireAndReleaseLock(t *testing.T) {
+	dir := t.TempDir()
+	lock, err := AcquireLock(dir, "tui", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path := filepath.Join(dir, LockFilename)
+	pid, owner := readLockFile(path)
+	if pid != os.Getpid() || owner != "tui" {
+		t.Errorf("expected pid %d/tui, got %d/%s", os.Getpid(), pid, owner)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("release error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("lock file should be removed on release")
+	}
+}
+
+func TestAcquireLockHeldFails(t *testing.T) {
+	dir := t.TempDir()
+	lock, err := AcquireLock(dir, "tui", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer lock.Release()
+
+	_, err = AcquireLock(dir, "cli", 0)
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("expected LockedError, got %v", err)
+	}
+	if locked.PID != os.Getpid() || locked.Owner != "tui" {
+		t.Errorf("expected holder pid %d (tui), got %d (%s)", os.Getpid(), locked.PID, locked.Owner)
+	}
+}
+
+func TestAcquireLockWaitsForRelease(t *testing.T) {
+	dir := t.TempDir()
+	lock, err := AcquireLock(dir, "tui", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		lock.Release()
+	}()
+
+	second, err := AcquireLock(dir, "cli", 2*time.Second)
+	if err != nil {
+		t.Fatalf("expected lock after waiting, got %v", err)
+	}
+	second.Release()
+}
+
+func TestAcquireLockTakesOverStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	// PIDs this large are never assigned, so the holder is considered dead.
+	stale := fmt.Sprintf("%d\ntui\n", 1<<30)
+	if err := os.WriteFile(filepath.Join(dir, LockFilename), []byte(stale), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	lock, err := AcquireLock(dir, "cli", 0)
+	if err != nil {
+		t.Fatalf("expected stale lock to be replaced, got %v", err)
+	}
+	lock.Release()
+}
+
+func TestAcquireLockKeepsUnwrittenLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, LockFilename)
+	// A holder that has locked the file but not yet written its PID.
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+	if err := tryLockFile(f); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+
+	_, err = AcquireLock(dir, "cli", 3*lockPollInterval)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.PID != 0 {
+		t.Fatalf("an empty lock file that is held should not be taken over, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "pid unknown") {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
+
+func TestAcquireLockExcludesConcurrentHolders(t *testing.T) {
+	dir := t.TempDir()
+	var held, overlaps atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				lock, err := AcquireLock(dir, "cli", 10*time.Second)
+				if err != nil {
+					t.Errorf("acquire: %v", err)
+					return
+				}
+				if held.Add(1) > 1 {
+					overlaps.Add(1)
+				}
+				time.Sleep(time.Millisecond)
+				held.Add(-1)
+				if err := lock.Release(); err != nil {
+					t.Errorf("release: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := overlaps.Load(); n > 0 {
+		t.Errorf("lock was held by two holders at once %d time(s)", n)
+	}
+}
+
+func TestReleaseNilLock(t *testing.T) {
+	var l *Lock
+	if err := l.Release(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build !windows
+
+package data
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockFile takes an exclusive flock on f without blocking, returning
+// errLockHeld if another process holds it.
+func tryLockFile(f *os.File) error {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLockHeld
+	}
+	return err
+}
+
+// unlockFile drops the flock on f.
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
+
+// removeBlocked reports whether removing a lock file failed only because
+// another process has it open. Unix allows removing open files.
+func removeBlocked(err error) bool {
+	return false
+}
@@ -0,0 +1,38 @@
+//go:build windows
+
+package data
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockOffset places the locked byte far past the PID and owner, because a
+// LockFileEx range can't be read by other processes.
+const lockOffset = 1 << 30
+
+// tryLockFile takes an exclusive LockFileEx lock on f without blocking,
+// returning errLockHeld if another process holds it.
+func tryLockFile(f *os.File) error {
+	ol := windows.Overlapped{OffsetHigh: lockOffset}
+	err := windows.LockFileEx(windows.Handle(f.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &ol)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLockHeld
+	}
+	return err
+}
+
+// unlockFile drops the LockFileEx lock on f.
+func unlockFile(f *os.File) error {
+	ol := windows.Overlapped{OffsetHigh: lockOffset}
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, &ol)
+}
+
+// removeBlocked reports whether removing a lock file failed only because
+// another process has it open; the file is then left for that process.
+func removeBlocked(err error) bool {
+	return errors.Is(err, windows.ERROR_SHARING_VIOLATION) || errors.Is(err, windows.ERROR_ACCESS_DENIED)
+}
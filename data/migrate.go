@@ -0,0 +1,372 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentSchemaVersion is the schema_version written to every data file.
+// It must equal the Version of the last entry in migrations.
+const CurrentSchemaVersion = 1
+
+// DocKind identifies which kind of data file a document is, so migrations
+// can target specific files.
+type DocKind string
+
+const (
+	DocBadges      DocKind = "badge_data"
+	DocEvents      DocKind = "events"
+	DocVacations   DocKind = "vacations"
+	DocHolidays    DocKind = "holidays"
+	DocTimePeriods DocKind = "timeperiods"
+)
+
+// Migration upgrades a decoded document from schema Version-1 to Version.
+// Apply edits doc in place; it is called for every kind, so migrations that
+// only affect one file should check kind first.
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func(kind DocKind, doc map[string]interface{}) error
+}
+
+// migrations is the ordered registry of schema changes. Append new entries
+// (and bump CurrentSchemaVersion) whenever a file format changes; never edit
+// or reorder existing ones, since checked-in data depends on them.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "add schema_version to all data files",
+		Apply:       func(DocKind, map[string]interface{}) error { return nil },
+	},
+}
+
+// Migrations returns a copy of the migration registry, in order.
+func Migrations() []Migration {
+	result := make([]Migration, len(migrations))
+	copy(result, migrations)
+	return result
+}
+
+// schemaHeader is decoded first to find a document's version cheaply.
+type schemaHeader struct {
+	SchemaVersion int `json:"schema_version" yaml:"schema_version"`
+}
+
+// documentVersion returns the schema_version recorded in raw (0 if absent).
+func documentVersion(name string, raw []byte) (int, error) {
+	var h schemaHeader
+	if err := unmarshalDocument(name, raw, &h); err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return h.SchemaVersion, nil
+}
+
+// migrateRaw upgrades raw document bytes to CurrentSchemaVersion, returning
+// them unchanged if already current. Documents from a newer rto are rejected
+// rather than guessed at. When the migrations change nothing but the
+// version, only schema_version is written into raw, so comments, key order
+// and formatting survive; otherwise the document is re-encoded.
+func migrateRaw(name string, kind DocKind, raw []byte) ([]byte, error) {
+	version, err := documentVersion(name, raw)
+	if err != nil {
+		return nil, err
+	}
+	if version == CurrentSchemaVersion {
+		return raw, nil
+	}
+	if version > CurrentSchemaVersion {
+		return nil, fmt.Errorf("%s has schema_version %d, but this rto only understands up to %d — upgrade rto",
+			name, version, CurrentSchemaVersion)
+	}
+
+	doc, err := decodeGeneric(name, raw)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+		if err := m.Apply(kind, doc); err != nil {
+			return nil, fmt.Errorf("migrating %s to schema %d (%s): %w", name, m.Version, m.Description, err)
+		}
+	}
+	doc["schema_version"] = CurrentSchemaVersion
+	encoded, err := marshalDocument(name, doc)
+	if err != nil {
+		return nil, err
+	}
+	if stamped, ok := stampSchemaVersion(name, raw, encoded); ok {
+		return stamped, nil
+	}
+	return encoded, nil
+}
+
+var (
+	yamlVersionLine  = regexp.MustCompile(`(?m)^schema_version:[ \t]*[^\s#]*`)
+	jsonVersionField = regexp.MustCompile(`"schema_version"\s*:\s*[^,}\s]*`)
+)
+
+// stampSchemaVersion sets schema_version in raw as written, adding it if
+// absent. It reports false if the result doesn't decode to the same
+// document as want, i.e. the migrations changed more than the version.
+func stampSchemaVersion(name string, raw, want []byte) ([]byte, bool) {
+	line := fmt.Sprintf("schema_version: %d", CurrentSchemaVersion)
+	var stamped []byte
+	switch {
+	case isJSONFile(name):
+		stamped = stampJSONField(raw, fmt.Sprintf(`"schema_version": %d`, CurrentSchemaVersion))
+	case yamlVersionLine.Match(raw):
+		stamped = yamlVersionLine.ReplaceAllLiteral(raw, []byte(line))
+	default:
+		stamped = insertYAMLLine(raw, line)
+	}
+
+	got, err := decodeGeneric(name, stamped)
+	if err != nil {
+		return nil, false
+	}
+	expected, err := decodeGeneric(name, want)
+	if err != nil || !reflect.DeepEqual(got, expected) {
+		return nil, false
+	}
+	return stamped, true
+}
+
+// stampJSONField replaces the schema_version field of a JSON object, or
+// adds field as its first member, matching the layout of the next one.
+func stampJSONField(raw []byte, field string) []byte {
+	var out bytes.Buffer
+	if loc := jsonVersionField.FindIndex(raw); loc != nil {
+		out.Write(raw[:loc[0]])
+		out.WriteString(field)
+		out.Write(raw[loc[1]:])
+		return out.Bytes()
+	}
+	open := bytes.IndexByte(raw, '{')
+	if open < 0 {
+		return raw
+	}
+	rest := raw[open+1:]
+	body := bytes.TrimLeft(rest, " \t\r\n")
+	space := rest[:len(rest)-len(body)]
+	out.Write(raw[:open+1])
+	switch {
+	case bytes.HasPrefix(body, []byte("}")):
+		out.WriteString(field)
+		out.Write(rest)
+	case bytes.ContainsRune(space, '\n'):
+		out.Write(space)
+		out.WriteString(field + ",")
+		out.Write(rest)
+	default:
+		out.Write(space)
+		out.WriteString(field + ", ")
+		out.Write(body)
+	}
+	return out.Bytes()
+}
+
+// insertYAMLLine adds line before the first top-level key of a YAML
+// document, after any leading comments.
+func insertYAMLLine(raw []byte, line string) []byte {
+	lines := bytes.SplitAfter(raw, []byte("\n"))
+	for i, l := range lines {
+		t := bytes.TrimSpace(l)
+		if len(t) == 0 || t[0] == '#' || bytes.Equal(t, []byte("---")) {
+			continue
+		}
+		var out bytes.Buffer
+		for _, before := range lines[:i] {
+			out.Write(before)
+		}
+		out.WriteString(line + "\n")
+		for _, after := range lines[i:] {
+			out.Write(after)
+		}
+		return out.Bytes()
+	}
+	if len(raw) > 0 && !bytes.HasSuffix(raw, []byte("\n")) {
+		raw = append(raw, '\n')
+	}
+	return append(raw, line+"\n"...)
+}
+
+// loadVersioned reads the named document from s, migrates it in memory to
+// the current schema, and decodes it into v. A missing document leaves v
+// unchanged.
+func loadVersioned(s Store, name string, kind DocKind, v interface{}) error {
+	raw, err := s.Read(name)
+	if err != nil || raw == nil {
+		return err
+	}
+	migrated, err := migrateRaw(name, kind, raw)
+	if err != nil {
+		return err
+	}
+	return unmarshalDocument(name, migrated, v)
+}
+
+// decodeGeneric decodes a document into a plain map for migrations. YAML
+// timestamps are kept as their original strings so that unquoted dates in
+// hand-written files survive the round trip.
+func decodeGeneric(name string, raw []byte) (map[string]interface{}, error) {
+	doc := map[string]interface{}{}
+	if isJSONFile(name) {
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		return doc, nil
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	v, err := nodeToInterface(&node)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, nil
+	}
+	if v == nil {
+		return doc, nil
+	}
+	return nil, fmt.Errorf("parsing %s: top level is not a mapping", name)
+}
+
+func nodeToInterface(n *yaml.Node) (interface{}, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return nodeToInterface(n.Content[0])
+	case yaml.AliasNode:
+		return nodeToInterface(n.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v, err := nodeToInterface(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[n.Content[i].Value] = v
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		s := make([]interface{}, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := nodeToInterface(c)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		return s, nil
+	case yaml.ScalarNode:
+		if n.Tag == "!!timestamp" {
+			return n.Value, nil
+		}
+		var v interface{}
+		if err := n.Decode(&v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return nil, nil
+}
+
+// MigrationResult describes the migration of a single document.
+type MigrationResult struct {
+	Name        string
+	FromVersion int
+	ToVersion   int
+	Before      []byte
+	After       []byte
+}
+
+// Changed reports whether the document needed migrating.
+func (r MigrationResult) Changed() bool {
+	return r.FromVersion < r.ToVersion
+}
+
+// MigrateStore upgrades every data document in s to CurrentSchemaVersion
+// and reports what changed. Documents already at the current version are
+// left byte-for-byte untouched, and the rest are migrated as written (see
+// migrateRaw), keeping hand-written comments and unknown keys. Each must
+// still load, so a broken document is reported rather than written. With
+// dryRun, nothing is written; After holds exactly what would have been saved.
+func MigrateStore(s Store, dryRun bool) ([]MigrationResult, error) {
+	settings, err := LoadAppSettingsFromStore(s)
+	if err != nil {
+		return nil, fmt.Errorf("loading settings: %w", err)
+	}
+
+	type target struct {
+		name string
+		kind DocKind
+		load func() error
+	}
+	targets := []target{
+		{badgeDataFilename, DocBadges, func() error { _, err := LoadBadgeEntryDataFromStore(s); return err }},
+		{eventsFilename, DocEvents, func() error { _, err := LoadEventDataFromStore(s); return err }},
+		{vacationsFilename, DocVacations, func() error { _, err := LoadVacationDataFromStore(s); return err }},
+		{holidaysFilename, DocHolidays, func() error { _, err := LoadHolidayDataFromStore(s); return err }},
+	}
+	seen := map[string]bool{}
+	for _, tpFile := range settings.TimePeriods {
+		if seen[tpFile] {
+			continue
+		}
+		seen[tpFile] = true
+		name := tpFile
+		targets = append(targets, target{name, DocTimePeriods, func() error {
+			_, err := LoadTimePeriodDataFromStore(s, name)
+			return err
+		}})
+	}
+
+	var results []MigrationResult
+	for _, t := range targets {
+		raw, err := s.Read(t.name)
+		if err != nil {
+			return results, err
+		}
+		if raw == nil {
+			continue
+		}
+		version, err := documentVersion(t.name, raw)
+		if err != nil {
+			return results, err
+		}
+		if version > CurrentSchemaVersion {
+			return results, fmt.Errorf("%s has schema_version %d, but this rto only understands up to %d — upgrade rto",
+				t.name, version, CurrentSchemaVersion)
+		}
+		res := MigrationResult{Name: t.name, FromVersion: version, ToVersion: CurrentSchemaVersion, Before: raw, After: raw}
+		if res.Changed() {
+			if err := t.load(); err != nil {
+				return results, err
+			}
+			res.After, err = migrateRaw(t.name, t.kind, raw)
+			if err != nil {
+				return results, err
+			}
+			if !dryRun {
+				if err := s.Write(t.name, res.After); err != nil {
+					return results, err
+				}
+			}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
@@ -0,0 +1,217 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrationsRegistryOrdered(t *testing.T) {
+	all := Migrations()
+	if len(all) == 0 {
+		t.Fatal("expected at least one migration")
+	}
+	for i, m := range all {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %d; versions must be sequential from 1", i, m.Version)
+		}
+	}
+	if all[len(all)-1].Version != CurrentSchemaVersion {
+		t.Errorf("last migration version %d != CurrentSchemaVersion %d", all[len(all)-1].Version, CurrentSchemaVersion)
+	}
+}
+
+func TestSaveWritesSchemaVersion(t *testing.T) {
+	dir := t.TempDir()
+	h := NewHolidayData()
+	h.Add(Holiday{Name: "New Year", Date: "2025-01-01"})
+	if err := h.SaveTo(dir); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	b, _ := os.ReadFile(filepath.Join(dir, holidaysFilename))
+	if !strings.HasPrefix(string(b), "schema_version: 1\n") {
+		t.Errorf("expected schema_version first, got:\n%s", b)
+	}
+
+	e := NewEventData()
+	if err := e.SaveTo(dir); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	b, _ = os.ReadFile(filepath.Join(dir, eventsFilename))
+	if !strings.Contains(string(b), `"schema_version": 1`) {
+		t.Errorf("expected schema_version in JSON, got:\n%s", b)
+	}
+}
+
+func TestLoadUnversionedFile(t *testing.T) {
+	dir := t.TempDir()
+	// Hand-written, pre-versioning file with unquoted dates.
+	yml := "timeperiods:\n  - key: Q1_2025\n    name: Q1\n    start_date: 2025-01-01\n    end_date: 2025-03-31\n"
+	if err := os.WriteFile(filepath.Join(dir, "periods.yaml"), []byte(yml), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	td, err := LoadTimePeriodDataFrom(dir, "periods.yaml")
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	tp, err := td.GetPeriodByKey("Q1_2025")
+	if err != nil {
+		t.Fatalf("expected Q1_2025: %v", err)
+	}
+	if tp.StartDateRaw != "2025-01-01" {
+		t.Errorf("expected unquoted date preserved, got %q", tp.StartDateRaw)
+	}
+}
+
+func TestLoadNewerSchemaFails(t *testing.T) {
+	dir := t.TempDir()
+	json := `{"schema_version": 999, "badge_data": []}`
+	if err := os.WriteFile(filepath.Join(dir, badgeDataFilename), []byte(json), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := LoadBadgeEntryDataFrom(dir); err == nil || !strings.Contains(err.Error(), "upgrade rto") {
+		t.Errorf("expected newer-schema error, got %v", err)
+	}
+}
+
+func TestMigrationApplyRuns(t *testing.T) {
+	old := migrations
+	defer func() { migrations = old }()
+
+	var seen []DocKind
+	migrations = append(append([]Migration{}, old...), Migration{
+		Version:     CurrentSchemaVersion + 1,
+		Description: "test",
+		Apply: func(kind DocKind, doc map[string]interface{}) error {
+			seen = append(seen, kind)
+			return nil
+		},
+	})
+	if _, err := migrateRaw("events.json", DocEvents, []byte(`{"events": []}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(seen) != 1 || seen[0] != DocEvents {
+		t.Errorf("expected migration to run once for events, got %v", seen)
+	}
+}
+
+func writeUnversionedDataDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		settingsFilename:       "time_periods:\n  - q.yaml\n",
+		"q.yaml":               "timeperiods:\n  - key: Q1_2025\n    name: Q1\n    start_date: \"2025-01-01\"\n    end_date: \"2025-03-31\"\n",
+		holidaysFilename:       "holidays:\n  - name: \"New Year\"\n    date: \"2025-01-01\"\n",
+		badgeDataFilename:      `{"badge_data": [{"entry_date": "2025-01-06", "date_time": "2025-01-06T09:00:00", "office": "HQ", "is_badged_in": true, "is_flex_credit": false}]}`,
+		"unrelated-notes.yaml": "foo: bar\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestMigrateStoreDryRun(t *testing.T) {
+	dir := writeUnversionedDataDir(t)
+	before, _ := os.ReadFile(filepath.Join(dir, holidaysFilename))
+
+	results, err := MigrateStore(NewDirStore(dir), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// badge_data.json, holidays.yaml and q.yaml exist; events/vacations don't.
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if !r.Changed() || r.FromVersion != 0 || r.ToVersion != CurrentSchemaVersion {
+			t.Errorf("%s: expected 0 → %d, got %d → %d", r.Name, CurrentSchemaVersion, r.FromVersion, r.ToVersion)
+		}
+		if !strings.Contains(string(r.After), "schema_version") {
+			t.Errorf("%s: expected schema_version in migrated output", r.Name)
+		}
+	}
+	after, _ := os.ReadFile(filepath.Join(dir, holidaysFilename))
+	if string(before) != string(after) {
+		t.Error("dry run should not modify files")
+	}
+}
+
+func TestMigrateStoreWritesAndIsIdempotent(t *testing.T) {
+	dir := writeUnversionedDataDir(t)
+	s := NewDirStore(dir)
+	if _, err := MigrateStore(s, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := os.ReadFile(filepath.Join(dir, "q.yaml"))
+	if !strings.HasPrefix(string(b), "schema_version: 1\n") {
+		t.Errorf("expected migrated q.yaml, got:\n%s", b)
+	}
+
+	results, err := MigrateStore(s, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, r := range results {
+		if r.Changed() {
+			t.Errorf("%s: second migration should be a no-op", r.Name)
+		}
+	}
+	bd, err := LoadBadgeEntryDataFrom(dir)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if e, ok := bd.Get("2025-01-06"); !ok || e.Office != "HQ" {
+		t.Error("badge data should survive migration")
+	}
+}
+
+func TestMigrateStoreKeepsDocumentAsWritten(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		settingsFilename:  "time_periods: []\n",
+		holidaysFilename:  "# Company holidays, from HR\nholidays:\n  - name: \"New Year\" # observed\n    date: 2025-01-01\n    region: US\n",
+		badgeDataFilename: "{\n  \"badge_data\": [],\n  \"exported_by\": \"portal\"\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	results, err := MigrateStore(NewDirStore(dir), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		holidaysFilename:  "# Company holidays, from HR\nschema_version: 1\n" + files[holidaysFilename][len("# Company holidays, from HR\n"):],
+		badgeDataFilename: "{\n  \"schema_version\": 1,\n  \"badge_data\": [],\n  \"exported_by\": \"portal\"\n}\n",
+	}
+	for _, r := range results {
+		if string(r.After) != want[r.Name] {
+			t.Errorf("%s: expected only schema_version added, got:\n%s", r.Name, r.After)
+		}
+	}
+}
+
+func TestStampSchemaVersion(t *testing.T) {
+	cases := []struct{ name, raw, want string }{
+		{"events.json", `{"events": []}`, `{"schema_version": 1, "events": []}`},
+		{"events.json", `{}`, `{"schema_version": 1}`},
+		{"events.json", `{"schema_version": 0, "events": []}`, `{"schema_version": 1, "events": []}`},
+		{"q.yaml", "schema_version: 0 # legacy\ntimeperiods: []\n", "schema_version: 1 # legacy\ntimeperiods: []\n"},
+		{"q.yaml", "", "schema_version: 1\n"},
+	}
+	for _, c := range cases {
+		got, err := migrateRaw(c.name, DocTimePeriods, []byte(c.raw))
+		if err != nil {
+			t.Fatalf("%s %q: unexpected error: %v", c.name, c.raw, err)
+		}
+		if string(got) != c.want {
+			t.Errorf("%s %q: got %q, want %q", c.name, c.raw, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period units for PeriodSpec.Unit.
+const (
+	UnitYears    = "years"
+	UnitHalves   = "halves"
+	UnitQuarters = "quarters"
+	UnitMonths   = "months"
+	UnitWeeks    = "weeks"
+)
+
+// PeriodUnits lists the units GeneratePeriods supports.
+var PeriodUnits = []string{UnitYears, UnitHalves, UnitQuarters, UnitMonths, UnitWeeks}
+
+// RetailPatterns lists the week patterns of a retail (4-4-5) calendar: the
+// weeks in each month of a 13-week quarter.
+var RetailPatterns = map[string][3]int{
+	"4-4-5": {4, 4, 5},
+	"4-5-4": {4, 5, 4},
+	"5-4-4": {5, 4, 4},
+}
+
+// PeriodSpec describes the time periods GeneratePeriods creates.
+//
+// A fiscal year starts on the 1st of StartMonth and is named for the
+// calendar year it ends in, so with StartMonth October, FY2026 runs from
+// October 1, 2025 to September 30, 2026. With a Pattern, the year is a
+// retail calendar instead: 52 or 53 whole weeks starting on the Monday
+// nearest the 1st of StartMonth, split into 13-week quarters whose months
+// follow the pattern; a 53rd week joins the last month. Weeks without a
+// Pattern are ISO weeks of ISO years, which ignore StartMonth.
+type PeriodSpec struct {
+	Unit       string     // one of PeriodUnits
+	StartMonth time.Month // first month of the fiscal year; 0 means January
+	Pattern    string     // "" for calendar months, or a key of RetailPatterns
+	Year       int        // first fiscal year
+	Years      int        // number of fiscal years; 0 means 1
+}
+
+// Validate checks the unit, start month, pattern and years.
+func (s PeriodSpec) Validate() error {
+	if !containsString(PeriodUnits, s.Unit) {
+		return fmt.Errorf("unknown period unit %q (expected %s)", s.Unit, strings.Join(PeriodUnits, ", "))
+	}
+	if s.StartMonth < 0 || s.StartMonth > 12 {
+		return fmt.Errorf("start month must be 1 to 12")
+	}
+	if _, ok := RetailPatterns[s.Pattern]; s.Pattern != "" && !ok {
+		return fmt.Errorf("unknown retail pattern %q (expected 4-4-5, 4-5-4 or 5-4-4)", s.Pattern)
+	}
+	if s.Year < 1900 || s.Year > 9999 {
+		return fmt.Errorf("year %d is out of range", s.Year)
+	}
+	if s.Years < 0 || s.Years > 100 {
+		return fmt.Errorf("years must be 1 to 100")
+	}
+	return nil
+}
+
+// Fiscal reports whether the spec's years differ from calendar years.
+func (s PeriodSpec) Fiscal() bool {
+	return s.Pattern != "" || (s.StartMonth != 0 && s.StartMonth != time.January)
+}
+
+// GeneratePeriods returns the periods s describes, in date order, with
+// their dates parsed.
+func GeneratePeriods(s PeriodSpec) ([]TimePeriod, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	var periods []TimePeriod
+	add := func(key, name string, start, end time.Time) {
+		tp := TimePeriod{
+			Key:          key,
+			Name:         name,
+			StartDateRaw: start.Format(BadgeDateFormat),
+			EndDateRaw:   end.Format(BadgeDateFormat),
+		}
+		tp.StartDate, tp.EndDate = start, end
+		periods = append(periods, tp)
+	}
+
+	for fy := s.Year; fy < s.Year+max(s.Years, 1); fy++ {
+		if s.Unit == UnitWeeks && s.Pattern == "" {
+			d := isoWeekOne(fy)
+			for w := 1; isoYear(d) == fy; w++ {
+				name := fmt.Sprintf("W%02d", w)
+				add(TimePeriodKey(name, fy), name, d, d.AddDate(0, 0, 6))
+				d = d.AddDate(0, 0, 7)
+			}
+			continue
+		}
+
+		months := s.fiscalMonths(fy)
+		yearName := fmt.Sprintf("%d", fy)
+		if s.Fiscal() {
+			yearName = fmt.Sprintf("FY%d", fy)
+		}
+		switch s.Unit {
+		case UnitYears:
+			add(yearName, yearName, months[0], months[12].AddDate(0, 0, -1))
+		case UnitHalves, UnitQuarters:
+			n := 6
+			prefix := "H"
+			if s.Unit == UnitQuarters {
+				n, prefix = 3, "Q"
+			}
+			for i := 0; i < 12/n; i++ {
+				name := fmt.Sprintf("%s%d", prefix, i+1)
+				add(TimePeriodKey(name, fy), name, months[i*n], months[(i+1)*n].AddDate(0, 0, -1))
+			}
+		case UnitMonths:
+			for i := 0; i < 12; i++ {
+				start, end := months[i], months[i+1].AddDate(0, 0, -1)
+				if s.Pattern != "" {
+					name := fmt.Sprintf("P%d", i+1)
+					add(TimePeriodKey(name, fy), name, start, end)
+				} else {
+					name := start.Format("Jan")
+					add(TimePeriodKey(name, start.Year()), name, start, end)
+				}
+			}
+		case UnitWeeks:
+			w := 1
+			for d := months[0]; d.Before(months[12]); d = d.AddDate(0, 0, 7) {
+				name := fmt.Sprintf("W%02d", w)
+				add(TimePeriodKey(name, fy), name, d, d.AddDate(0, 0, 6))
+				w++
+			}
+		}
+	}
+	return periods, nil
+}
+
+// fiscalMonths returns the first day of each month of fiscal year fy and
+// the first day of the next year: 13 dates.
+func (s PeriodSpec) fiscalMonths(fy int) [13]time.Time {
+	start := s.StartMonth
+	if start == 0 {
+		start = time.January
+	}
+	year := fy
+	if start != time.January {
+		year--
+	}
+	var months [13]time.Time
+	if s.Pattern == "" {
+		for i := range months {
+			months[i] = time.Date(year, start+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+		}
+		return months
+	}
+
+	pattern := RetailPatterns[s.Pattern]
+	d := nearestMonday(time.Date(year, start, 1, 0, 0, 0, 0, time.UTC))
+	for i := 0; i < 12; i++ {
+		months[i] = d
+		d = d.AddDate(0, 0, 7*pattern[i%3])
+	}
+	months[12] = nearestMonday(time.Date(year+1, start, 1, 0, 0, 0, 0, time.UTC))
+	return months
+}
+
+// CalendarColumnsFor returns the calendar_display_columns that fit the
+// longest of periods in rows of at most four months.
+func CalendarColumnsFor(periods []TimePeriod) int {
+	span := 1
+	for i := range periods {
+		span = max(span, periods[i].MonthSpan())
+	}
+	rows := (span + 3) / 4
+	return (span + rows - 1) / rows
+}
+
+// PeriodPattern is the calendar of an existing time period file: the spec
+// that generates its periods, and the formats of their keys and names, in
+// which {year}, {name} and {NAME} stand for the generated period's year,
+// name and upper-cased name.
+type PeriodPattern struct {
+	Spec       PeriodSpec
+	KeyFormat  string
+	NameFormat string
+}
+
+// Generate returns the periods of years fiscal years from year, keyed and
+// named like the file p was inferred from.
+func (p PeriodPattern) Generate(year, years int) ([]TimePeriod, error) {
+	spec := p.Spec
+	spec.Year, spec.Years = year, years
+	periods, err := GeneratePeriods(spec)
+	if err != nil {
+		return nil, err
+	}
+	for i, g := range periods {
+		periods[i].Key = applyPeriodFormat(p.KeyFormat, g)
+		periods[i].Name = applyPeriodFormat(p.NameFormat, g)
+	}
+	return periods, nil
+}
+
+// InferPeriodPattern finds the calendar periods follow: the first spec
+// whose generated periods have the same dates as the last four of periods
+// and whose keys and names map onto theirs by one format each. Calendar
+// years are tried before fiscal ones, and ISO weeks before retail weeks.
+func InferPeriodPattern(periods []TimePeriod) (PeriodPattern, bool) {
+	if len(periods) == 0 {
+		return PeriodPattern{}, false
+	}
+	recent := make([]TimePeriod, len(periods))
+	copy(recent, periods)
+	sort.SliceStable(recent, func(i, j int) bool { return recent[i].StartDate.Before(recent[j].StartDate) })
+	recent = recent[max(0, len(recent)-4):]
+	last := recent[len(recent)-1]
+
+	var specs []PeriodSpec
+	for _, unit := range []string{UnitQuarters, UnitMonths, UnitHalves, UnitYears, UnitWeeks} {
+		for _, pattern := range []string{"", "4-4-5", "4-5-4", "5-4-4"} {
+			for m := time.January; m <= time.December; m++ {
+				specs = append(specs, PeriodSpec{Unit: unit, StartMonth: m, Pattern: pattern})
+				if unit == UnitWeeks && pattern == "" {
+					break // ISO weeks ignore the start month
+				}
+			}
+		}
+	}
+
+	dates := func(tp TimePeriod) string {
+		return tp.StartDate.Format(BadgeDateFormat) + "|" + tp.EndDate.Format(BadgeDateFormat)
+	}
+	for _, spec := range specs {
+		spec.Year, spec.Years = last.EndDate.Year()-2, 4
+		generated, err := GeneratePeriods(spec)
+		if err != nil {
+			continue
+		}
+		byDates := map[string]TimePeriod{}
+		for _, g := range generated {
+			byDates[dates(g)] = g
+		}
+		g, ok := byDates[dates(last)]
+		if !ok {
+			continue
+		}
+		p := PeriodPattern{
+			Spec:       PeriodSpec{Unit: spec.Unit, StartMonth: spec.StartMonth, Pattern: spec.Pattern},
+			KeyFormat:  periodFormat(last.Key, g),
+			NameFormat: periodFormat(last.Name, g),
+		}
+		if !strings.Contains(p.KeyFormat, "{year}") {
+			continue
+		}
+		matches := true
+		for _, tp := range recent {
+			g, ok := byDates[dates(tp)]
+			if !ok || applyPeriodFormat(p.KeyFormat, g) != tp.Key || applyPeriodFormat(p.NameFormat, g) != tp.Name {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return p, true
+		}
+	}
+	return PeriodPattern{}, false
+}
+
+// Rollover returns the periods that extend td through the fiscal year
+// holding date, following the calendar InferPeriodPattern finds in td: the
+// rest of the fiscal year of td's last period and the years after it. It
+// returns nil if date isn't past td's last period, and an error if no
+// calendar fits td's periods.
+func (td *TimePeriodData) Rollover(date time.Time) ([]TimePeriod, error) {
+	if len(td.periods) == 0 {
+		return nil, nil
+	}
+	lastEnd := td.periods[0].EndDate
+	for _, tp := range td.periods[1:] {
+		if tp.EndDate.After(lastEnd) {
+			lastEnd = tp.EndDate
+		}
+	}
+	if !date.After(lastEnd) {
+		return nil, nil
+	}
+	p, ok := InferPeriodPattern(td.periods)
+	if !ok {
+		return nil, fmt.Errorf("can't tell which calendar %s follows; extend it with rto periods generate", td.filename)
+	}
+
+	var periods []TimePeriod
+	for fy := lastEnd.Year() - 1; fy <= date.Year()+1; fy++ {
+		generated, err := p.Generate(fy, 1)
+		if err != nil {
+			return nil, err
+		}
+		covered := false
+		for _, tp := range generated {
+			if tp.StartDate.After(lastEnd) {
+				periods = append(periods, tp)
+				covered = covered || (!date.Before(tp.StartDate) && !date.After(tp.EndDate))
+			}
+		}
+		if covered {
+			break
+		}
+	}
+	return periods, nil
+}
+
+// periodFormat turns s, the key or name of a period generated as g, into a
+// format for applyPeriodFormat.
+func periodFormat(s string, g TimePeriod) string {
+	s = strings.ReplaceAll(s, periodYear(g), "{year}")
+	s = strings.ReplaceAll(s, g.Name, "{name}")
+	return strings.ReplaceAll(s, strings.ToUpper(g.Name), "{NAME}")
+}
+
+func applyPeriodFormat(format string, g TimePeriod) string {
+	return strings.NewReplacer("{year}", periodYear(g), "{name}", g.Name, "{NAME}", strings.ToUpper(g.Name)).Replace(format)
+}
+
+// periodYear returns the year a generated period's key ends in.
+func periodYear(g TimePeriod) string {
+	return g.Key[max(0, len(g.Key)-4):]
+}
+
+// ParseMonth parses a month number (1–12), name, or an abbreviation of at
+// least three letters (e.g. "10", "oct", "October").
+func ParseMonth(s string) (time.Month, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if n, err := strconv.Atoi(s); err == nil && n >= 1 && n <= 12 {
+		return time.Month(n), nil
+	}
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if s == name || (len(s) >= 3 && strings.HasPrefix(name, s)) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown month %q", s)
+}
+
+// nearestMonday returns the Monday nearest d, d itself if it is one.
+func nearestMonday(d time.Time) time.Time {
+	offset := (int(time.Monday) - int(d.Weekday()) + 7) % 7 // days to the next Monday
+	if offset > 3 {
+		offset -= 7
+	}
+	return d.AddDate(0, 0, offset)
+}
+
+// isoWeekOne returns the Monday of ISO week 1 of year: the week holding
+// January 4.
+func isoWeekOne(year int) time.Time {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	return jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+}
+
+func isoYear(d time.Time) int {
+	y, _ := d.ISOWeek()
+	return y
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func periodRanges(periods []TimePeriod) map[string]string {
+	m := map[string]string{}
+	for _, tp := range periods {
+		m[tp.Key] = tp.Name + " " + tp.StartDateRaw + " " + tp.EndDateRaw
+	}
+	return m
+}
+
+func TestDefaultTimePeriods(t *testing.T) {
+	periods := DefaultTimePeriods()
+	if len(periods) != 8 {
+		t.Fatalf("expected 8 quarters, got %d", len(periods))
+	}
+	got := periodRanges(periods)
+	for key, want := range map[string]string{
+		"Q1_2025": "Q1 2025-01-01 2025-03-31",
+		"Q2_2025": "Q2 2025-04-01 2025-06-30",
+		"Q4_2026": "Q4 2026-10-01 2026-12-31",
+	} {
+		if got[key] != want {
+			t.Errorf("%s = %q, want %q", key, got[key], want)
+		}
+	}
+}
+
+func TestGenerateFiscalPeriods(t *testing.T) {
+	periods, err := GeneratePeriods(PeriodSpec{Unit: UnitQuarters, StartMonth: time.October, Year: 2026})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := periodRanges(periods)
+	if len(periods) != 4 || got["Q1_2026"] != "Q1 2025-10-01 2025-12-31" || got["Q4_2026"] != "Q4 2026-07-01 2026-09-30" {
+		t.Errorf("unexpected fiscal quarters: %v", got)
+	}
+
+	periods, _ = GeneratePeriods(PeriodSpec{Unit: UnitYears, StartMonth: time.July, Year: 2026, Years: 2})
+	got = periodRanges(periods)
+	if got["FY2027"] != "FY2027 2026-07-01 2027-06-30" {
+		t.Errorf("unexpected fiscal years: %v", got)
+	}
+
+	periods, _ = GeneratePeriods(PeriodSpec{Unit: UnitHalves, Year: 2026})
+	if got := periodRanges(periods); got["H2_2026"] != "H2 2026-07-01 2026-12-31" || CalendarColumnsFor(periods) != 3 {
+		t.Errorf("unexpected halves: %v, %d columns", got, CalendarColumnsFor(periods))
+	}
+
+	periods, _ = GeneratePeriods(PeriodSpec{Unit: UnitMonths, StartMonth: time.October, Year: 2026})
+	if got := periodRanges(periods); len(periods) != 12 || got["OCT_2025"] != "Oct 2025-10-01 2025-10-31" || got["FEB_2026"] != "Feb 2026-02-01 2026-02-28" {
+		t.Errorf("unexpected months: %v", got)
+	}
+}
+
+func TestGenerateRetailPeriods(t *testing.T) {
+	// FY2026 starting in February: Monday Feb 3, 2025 to Sunday Feb 1, 2026.
+	periods, err := GeneratePeriods(PeriodSpec{Unit: UnitMonths, StartMonth: time.February, Pattern: "4-4-5", Year: 2026, Years: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := periodRanges(periods)
+	if got["P1_2026"] != "P1 2025-02-03 2025-03-02" || got["P3_2026"] != "P3 2025-03-31 2025-05-04" || got["P12_2026"] != "P12 2025-12-29 2026-02-01" {
+		t.Errorf("unexpected 4-4-5 months: %v", got)
+	}
+	var long []string
+	for i, tp := range periods {
+		if i > 0 && !tp.StartDate.Equal(periods[i-1].EndDate.AddDate(0, 0, 1)) {
+			t.Fatalf("%s doesn't follow %s", tp.Key, periods[i-1].Key)
+		}
+		if tp.StartDate.Weekday() != time.Monday {
+			t.Errorf("%s starts on a %s", tp.Key, tp.StartDate.Weekday())
+		}
+		if tp.Name == "P12" && tp.EndDate.Sub(tp.StartDate) == 41*24*time.Hour {
+			long = append(long, tp.Key)
+		}
+	}
+	if len(long) != 1 || long[0] != "P12_2030" {
+		t.Errorf("expected FY2030 alone to have 53 weeks, got %v", long)
+	}
+
+	periods, _ = GeneratePeriods(PeriodSpec{Unit: UnitQuarters, StartMonth: time.February, Pattern: "5-4-4", Year: 2026})
+	if got := periodRanges(periods); got["Q1_2026"] != "Q1 2025-02-03 2025-05-04" || CalendarColumnsFor(periods) != 4 {
+		t.Errorf("unexpected retail quarters: %v, %d columns", got, CalendarColumnsFor(periods))
+	}
+}
+
+func TestGenerateISOWeeks(t *testing.T) {
+	periods, err := GeneratePeriods(PeriodSpec{Unit: UnitWeeks, Year: 2026})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := periodRanges(periods)
+	if len(periods) != 53 || got["W01_2026"] != "W01 2025-12-29 2026-01-04" || got["W53_2026"] != "W53 2026-12-28 2027-01-03" {
+		t.Errorf("unexpected ISO weeks (%d): W01 %q, W53 %q", len(periods), got["W01_2026"], got["W53_2026"])
+	}
+	if CalendarColumnsFor(periods) != 2 {
+		t.Errorf("expected 2 columns for weeks spanning two months, got %d", CalendarColumnsFor(periods))
+	}
+
+	for _, bad := range []PeriodSpec{{Unit: "fortnights", Year: 2026}, {Unit: UnitMonths, Pattern: "4-4-4", Year: 2026},
+		{Unit: UnitMonths, StartMonth: 13, Year: 2026}, {Unit: UnitMonths}} {
+		if _, err := GeneratePeriods(bad); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
+
+func TestInferPeriodPattern(t *testing.T) {
+	quarters, _ := GeneratePeriods(PeriodSpec{Unit: UnitQuarters, StartMonth: time.October, Year: 2026})
+	for i := range quarters {
+		// Keys like "FY2026-Q1" and names like "Q1 FY2026" instead of the generator's own.
+		quarters[i].Key = "FY2026-" + quarters[i].Name
+		quarters[i].Name = quarters[i].Name + " FY2026"
+	}
+	p, ok := InferPeriodPattern(quarters)
+	if !ok || p.Spec.Unit != UnitQuarters || p.Spec.StartMonth != time.October || p.KeyFormat != "FY{year}-{name}" {
+		t.Fatalf("unexpected pattern: %+v, %v", p, ok)
+	}
+	next, _ := p.Generate(2027, 1)
+	if got := periodRanges(next); got["FY2027-Q2"] != "Q2 FY2027 2027-01-01 2027-03-31" {
+		t.Errorf("unexpected next year: %v", got)
+	}
+
+	retail, _ := GeneratePeriods(PeriodSpec{Unit: UnitMonths, StartMonth: time.February, Pattern: "4-5-4", Year: 2026})
+	if p, ok := InferPeriodPattern(retail); !ok || p.Spec.Pattern != "4-5-4" || p.Spec.StartMonth != time.February {
+		t.Errorf("expected a 4-5-4 calendar starting in February, got %+v, %v", p, ok)
+	}
+	weeks, _ := GeneratePeriods(PeriodSpec{Unit: UnitWeeks, Year: 2026})
+	if p, ok := InferPeriodPattern(weeks); !ok || p.Spec.Pattern != "" || p.KeyFormat != "{name}_{year}" {
+		t.Errorf("expected ISO weeks, got %+v, %v", p, ok)
+	}
+
+	odd := []TimePeriod{{Key: "SPRINT_1", Name: "Sprint 1", StartDate: date("2026-01-05"), EndDate: date("2026-01-18")}}
+	if p, ok := InferPeriodPattern(odd); ok {
+		t.Errorf("expected no pattern for a two-week sprint, got %+v", p)
+	}
+}
+
+func TestRollover(t *testing.T) {
+	td := NewTimePeriodData()
+	for _, tp := range DefaultTimePeriods() {
+		td.Add(tp)
+	}
+	if periods, err := td.Rollover(date("2026-10-16")); periods != nil || err != nil {
+		t.Errorf("expected no rollover inside the periods, got %v, %v", periods, err)
+	}
+	periods, err := td.Rollover(date("2027-01-04"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := periodRanges(periods); len(periods) != 4 || got["Q1_2027"] != "Q1 2027-01-01 2027-03-31" {
+		t.Errorf("expected the quarters of 2027, got %v", got)
+	}
+	// Two years behind: both missing years are added.
+	if periods, _ := td.Rollover(date("2028-05-01")); len(periods) != 8 || periods[7].Key != "Q4_2028" {
+		t.Errorf("expected the quarters of 2027 and 2028, got %d", len(periods))
+	}
+
+	// A file ending mid-year is completed first.
+	partial := NewTimePeriodData()
+	for _, tp := range DefaultTimePeriods()[:6] {
+		partial.Add(tp)
+	}
+	if periods, _ := partial.Rollover(date("2026-07-01")); len(periods) != 2 || periods[0].Key != "Q3_2026" {
+		t.Errorf("expected Q3 and Q4 of 2026, got %v", periodRanges(periods))
+	}
+
+	sprints := NewTimePeriodDataWithFile("sprints.yaml")
+	sprints.Add(TimePeriod{Key: "SPRINT_1", Name: "Sprint 1", StartDate: date("2026-01-05"), EndDate: date("2026-01-18")})
+	if _, err := sprints.Rollover(date("2026-02-01")); err == nil || !strings.Contains(err.Error(), "sprints.yaml") {
+		t.Errorf("expected an error naming the file, got %v", err)
+	}
+}
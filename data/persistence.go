@@ -43,23 +43,60 @@ func loadFile(path string) ([]byte, error) {
 	return data, nil
 }
 
+// writeFileAtomic writes data to a temp file in the same directory, fsyncs
+// it, and renames it over path, so readers never observe a partially written
+// file even if the process is killed mid-write.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file for %s: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmpPath)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		cleanup()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		cleanup()
+		return fmt.Errorf("syncing %s: %w", path, err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		cleanup()
+		return fmt.Errorf("setting permissions on %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("closing %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("replacing %s: %w", path, err)
+	}
+
+	// Persist the rename itself; not all platforms support syncing a directory.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
 // LoadJSON deserializes JSON from a file in the global data directory.
 // If the file doesn't exist, v is left unchanged (no error).
 func LoadJSON(filename string, v interface{}) error {
 	return LoadJSONFrom(GetDataDir(), filename, v)
 }
 
-// LoadJSONFrom deserializes JSON from a file in the given directory.
+// LoadJSONFrom deserializes JSON from a file in the given directory, through
+// a DirStore like every other document; the extension selects the encoding.
 func LoadJSONFrom(dir, filename string, v interface{}) error {
-	path := filepath.Join(dir, filename)
-	data, err := loadFile(path)
-	if err != nil {
-		return err
-	}
-	if data == nil {
-		return nil
-	}
-	return json.Unmarshal(data, v)
+	return loadDocument(NewDirStore(dir), filename, v)
 }
 
 // SaveJSON serializes v as JSON and writes to a file in the global data directory.
@@ -67,17 +104,19 @@ func SaveJSON(filename string, v interface{}) error {
 	return SaveJSONTo(GetDataDir(), filename, v)
 }
 
-// SaveJSONTo serializes v as JSON and writes to a file in the given directory.
+// SaveJSONTo serializes v as JSON and atomically writes it to a file in the
+// given directory, through a DirStore.
 func SaveJSONTo(dir, filename string, v interface{}) error {
-	path := filepath.Join(dir, filename)
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("creating directories for %s: %w", path, err)
-	}
+	return saveDocument(NewDirStore(dir), filename, v)
+}
+
+// marshalJSON serializes v as indented JSON.
+func marshalJSON(filename string, v interface{}) ([]byte, error) {
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
-		return fmt.Errorf("marshaling JSON for %s: %w", filename, err)
+		return nil, fmt.Errorf("marshaling JSON for %s: %w", filename, err)
 	}
-	return os.WriteFile(path, data, 0644)
+	return data, nil
 }
 
 // LoadYAML deserializes YAML from a file in the global data directory.
@@ -86,17 +125,10 @@ func LoadYAML(filename string, v interface{}) error {
 	return LoadYAMLFrom(GetDataDir(), filename, v)
 }
 
-// LoadYAMLFrom deserializes YAML from a file in the given directory.
+// LoadYAMLFrom deserializes YAML from a file in the given directory, through
+// a DirStore like every other document; the extension selects the encoding.
 func LoadYAMLFrom(dir, filename string, v interface{}) error {
-	path := filepath.Join(dir, filename)
-	data, err := loadFile(path)
-	if err != nil {
-		return err
-	}
-	if data == nil {
-		return nil
-	}
-	return yaml.Unmarshal(data, v)
+	return loadDocument(NewDirStore(dir), filename, v)
 }
 
 // SaveYAML serializes v as YAML and writes to a file in the global data directory.
@@ -104,18 +136,19 @@ func SaveYAML(filename string, v interface{}) error {
 	return SaveYAMLTo(GetDataDir(), filename, v)
 }
 
-// SaveYAMLTo serializes v as YAML and writes to a file in the given directory.
-// String values (but not mapping keys) are always double-quoted so the output
-// matches the hand-written style used for holidays, vacations, and settings.
+// SaveYAMLTo serializes v as YAML and atomically writes it to a file in the
+// given directory, through a DirStore. String values (but not mapping keys)
+// are always double-quoted so the output matches the hand-written style used
+// for holidays, vacations, and settings.
 func SaveYAMLTo(dir, filename string, v interface{}) error {
-	path := filepath.Join(dir, filename)
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("creating directories for %s: %w", path, err)
-	}
+	return saveDocument(NewDirStore(dir), filename, v)
+}
 
+// marshalYAML serializes v in the repo's YAML style (see SaveYAMLTo).
+func marshalYAML(filename string, v interface{}) ([]byte, error) {
 	var node yaml.Node
 	if err := node.Encode(v); err != nil {
-		return fmt.Errorf("encoding YAML for %s: %w", filename, err)
+		return nil, fmt.Errorf("encoding YAML for %s: %w", filename, err)
 	}
 	quoteStringValues(&node)
 
@@ -123,13 +156,34 @@ func SaveYAMLTo(dir, filename string, v interface{}) error {
 	enc := yaml.NewEncoder(&buf)
 	enc.SetIndent(2)
 	if err := enc.Encode(&node); err != nil {
-		return fmt.Errorf("marshaling YAML for %s: %w", filename, err)
+		return nil, fmt.Errorf("marshaling YAML for %s: %w", filename, err)
 	}
 	if err := enc.Close(); err != nil {
-		return fmt.Errorf("finalizing YAML for %s: %w", filename, err)
+		return nil, fmt.Errorf("finalizing YAML for %s: %w", filename, err)
 	}
 
-	return os.WriteFile(path, dedentSequences(buf.Bytes(), 2), 0644)
+	return dedentSequences(buf.Bytes(), 2), nil
+}
+
+// isJSONFile reports whether filename should be encoded as JSON rather than YAML.
+func isJSONFile(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".json")
+}
+
+// marshalDocument encodes v using the format implied by the filename extension.
+func marshalDocument(filename string, v interface{}) ([]byte, error) {
+	if isJSONFile(filename) {
+		return marshalJSON(filename, v)
+	}
+	return marshalYAML(filename, v)
+}
+
+// unmarshalDocument decodes data using the format implied by the filename extension.
+func unmarshalDocument(filename string, data []byte, v interface{}) error {
+	if isJSONFile(filename) {
+		return json.Unmarshal(data, v)
+	}
+	return yaml.Unmarshal(data, v)
 }
 
 // dedentSequences removes the extra indentation that yaml.Encoder adds to
@@ -123,3 +123,43 @@ func TestSaveYAMLCreatesNestedDirs(t *testing.T) {
 		t.Errorf("file not created: %v", err)
 	}
 }
+
+func TestSaveJSONLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	type S struct{ X int }
+	for i := 0; i < 3; i++ {
+		if err := SaveJSONTo(dir, "test.json", &S{X: i}); err != nil {
+			t.Fatalf("save error: %v", err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("readdir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "test.json" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("expected only test.json, got %v", names)
+	}
+}
+
+func TestWriteFileAtomicReplacesContent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "f.yaml")
+	if err := os.WriteFile(path, []byte("old content that is longer"), 0600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := writeFileAtomic(path, []byte("new"), 0644); err != nil {
+		t.Fatalf("atomic write: %v", err)
+	}
+	b, _ := os.ReadFile(path)
+	if string(b) != "new" {
+		t.Errorf("expected new content, got %q", b)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("expected mode 0644, got %v", info.Mode().Perm())
+	}
+}
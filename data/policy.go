@@ -0,0 +1,282 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const policyFilename = "policy.yaml"
+
+// Rounding modes for Policy.Rounding: how a fractional number of required
+// days becomes a whole one.
+const (
+	RoundCeil    = "ceil" // default
+	RoundNearest = "round"
+	RoundFloor   = "floor"
+)
+
+// Holiday modes for Policy.Holidays.
+const (
+	HolidaysExclude = "exclude" // holidays are removed from the total days (default)
+	HolidaysInclude = "include" // holidays count as ordinary working days
+)
+
+// Policy is the compliance policy in policy.yaml. Every field is optional;
+// without the file, DefaultPolicy applies the goal from settings.yaml.
+type Policy struct {
+	Name     string     `yaml:"name,omitempty"`
+	Goal     int        `yaml:"goal,omitempty"`     // percent of total days; default settings.yaml goal
+	Rounding string     `yaml:"rounding,omitempty"` // RoundCeil, RoundNearest or RoundFloor
+	Flex     FlexPolicy `yaml:"flex,omitempty"`
+	Holidays string     `yaml:"holidays,omitempty"` // HolidaysExclude or HolidaysInclude
+
+	// Leave overrides the rule (LeaveExclude, LeaveAttend or LeaveAbsent)
+	// for a leave type; see LeaveRule for the defaults.
+	Leave map[LeaveType]string `yaml:"leave,omitempty"`
+
+	// StatusBands name the status of a period that is neither achieved nor
+	// impossible, by how far ahead of pace it is. They are checked in order
+	// and the first match wins.
+	StatusBands []StatusBand `yaml:"status_bands,omitempty"`
+}
+
+// FlexPolicy says whether, how many, and how much flex credits count
+// toward the goal.
+type FlexPolicy struct {
+	Count  *bool   `yaml:"count,omitempty"`  // default true
+	Cap    int     `yaml:"cap,omitempty"`    // most flex credits counted per period; 0 means no limit
+	Weight float64 `yaml:"weight,omitempty"` // days each counted credit is worth, e.g. 0.5; default 1
+}
+
+// Counts reports whether flex credits count toward the goal at all.
+func (f FlexPolicy) Counts() bool {
+	return f.Count == nil || *f.Count
+}
+
+// Credit returns the days a counted flex credit is worth.
+func (f FlexPolicy) Credit() float64 {
+	if f.Weight == 0 {
+		return 1
+	}
+	return f.Weight
+}
+
+// Allows reports whether a period with used flex credits counted can count
+// one more.
+func (f FlexPolicy) Allows(used int) bool {
+	return f.Counts() && (f.Cap == 0 || used < f.Cap)
+}
+
+// LeaveRule returns how a day of leave of type t counts: the rule in
+// policy.yaml, or by default LeaveAttend for business travel, LeaveAbsent
+// for unpaid leave and LeaveExclude for everything else.
+func (p *Policy) LeaveRule(t LeaveType) string {
+	if r, ok := p.Leave[t]; ok {
+		return r
+	}
+	if r, ok := defaultLeaveRules[t]; ok {
+		return r
+	}
+	return LeaveExclude
+}
+
+// StatusBand is one entry of Policy.StatusBands.
+type StatusBand struct {
+	Name    string `yaml:"name"`
+	MinPace *int   `yaml:"min_pace,omitempty"` // days ahead of pace, negative when behind; empty matches any pace
+	Color   string `yaml:"color,omitempty"`    // TUI color, an ANSI 256-color number
+}
+
+// DefaultStatusBands returns the bands used when policy.yaml has none:
+// On Track at or ahead of pace, At Risk behind it.
+func DefaultStatusBands() []StatusBand {
+	zero := 0
+	return []StatusBand{
+		{Name: "On Track", MinPace: &zero, Color: "40"},
+		{Name: "At Risk", Color: "208"},
+	}
+}
+
+// DefaultPolicy returns the policy used without a policy.yaml: goal percent
+// of the days that aren't holidays or vacation, rounded up, counting every
+// flex credit.
+func DefaultPolicy(goal int) *Policy {
+	return &Policy{
+		Name:        "Default",
+		Goal:        goal,
+		Rounding:    RoundCeil,
+		Holidays:    HolidaysExclude,
+		StatusBands: DefaultStatusBands(),
+	}
+}
+
+// LoadPolicy reads policy.yaml from the global store; see LoadPolicyFromStore.
+func LoadPolicy(settings *AppSettings) (*Policy, error) {
+	return LoadPolicyFromStore(GetStore(), settings)
+}
+
+// LoadPolicyFromStore reads and validates policy.yaml from the given store.
+// Fields it leaves out take their defaults, with the goal from settings.
+func LoadPolicyFromStore(st Store, settings *AppSettings) (*Policy, error) {
+	p := DefaultPolicy(settings.Goal)
+	if !st.Exists(policyFilename) {
+		return p, nil
+	}
+	var loaded Policy
+	if err := loadDocument(st, policyFilename, &loaded); err != nil {
+		return nil, err
+	}
+	p.Name = policyFilename
+	if loaded.Name != "" {
+		p.Name = loaded.Name
+	}
+	if loaded.Goal != 0 {
+		p.Goal = loaded.Goal
+	}
+	if loaded.Rounding != "" {
+		p.Rounding = loaded.Rounding
+	}
+	if loaded.Holidays != "" {
+		p.Holidays = loaded.Holidays
+	}
+	p.Flex = loaded.Flex
+	p.Leave = loaded.Leave
+	if len(loaded.StatusBands) > 0 {
+		p.StatusBands = loaded.StatusBands
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", policyFilename, err)
+	}
+	return p, nil
+}
+
+// Validate checks the goal, the modes, the flex cap, the leave rules and
+// the status bands.
+func (p *Policy) Validate() error {
+	if p.Goal < 1 || p.Goal > 100 {
+		return fmt.Errorf("goal must be between 1 and 100, got %d", p.Goal)
+	}
+	switch p.Rounding {
+	case RoundCeil, RoundNearest, RoundFloor:
+	default:
+		return fmt.Errorf("unknown rounding %q (expected %s, %s or %s)", p.Rounding, RoundCeil, RoundNearest, RoundFloor)
+	}
+	switch p.Holidays {
+	case HolidaysExclude, HolidaysInclude:
+	default:
+		return fmt.Errorf("unknown holidays %q (expected %s or %s)", p.Holidays, HolidaysExclude, HolidaysInclude)
+	}
+	if p.Flex.Cap < 0 {
+		return fmt.Errorf("flex: cap can't be negative")
+	}
+	if p.Flex.Weight < 0 || p.Flex.Weight > 1 {
+		return fmt.Errorf("flex: weight must be between 0 and 1, got %g", p.Flex.Weight)
+	}
+	for t, r := range p.Leave {
+		if !t.Valid() {
+			return fmt.Errorf("leave: unknown leave type %q (expected %s)", t, leaveTypeList())
+		}
+		switch r {
+		case LeaveExclude, LeaveAttend, LeaveAbsent:
+		default:
+			return fmt.Errorf("leave: unknown rule %q for %s (expected %s, %s or %s)", r, t, LeaveExclude, LeaveAttend, LeaveAbsent)
+		}
+	}
+	for i, b := range p.StatusBands {
+		if b.Name == "" {
+			return fmt.Errorf("status_bands entry %d: name is required", i+1)
+		}
+		if b.MinPace == nil && i < len(p.StatusBands)-1 {
+			return fmt.Errorf("status_bands entry %d: only the last band may omit min_pace", i+1)
+		}
+	}
+	return nil
+}
+
+// Proration rules for WeeklyMinimum: how holidays, vacation days and
+// period boundaries lower a week's minimum.
+const (
+	ProrateProportional = "proportional" // scale by available / scheduled days, rounded
+	ProrateSubtract     = "subtract"     // each unavailable day counts as an office day
+	ProrateNone         = "none"         // full minimum, capped at the available days
+)
+
+// WeeklyMinimum is the weekly_minimum block of settings.yaml: a policy of at
+// least Days office days in every ISO week, evaluated alongside the period
+// goal. It is off when Days is zero.
+type WeeklyMinimum struct {
+	Days      int    `yaml:"days"`
+	Proration string `yaml:"proration,omitempty"` // default ProrateProportional
+}
+
+// Enabled reports whether a weekly minimum is configured.
+func (w WeeklyMinimum) Enabled() bool {
+	return w.Days > 0
+}
+
+// Rule returns the proration rule, applying the default.
+func (w WeeklyMinimum) Rule() string {
+	if w.Proration == "" {
+		return ProrateProportional
+	}
+	return w.Proration
+}
+
+// Validate checks the day count and proration rule.
+func (w WeeklyMinimum) Validate() error {
+	if w.Days < 0 || w.Days > 7 {
+		return fmt.Errorf("weekly_minimum: days must be between 0 and 7, got %d", w.Days)
+	}
+	switch w.Rule() {
+	case ProrateProportional, ProrateSubtract, ProrateNone:
+		return nil
+	}
+	return fmt.Errorf("weekly_minimum: unknown proration %q (expected %s, %s or %s)",
+		w.Proration, ProrateProportional, ProrateSubtract, ProrateNone)
+}
+
+// RollingWindow is the rolling_window setting: a trailing window such as
+// "12w" or "90d" over which attendance is averaged, independent of the time
+// period files. It is off when empty.
+type RollingWindow string
+
+// Enabled reports whether a rolling window is configured.
+func (r RollingWindow) Enabled() bool {
+	return r != ""
+}
+
+// Days returns the window length in calendar days.
+func (r RollingWindow) Days() (int, error) {
+	return ParseWindow(string(r))
+}
+
+// ParseWindow parses a window length of whole weeks ("12w") or days
+// ("90d") and returns it in calendar days.
+func ParseWindow(s string) (int, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	unit := 0
+	switch {
+	case strings.HasSuffix(v, "w"):
+		unit = 7
+	case strings.HasSuffix(v, "d"):
+		unit = 1
+	}
+	if unit == 0 {
+		return 0, fmt.Errorf("invalid window %q (expected weeks like 12w or days like 90d)", s)
+	}
+	n, err := strconv.Atoi(v[:len(v)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid window %q (expected weeks like 12w or days like 90d)", s)
+	}
+	return n * unit, nil
+}
+
+// FormatWindow describes a window length in days, in weeks when it is a
+// whole number of them.
+func FormatWindow(days int) string {
+	if days%7 == 0 {
+		return fmt.Sprintf("%d weeks", days/7)
+	}
+	return fmt.Sprintf("%d days", days)
+}
@@ -0,0 +1,96 @@
+package data
+
+import "testing"
+
+func TestWeeklyMinimumValidate(t *testing.T) {
+	valid := []WeeklyMinimum{{}, {Days: 3}, {Days: 3, Proration: ProrateSubtract}, {Days: 7, Proration: ProrateNone}}
+	for _, w := range valid {
+		if err := w.Validate(); err != nil {
+			t.Errorf("%+v: unexpected error %v", w, err)
+		}
+	}
+	invalid := []WeeklyMinimum{{Days: -1}, {Days: 8}, {Days: 3, Proration: "round"}}
+	for _, w := range invalid {
+		if err := w.Validate(); err == nil {
+			t.Errorf("%+v: expected an error", w)
+		}
+	}
+	if (WeeklyMinimum{Days: 3}).Rule() != ProrateProportional {
+		t.Error("proration should default to proportional")
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := map[string]int{"12w": 84, "90d": 90, " 4W ": 28, "1d": 1}
+	for in, want := range tests {
+		if got, err := ParseWindow(in); err != nil || got != want {
+			t.Errorf("ParseWindow(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "12", "w", "0w", "-2w", "3m", "1.5w", "12dw"} {
+		if _, err := ParseWindow(in); err == nil {
+			t.Errorf("ParseWindow(%q): expected an error", in)
+		}
+	}
+	if FormatWindow(84) != "12 weeks" || FormatWindow(90) != "90 days" {
+		t.Errorf("unexpected FormatWindow: %s, %s", FormatWindow(84), FormatWindow(90))
+	}
+}
+
+func TestParseLeaveType(t *testing.T) {
+	tests := map[string]LeaveType{"": LeaveVacation, "Sick": LeaveSick, "jury-duty": LeaveJuryDuty, "jury duty": LeaveJuryDuty, "travel": LeaveTravel}
+	for in, want := range tests {
+		if got, err := ParseLeaveType(in); err != nil || got != want {
+			t.Errorf("ParseLeaveType(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseLeaveType("holiday"); err == nil {
+		t.Error("expected an error for an unknown type")
+	}
+
+	p := DefaultPolicy(50)
+	if p.LeaveRule(LeaveTravel) != LeaveAttend || p.LeaveRule(LeaveUnpaid) != LeaveAbsent || p.LeaveRule(LeaveSick) != LeaveExclude {
+		t.Error("unexpected default leave rules")
+	}
+	p.Leave = map[LeaveType]string{LeaveSick: LeaveAbsent}
+	if p.LeaveRule(LeaveSick) != LeaveAbsent || p.LeaveRule(LeaveVacation) != LeaveExclude {
+		t.Error("policy.yaml should override only the types it lists")
+	}
+}
+
+func TestLoadPolicyFromStore(t *testing.T) {
+	st := NewDirStore(t.TempDir())
+	settings := DefaultAppSettings()
+	settings.Goal = 60
+
+	p, err := LoadPolicyFromStore(st, &settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Default" || p.Goal != 60 || p.Rounding != RoundCeil || p.Holidays != HolidaysExclude || !p.Flex.Counts() {
+		t.Errorf("without policy.yaml expected the default policy, got %+v", p)
+	}
+
+	doc := "name: Division 3\nrounding: round\nflex:\n  cap: 4\nstatus_bands:\n  - name: Fine\n    min_pace: -1\n  - name: Behind\n"
+	if err := st.Write(policyFilename, []byte(doc)); err != nil {
+		t.Fatal(err)
+	}
+	p, err = LoadPolicyFromStore(st, &settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Division 3" || p.Goal != 60 || p.Rounding != RoundNearest || p.Flex.Cap != 4 || len(p.StatusBands) != 2 {
+		t.Errorf("unexpected policy %+v", p)
+	}
+
+	for _, bad := range []string{"goal: 120\n", "rounding: up\n", "holidays: maybe\n", "flex:\n  cap: -1\n", "flex:\n  weight: 1.5\n",
+		"leave:\n  sick: attend\n  holiday: exclude\n", "leave:\n  sick: maybe\n",
+		"status_bands:\n  - name: A\n  - name: B\n    min_pace: 0\n"} {
+		if err := st.Write(policyFilename, []byte(bad)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicyFromStore(st, &settings); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
@@ -34,6 +34,7 @@ func (tp *TimePeriod) ParseDates() error {
 }
 
 type timePeriodDataFile struct {
+	SchemaVersion          int          `yaml:"schema_version"`
 	CalendarDisplayColumns int          `yaml:"calendar_display_columns,omitempty"`
 	TimePeriods            []TimePeriod `yaml:"timeperiods"`
 }
@@ -83,20 +84,25 @@ func (td *TimePeriodData) SetCalendarDisplayColumns(cols int) {
 }
 
 func LoadTimePeriodData() (*TimePeriodData, error) {
-	dir := GetDataDir()
-	settings, err := LoadAppSettingsFrom(dir)
+	s := GetStore()
+	settings, err := LoadAppSettingsFromStore(s)
 	if err != nil {
-		return LoadTimePeriodDataFrom(dir, "")
+		return LoadTimePeriodDataFromStore(s, "")
 	}
-	return LoadTimePeriodDataFrom(dir, settings.ActiveTimePeriodFile(0))
+	return LoadTimePeriodDataFromStore(s, settings.ActiveTimePeriodFile(0))
 }
 
 func LoadTimePeriodDataFrom(dir string, filename string) (*TimePeriodData, error) {
+	return LoadTimePeriodDataFromStore(NewDirStore(dir), filename)
+}
+
+// LoadTimePeriodDataFromStore reads the named time period file from the given store.
+func LoadTimePeriodDataFromStore(s Store, filename string) (*TimePeriodData, error) {
 	if filename == "" {
 		filename = defaultTimePeriodsFilename
 	}
 	var file timePeriodDataFile
-	if err := LoadYAMLFrom(dir, filename, &file); err != nil {
+	if err := loadVersioned(s, filename, DocTimePeriods, &file); err != nil {
 		return nil, err
 	}
 	for i := range file.TimePeriods {
@@ -116,15 +122,24 @@ func LoadTimePeriodDataFrom(dir string, filename string) (*TimePeriodData, error
 }
 
 func (td *TimePeriodData) Save() error {
-	return td.SaveTo(GetDataDir())
+	return td.SaveToStore(GetStore())
 }
 
 func (td *TimePeriodData) SaveTo(dir string) error {
-	file := timePeriodDataFile{
+	return td.SaveToStore(NewDirStore(dir))
+}
+
+// SaveToStore writes the time period file to the given store.
+func (td *TimePeriodData) SaveToStore(s Store) error {
+	return saveDocument(s, td.filename, td.fileDocument())
+}
+
+func (td *TimePeriodData) fileDocument() interface{} {
+	return &timePeriodDataFile{
+		SchemaVersion:          CurrentSchemaVersion,
 		CalendarDisplayColumns: td.calendarDisplayColumns,
 		TimePeriods:            td.periods,
 	}
-	return SaveYAMLTo(dir, td.filename, &file)
 }
 
 func (td *TimePeriodData) All() []TimePeriod {
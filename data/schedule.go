@@ -0,0 +1,134 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WorkWeek is one entry of the work_schedule list in settings.yaml: the
+// weekdays worked from From until the next entry's From.
+type WorkWeek struct {
+	From string   `yaml:"from,omitempty"` // YYYY-MM-DD; may be empty on the first entry only
+	Days []string `yaml:"days"`           // weekday names or abbreviations, e.g. [mon, tue, wed, thu]
+}
+
+// WorkSchedule is a parsed, date-ranged work_schedule. The first week also
+// applies to dates before its From.
+type WorkSchedule struct {
+	starts []time.Time // zero for an open-ended first entry
+	days   [][7]bool   // indexed by time.Weekday
+}
+
+var activeSchedule = DefaultWorkSchedule()
+
+// DefaultWorkSchedule returns the Monday–Friday schedule used when
+// settings.yaml has no work_schedule.
+func DefaultWorkSchedule() *WorkSchedule {
+	var week [7]bool
+	for d := time.Monday; d <= time.Friday; d++ {
+		week[d] = true
+	}
+	return &WorkSchedule{starts: []time.Time{{}}, days: [][7]bool{week}}
+}
+
+// ParseWorkSchedule validates weeks and returns the schedule they describe.
+// An empty list yields DefaultWorkSchedule.
+func ParseWorkSchedule(weeks []WorkWeek) (*WorkSchedule, error) {
+	if len(weeks) == 0 {
+		return DefaultWorkSchedule(), nil
+	}
+	ws := &WorkSchedule{}
+	for i, w := range weeks {
+		var start time.Time
+		if w.From != "" {
+			t, err := time.Parse(BadgeDateFormat, w.From)
+			if err != nil {
+				return nil, fmt.Errorf("work_schedule entry %d: invalid from %q (expected YYYY-MM-DD)", i+1, w.From)
+			}
+			start = t
+		} else if i > 0 {
+			return nil, fmt.Errorf("work_schedule entry %d: from is required after the first entry", i+1)
+		}
+		if i > 0 && !start.After(ws.starts[i-1]) {
+			return nil, fmt.Errorf("work_schedule entry %d: from %s must be after the previous entry's", i+1, w.From)
+		}
+		if len(w.Days) == 0 {
+			return nil, fmt.Errorf("work_schedule entry %d: days can't be empty", i+1)
+		}
+		var week [7]bool
+		for _, name := range w.Days {
+			d, err := ParseWeekday(name)
+			if err != nil {
+				return nil, fmt.Errorf("work_schedule entry %d: %w", i+1, err)
+			}
+			week[d] = true
+		}
+		ws.starts = append(ws.starts, start)
+		ws.days = append(ws.days, week)
+	}
+	return ws, nil
+}
+
+// IsWorkday reports whether date is a working day under the schedule.
+func (ws *WorkSchedule) IsWorkday(date time.Time) bool {
+	return ws.week(date)[date.Weekday()]
+}
+
+// Weekdays returns the working weekdays in effect on date, Sunday first.
+func (ws *WorkSchedule) Weekdays(date time.Time) []time.Weekday {
+	week := ws.week(date)
+	var result []time.Weekday
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if week[d] {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+func (ws *WorkSchedule) week(date time.Time) [7]bool {
+	i := 0
+	for i+1 < len(ws.starts) && !date.Before(ws.starts[i+1]) {
+		i++
+	}
+	return ws.days[i]
+}
+
+// SetWorkSchedule sets the schedule used by IsWorkday. A nil schedule
+// restores DefaultWorkSchedule.
+func SetWorkSchedule(ws *WorkSchedule) {
+	if ws == nil {
+		ws = DefaultWorkSchedule()
+	}
+	activeSchedule = ws
+}
+
+// ActiveWorkSchedule returns the schedule set by SetWorkSchedule.
+func ActiveWorkSchedule() *WorkSchedule {
+	return activeSchedule
+}
+
+// IsWorkday reports whether date is a working day under the active schedule.
+func IsWorkday(date time.Time) bool {
+	return activeSchedule.IsWorkday(date)
+}
+
+// IsWeekend reports whether date is a Saturday or Sunday, whatever the
+// schedule says.
+func IsWeekend(date time.Time) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
+
+// ParseWeekday parses a weekday name or an abbreviation of at least two
+// letters (e.g. "mon", "Tu", "thursday").
+func ParseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || (len(s) >= 2 && strings.HasPrefix(name, s)) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
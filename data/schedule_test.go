@@ -0,0 +1,84 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, _ := time.Parse(BadgeDateFormat, s)
+	return t
+}
+
+func TestDefaultWorkSchedule(t *testing.T) {
+	ws := DefaultWorkSchedule()
+	// 2025-01-05 is a Sunday.
+	want := []bool{false, true, true, true, true, true, false}
+	for i, w := range want {
+		d := date("2025-01-05").AddDate(0, 0, i)
+		if got := ws.IsWorkday(d); got != w {
+			t.Errorf("%s: expected %v, got %v", d.Weekday(), w, got)
+		}
+	}
+}
+
+func TestParseWorkScheduleDateRanged(t *testing.T) {
+	ws, err := ParseWorkSchedule([]WorkWeek{
+		{Days: []string{"mon", "tue", "wed", "thu", "fri"}},
+		{From: "2025-07-01", Days: []string{"Sun", "monday", "tu", "wed", "thu"}},
+		{From: "2026-01-01", Days: []string{"mon", "tue", "wed"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cases := []struct {
+		date string
+		want bool
+	}{
+		{"2024-12-27", true},  // Fri, before any from: first entry applies
+		{"2025-06-29", false}, // Sun under Mon–Fri
+		{"2025-07-04", false}, // Fri under Sun–Thu
+		{"2025-07-06", true},  // Sun under Sun–Thu
+		{"2026-01-01", false}, // Thu under Mon–Wed
+		{"2026-01-07", true},  // Wed under Mon–Wed
+	}
+	for _, c := range cases {
+		if got := ws.IsWorkday(date(c.date)); got != c.want {
+			t.Errorf("%s (%s): expected %v, got %v", c.date, date(c.date).Weekday(), c.want, got)
+		}
+	}
+	if days := ws.Weekdays(date("2025-08-01")); len(days) != 5 || days[0] != time.Sunday {
+		t.Errorf("unexpected weekdays %v", days)
+	}
+}
+
+func TestParseWorkScheduleErrors(t *testing.T) {
+	cases := map[string][]WorkWeek{
+		"unknown weekday":     {{Days: []string{"mon", "funday"}}},
+		"days can't be empty": {{Days: nil}},
+		"from is required":    {{Days: []string{"mon"}}, {Days: []string{"tue"}}},
+		"must be after":       {{From: "2025-07-01", Days: []string{"mon"}}, {From: "2025-01-01", Days: []string{"tue"}}},
+		"invalid from":        {{From: "July", Days: []string{"mon"}}},
+	}
+	for want, weeks := range cases {
+		if _, err := ParseWorkSchedule(weeks); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestVacationMapRespectsWorkSchedule(t *testing.T) {
+	ws, _ := ParseWorkSchedule([]WorkWeek{{Days: []string{"sun", "mon", "tue", "wed", "thu"}}})
+	SetWorkSchedule(ws)
+	defer SetWorkSchedule(nil)
+
+	vd := NewVacationData()
+	vd.Add(Vacation{Destination: "Trip", StartDate: "2025-01-03", EndDate: "2025-01-06"}) // Fri–Mon
+	m := vd.GetVacationMap()
+	for key, want := range map[string]bool{"2025-01-03": false, "2025-01-04": false, "2025-01-05": true, "2025-01-06": true} {
+		if _, ok := m[key]; ok != want {
+			t.Errorf("%s: expected in map = %v", key, want)
+		}
+	}
+}
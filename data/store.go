@@ -0,0 +1,123 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Store is the persistence backend used by every data container. Documents
+// are addressed by their logical filename (e.g. "badge_data.json"); the
+// extension selects the encoding (JSON or YAML).
+type Store interface {
+	// Read returns the raw bytes of the named document, or nil (no error)
+	// if it doesn't exist.
+	Read(name string) ([]byte, error)
+	// Write stores the raw bytes of the named document.
+	Write(name string, data []byte) error
+	// Exists reports whether the named document has been stored.
+	Exists(name string) bool
+	// String describes the store location for display.
+	String() string
+}
+
+// loadDocument decodes the named document from s into v.
+// If the document doesn't exist, v is left unchanged (no error).
+func loadDocument(s Store, name string, v interface{}) error {
+	data, err := s.Read(name)
+	if err != nil || data == nil {
+		return err
+	}
+	return unmarshalDocument(name, data, v)
+}
+
+// saveDocument encodes v and writes it to s under the given name.
+func saveDocument(s Store, name string, v interface{}) error {
+	data, err := marshalDocument(name, v)
+	if err != nil {
+		return err
+	}
+	return s.Write(name, data)
+}
+
+var globalStore Store
+
+// SetStore sets the global store (called from main before any load/save).
+// Passing nil restores the default directory store.
+func SetStore(s Store) {
+	globalStore = s
+}
+
+// GetStore returns the configured store, defaulting to a DirStore rooted at
+// the global data directory.
+func GetStore() Store {
+	if globalStore != nil {
+		return globalStore
+	}
+	return NewDirStore(GetDataDir())
+}
+
+// OpenStore creates a store from a spec of the form "dir:PATH" or
+// "sqlite:PATH". An empty spec (or bare "dir") selects the global data directory.
+// A leading "~/" in PATH is the home directory, since the shell only expands
+// it at the start of a word.
+func OpenStore(spec string) (Store, error) {
+	kind, path, _ := strings.Cut(spec, ":")
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("expanding %s: %w", path, err)
+		}
+		path = filepath.Join(home, rest)
+	}
+	switch kind {
+	case "", "dir":
+		if path == "" {
+			path = GetDataDir()
+		}
+		return NewDirStore(path), nil
+	case "sqlite":
+		if path == "" {
+			path = filepath.Join(GetDataDir(), defaultSQLiteFilename)
+		}
+		return OpenSQLiteStore(path)
+	default:
+		return nil, fmt.Errorf("unknown store type %q (expected dir or sqlite)", kind)
+	}
+}
+
+// DirStore keeps each document as a YAML or JSON file in a directory.
+// This is the default store and the format documented in the README.
+type DirStore struct {
+	Dir string
+}
+
+// NewDirStore creates a store rooted at dir.
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{Dir: dir}
+}
+
+// Read reads the named file from the directory.
+func (s *DirStore) Read(name string) ([]byte, error) {
+	return loadFile(filepath.Join(s.Dir, name))
+}
+
+// Write atomically replaces the named file in the directory.
+func (s *DirStore) Write(name string, data []byte) error {
+	path := filepath.Join(s.Dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating directories for %s: %w", path, err)
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// Exists reports whether the named file is present in the directory.
+func (s *DirStore) Exists(name string) bool {
+	_, err := os.Stat(filepath.Join(s.Dir, name))
+	return err == nil
+}
+
+func (s *DirStore) String() string {
+	return s.Dir
+}
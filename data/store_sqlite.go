@@ -0,0 +1,263 @@
+package data
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // pure-Go driver, registers "sqlite"
+)
+
+const defaultSQLiteFilename = "rto.db"
+
+// badgeDataKey is the badge_data.json field whose entries get their own rows.
+const badgeDataKey = "badge_data"
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS documents (
+	name       TEXT PRIMARY KEY,
+	body       BLOB NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS badge_entries (
+	entry_date TEXT PRIMARY KEY,
+	body       TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);`
+
+// SQLiteStore keeps each document as a row in an embedded SQLite database.
+// Bodies are encoded exactly as DirStore would write them (JSON for .json
+// names, YAML otherwise), so JSON documents can be queried with json_each().
+// A save that doesn't change a document leaves its row untouched.
+//
+// Badge entries, which grow every day, are the exception: each is a JSON
+// row in badge_entries keyed by its date, and the badge_data.json document
+// row holds everything else (the schema version). Saving badge data only
+// writes the entries that changed.
+type SQLiteStore struct {
+	path string
+	db   *sql.DB
+}
+
+// OpenSQLiteStore opens (creating if needed) the database at path.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating directories for %s: %w", path, err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing %s: %w", path, err)
+	}
+	return &SQLiteStore{path: path, db: db}, nil
+}
+
+// Read returns the named document's body, or nil if it doesn't exist.
+func (s *SQLiteStore) Read(name string) ([]byte, error) {
+	if name == badgeDataFilename {
+		return s.readBadges()
+	}
+	return s.readDocument(name)
+}
+
+func (s *SQLiteStore) readDocument(name string) ([]byte, error) {
+	var body []byte
+	err := s.db.QueryRow(`SELECT body FROM documents WHERE name = ?`, name).Scan(&body)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s from %s: %w", name, s.path, err)
+	}
+	return body, nil
+}
+
+// Write upserts the named document. Unchanged documents are not rewritten.
+func (s *SQLiteStore) Write(name string, body []byte) error {
+	if name == badgeDataFilename {
+		return s.writeBadges(body)
+	}
+	return s.writeDocument(s.db, name, body)
+}
+
+// sqlExecer is the part of *sql.DB and *sql.Tx that writeDocument needs.
+type sqlExecer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func (s *SQLiteStore) writeDocument(db sqlExecer, name string, body []byte) error {
+	var existing []byte
+	err := db.QueryRow(`SELECT body FROM documents WHERE name = ?`, name).Scan(&existing)
+	if err == nil && bytes.Equal(existing, body) {
+		return nil
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("reading %s from %s: %w", name, s.path, err)
+	}
+
+	_, err = db.Exec(
+		`INSERT INTO documents (name, body, updated_at) VALUES (?, ?, ?)
+		 ON CONFLICT(name) DO UPDATE SET body = excluded.body, updated_at = excluded.updated_at`,
+		name, body, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("writing %s to %s: %w", name, s.path, err)
+	}
+	return nil
+}
+
+// Exists reports whether a row for the named document exists.
+func (s *SQLiteStore) Exists(name string) bool {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM documents WHERE name = ?`, name).Scan(&n)
+	return err == nil && n > 0
+}
+
+// readBadges rebuilds badge_data.json from its document row and the
+// badge_entries rows, in date order. A database written before entries had
+// their own rows still holds them in the document, which is returned as is.
+func (s *SQLiteStore) readBadges() ([]byte, error) {
+	body, err := s.readDocument(badgeDataFilename)
+	if err != nil || body == nil {
+		return body, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("reading %s from %s: %w", badgeDataFilename, s.path, err)
+	}
+	if _, legacy := doc[badgeDataKey]; legacy {
+		return body, nil
+	}
+
+	rows, err := s.db.Query(`SELECT body FROM badge_entries ORDER BY entry_date`)
+	if err != nil {
+		return nil, fmt.Errorf("reading badge entries from %s: %w", s.path, err)
+	}
+	defer rows.Close()
+	entries := []json.RawMessage{}
+	for rows.Next() {
+		var entry string
+		if err := rows.Scan(&entry); err != nil {
+			return nil, fmt.Errorf("reading badge entries from %s: %w", s.path, err)
+		}
+		entries = append(entries, json.RawMessage(entry))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading badge entries from %s: %w", s.path, err)
+	}
+	if doc[badgeDataKey], err = json.Marshal(entries); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// writeBadges stores the entries of a badge_data.json body as rows, adding,
+// updating and deleting only the rows that changed, and the rest of the
+// body as the document row. Two entries on the same date are refused, as
+// a row holds one entry per date.
+func (s *SQLiteStore) writeBadges(body []byte) error {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("writing %s to %s: %w", badgeDataFilename, s.path, err)
+	}
+	var raw []json.RawMessage
+	if b, ok := doc[badgeDataKey]; ok {
+		if err := json.Unmarshal(b, &raw); err != nil {
+			return fmt.Errorf("writing %s to %s: %w", badgeDataFilename, s.path, err)
+		}
+	}
+	delete(doc, badgeDataKey)
+
+	entries := make(map[string]string, len(raw))
+	for _, r := range raw {
+		var e struct {
+			EntryDate string `json:"entry_date"`
+		}
+		if err := json.Unmarshal(r, &e); err != nil {
+			return fmt.Errorf("writing %s to %s: %w", badgeDataFilename, s.path, err)
+		}
+		if _, dup := entries[e.EntryDate]; dup {
+			return fmt.Errorf("writing %s to %s: more than one badge entry for %q (run rto doctor --fix)", badgeDataFilename, s.path, e.EntryDate)
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, r); err != nil {
+			return fmt.Errorf("writing %s to %s: %w", badgeDataFilename, s.path, err)
+		}
+		entries[e.EntryDate] = compact.String()
+	}
+	rest, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("writing %s to %s: %w", badgeDataFilename, s.path, err)
+	}
+	defer tx.Rollback()
+
+	existing := map[string]string{}
+	rows, err := tx.Query(`SELECT entry_date, body FROM badge_entries`)
+	if err != nil {
+		return fmt.Errorf("reading badge entries from %s: %w", s.path, err)
+	}
+	for rows.Next() {
+		var date, entry string
+		if err := rows.Scan(&date, &entry); err != nil {
+			rows.Close()
+			return fmt.Errorf("reading badge entries from %s: %w", s.path, err)
+		}
+		existing[date] = entry
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reading badge entries from %s: %w", s.path, err)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for date, entry := range entries {
+		if old, ok := existing[date]; ok && old == entry {
+			continue
+		}
+		_, err := tx.Exec(
+			`INSERT INTO badge_entries (entry_date, body, updated_at) VALUES (?, ?, ?)
+			 ON CONFLICT(entry_date) DO UPDATE SET body = excluded.body, updated_at = excluded.updated_at`,
+			date, entry, now,
+		)
+		if err != nil {
+			return fmt.Errorf("writing badge entry %s to %s: %w", date, s.path, err)
+		}
+	}
+	for date := range existing {
+		if _, ok := entries[date]; ok {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM badge_entries WHERE entry_date = ?`, date); err != nil {
+			return fmt.Errorf("removing badge entry %s from %s: %w", date, s.path, err)
+		}
+	}
+	if err := s.writeDocument(tx, badgeDataFilename, rest); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("writing %s to %s: %w", badgeDataFilename, s.path, err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) String() string {
+	return "sqlite:" + s.path
+}
+
+// Close releases the database handle.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
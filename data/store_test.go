@@ -0,0 +1,279 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenStoreDir(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenStore("dir:" + dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ds, ok := s.(*DirStore)
+	if !ok {
+		t.Fatalf("expected *DirStore, got %T", s)
+	}
+	if ds.Dir != dir {
+		t.Errorf("expected dir %s, got %s", dir, ds.Dir)
+	}
+}
+
+func TestOpenStoreDefaultsToDataDir(t *testing.T) {
+	old := globalDataDir
+	defer func() { globalDataDir = old }()
+	SetDataDir("/tmp/rto-store-test")
+
+	s, err := OpenStore("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.String() != "/tmp/rto-store-test" {
+		t.Errorf("expected data dir store, got %s", s)
+	}
+}
+
+func TestOpenStoreExpandsHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	s, err := OpenStore("dir:~/rto-data")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := filepath.Join(home, "rto-data"); s.String() != want {
+		t.Errorf("expected %s, got %s", want, s)
+	}
+}
+
+func TestOpenStoreUnknown(t *testing.T) {
+	if _, err := OpenStore("postgres:foo"); err == nil {
+		t.Error("expected error for unknown store type")
+	}
+}
+
+func TestGetStoreDefault(t *testing.T) {
+	oldStore, oldDir := globalStore, globalDataDir
+	defer func() { globalStore, globalDataDir = oldStore, oldDir }()
+
+	globalStore = nil
+	SetDataDir("/tmp/abc")
+	if _, ok := GetStore().(*DirStore); !ok {
+		t.Errorf("expected default DirStore, got %T", GetStore())
+	}
+
+	mem := NewDirStore("/tmp/other")
+	SetStore(mem)
+	if GetStore() != mem {
+		t.Error("expected SetStore to override default")
+	}
+}
+
+func TestDirStoreExists(t *testing.T) {
+	dir := t.TempDir()
+	s := NewDirStore(dir)
+	if s.Exists("holidays.yaml") {
+		t.Error("expected missing file to not exist")
+	}
+	NewHolidayData().SaveToStore(s)
+	if !s.Exists("holidays.yaml") {
+		t.Error("expected saved file to exist")
+	}
+}
+
+func openTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	s, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "rto.db"))
+	if err != nil {
+		t.Fatalf("open error: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSQLiteStoreCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "rto.db")
+	s, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("open error: %v", err)
+	}
+	defer s.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("database file not created: %v", err)
+	}
+}
+
+func TestSQLiteStoreLoadMissing(t *testing.T) {
+	s := openTestSQLiteStore(t)
+	bd, err := LoadBadgeEntryDataFromStore(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bd.Len() != 0 {
+		t.Errorf("expected empty, got %d", bd.Len())
+	}
+	if s.Exists(badgeDataFilename) {
+		t.Error("expected document to not exist")
+	}
+}
+
+func TestSQLiteStoreRoundTrip(t *testing.T) {
+	s := openTestSQLiteStore(t)
+
+	bd := NewBadgeEntryData()
+	bd.Add(BadgeEntry{EntryDate: "2025-01-06", Office: "HQ", IsBadgedIn: true})
+	if err := bd.SaveToStore(s); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	hd := NewHolidayData()
+	hd.Add(Holiday{Name: "New Year", Date: "2025-01-01"})
+	if err := hd.SaveToStore(s); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	settings := AppSettings{DefaultOffice: "Austin, TX", Goal: 60}
+	if err := settings.SaveToStore(s); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+
+	loadedBadges, err := LoadBadgeEntryDataFromStore(s)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if e, ok := loadedBadges.Get("2025-01-06"); !ok || e.Office != "HQ" {
+		t.Errorf("badge entry not round-tripped: %+v", e)
+	}
+	loadedHolidays, err := LoadHolidayDataFromStore(s)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if loadedHolidays.GetHolidayMap()["2025-01-01"].Name != "New Year" {
+		t.Error("holiday not round-tripped")
+	}
+	loadedSettings, err := LoadAppSettingsFromStore(s)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if loadedSettings.DefaultOffice != "Austin, TX" || loadedSettings.Goal != 60 {
+		t.Errorf("settings not round-tripped: %+v", loadedSettings)
+	}
+}
+
+func TestSQLiteStoreSkipsUnchangedSave(t *testing.T) {
+	s := openTestSQLiteStore(t)
+	ed := NewEventData()
+	ed.Add(Event{Date: "2025-01-06", Description: "Offsite"})
+	if err := ed.SaveToStore(s); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+
+	var first string
+	s.db.QueryRow(`SELECT updated_at FROM documents WHERE name = ?`, eventsFilename).Scan(&first)
+	s.db.Exec(`UPDATE documents SET updated_at = 'sentinel' WHERE name = ?`, eventsFilename)
+
+	if err := ed.SaveToStore(s); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	var second string
+	s.db.QueryRow(`SELECT updated_at FROM documents WHERE name = ?`, eventsFilename).Scan(&second)
+	if second != "sentinel" {
+		t.Errorf("unchanged save should not rewrite row, updated_at = %s", second)
+	}
+}
+
+func TestSQLiteStoreTimePeriods(t *testing.T) {
+	s := openTestSQLiteStore(t)
+	td := NewTimePeriodDataWithFile("calendar-qtr.yaml")
+	td.SetCalendarDisplayColumns(4)
+	td.Add(TimePeriod{Key: "Q1_2025", Name: "Q1", StartDateRaw: "2025-01-01", EndDateRaw: "2025-03-31"})
+	if err := td.SaveToStore(s); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+
+	loaded, err := LoadTimePeriodDataFromStore(s, "calendar-qtr.yaml")
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if loaded.Len() != 1 || loaded.CalendarDisplayColumns() != 4 {
+		t.Errorf("expected 1 period with 4 columns, got %d/%d", loaded.Len(), loaded.CalendarDisplayColumns())
+	}
+	if _, err := loaded.GetPeriodByKey("Q1_2025"); err != nil {
+		t.Error("expected Q1_2025 to be loaded")
+	}
+}
+
+func TestSQLiteStoreBadgeEntryRows(t *testing.T) {
+	s := openTestSQLiteStore(t)
+	bd := NewBadgeEntryData()
+	bd.Add(BadgeEntry{EntryDate: "2025-01-07", Office: "HQ", IsBadgedIn: true})
+	bd.Add(BadgeEntry{EntryDate: "2025-01-06", Office: "HQ", IsBadgedIn: true})
+	if err := bd.SaveToStore(s); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	var n int
+	s.db.QueryRow(`SELECT COUNT(*) FROM badge_entries`).Scan(&n)
+	if n != 2 {
+		t.Fatalf("expected a row per entry, got %d", n)
+	}
+
+	// Only the changed and removed entries are written.
+	s.db.Exec(`UPDATE badge_entries SET updated_at = 'sentinel'`)
+	bd.Remove("2025-01-07")
+	bd.Add(BadgeEntry{EntryDate: "2025-01-08", Office: "Annex", IsBadgedIn: true})
+	if err := bd.SaveToStore(s); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	var stamp string
+	s.db.QueryRow(`SELECT updated_at FROM badge_entries WHERE entry_date = '2025-01-06'`).Scan(&stamp)
+	if stamp != "sentinel" {
+		t.Errorf("unchanged entry should not be rewritten, updated_at = %s", stamp)
+	}
+	s.db.QueryRow(`SELECT COUNT(*) FROM badge_entries WHERE entry_date = '2025-01-07'`).Scan(&n)
+	if n != 0 {
+		t.Error("removed entry should be deleted")
+	}
+
+	loaded, err := LoadBadgeEntryDataFromStore(s)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	all := loaded.All()
+	if len(all) != 2 || all[0].EntryDate != "2025-01-06" || all[1].Office != "Annex" {
+		t.Errorf("unexpected entries: %+v", all)
+	}
+
+	bd.Add(BadgeEntry{EntryDate: "2025-01-06", IsBadgedIn: true})
+	if err := bd.SaveToStore(s); err == nil {
+		t.Error("expected an error for two entries on one date")
+	}
+}
+
+func TestSQLiteStoreReadsBadgeDocument(t *testing.T) {
+	s := openTestSQLiteStore(t)
+	// A database from before badge entries had their own rows.
+	legacy := `{"schema_version": 1, "badge_data": [{"entry_date": "2025-01-06", "office": "HQ", "is_badged_in": true}]}`
+	if err := s.writeDocument(s.db, badgeDataFilename, []byte(legacy)); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	bd, err := LoadBadgeEntryDataFromStore(s)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if !bd.Has("2025-01-06") {
+		t.Fatal("expected the entry from the document")
+	}
+
+	if err := bd.SaveToStore(s); err != nil {
+		t.Fatalf("save error: %v", err)
+	}
+	var n int
+	s.db.QueryRow(`SELECT COUNT(*) FROM badge_entries`).Scan(&n)
+	if n != 1 {
+		t.Errorf("saving should move the entry to a row, got %d rows", n)
+	}
+	if bd, _ := LoadBadgeEntryDataFromStore(s); bd.Len() != 1 {
+		t.Errorf("expected 1 entry after the move, got %d", bd.Len())
+	}
+}
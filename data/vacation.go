@@ -1,21 +1,58 @@
 package data
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
 const vacationsFilename = "vacations.yaml"
 
-// Vacation represents a single vacation period.
+// Vacation represents a single period of leave: a vacation unless Type
+// says otherwise.
 type Vacation struct {
-	Destination string `yaml:"destination" json:"destination"`
-	StartDate   string `yaml:"start_date" json:"start_date"`
-	EndDate     string `yaml:"end_date" json:"end_date"`
-	Approved    bool   `yaml:"approved" json:"approved"`
+	Destination string    `yaml:"destination" json:"destination"`
+	StartDate   string    `yaml:"start_date" json:"start_date"`
+	EndDate     string    `yaml:"end_date" json:"end_date"`
+	Approved    bool      `yaml:"approved" json:"approved"`
+	Type        LeaveType `yaml:"type,omitempty" json:"type,omitempty"` // empty means LeaveVacation
+	UID         string    `yaml:"uid,omitempty" json:"uid,omitempty"`   // iCalendar UID, set when imported
+}
+
+// Leave returns the leave type of v.
+func (v Vacation) Leave() LeaveType {
+	if v.Type == "" {
+		return LeaveVacation
+	}
+	return v.Type
+}
+
+// Validate checks that v has a destination, a known leave type and a valid,
+// non-inverted date range.
+func (v Vacation) Validate() error {
+	if strings.TrimSpace(v.Destination) == "" {
+		return fmt.Errorf("destination is required")
+	}
+	if !v.Leave().Valid() {
+		return fmt.Errorf("unknown leave type %q (expected %s)", v.Type, leaveTypeList())
+	}
+	start, err := time.Parse(BadgeDateFormat, v.StartDate)
+	if err != nil {
+		return fmt.Errorf("invalid start date %q (expected YYYY-MM-DD)", v.StartDate)
+	}
+	end, err := time.Parse(BadgeDateFormat, v.EndDate)
+	if err != nil {
+		return fmt.Errorf("invalid end date %q (expected YYYY-MM-DD)", v.EndDate)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("end date %s is before start date %s", v.EndDate, v.StartDate)
+	}
+	return nil
 }
 
 type vacationDataFile struct {
-	Vacations []Vacation `yaml:"vacations"`
+	SchemaVersion int        `yaml:"schema_version"`
+	Vacations     []Vacation `yaml:"vacations"`
 }
 
 // VacationData is the in-memory container for vacations.
@@ -28,15 +65,20 @@ func NewVacationData() *VacationData {
 	return &VacationData{}
 }
 
-// LoadVacationData reads vacation data from the global data directory.
+// LoadVacationData reads vacation data from the global store.
 func LoadVacationData() (*VacationData, error) {
-	return LoadVacationDataFrom(GetDataDir())
+	return LoadVacationDataFromStore(GetStore())
 }
 
 // LoadVacationDataFrom reads vacation data from the specified directory.
 func LoadVacationDataFrom(dir string) (*VacationData, error) {
+	return LoadVacationDataFromStore(NewDirStore(dir))
+}
+
+// LoadVacationDataFromStore reads vacation data from the given store.
+func LoadVacationDataFromStore(s Store) (*VacationData, error) {
 	var file vacationDataFile
-	if err := LoadYAMLFrom(dir, vacationsFilename, &file); err != nil {
+	if err := loadVersioned(s, vacationsFilename, DocVacations, &file); err != nil {
 		return nil, err
 	}
 	if file.Vacations == nil {
@@ -45,15 +87,23 @@ func LoadVacationDataFrom(dir string) (*VacationData, error) {
 	return &VacationData{vacations: file.Vacations}, nil
 }
 
-// Save writes vacation data to the global data directory.
+// Save writes vacation data to the global store.
 func (v *VacationData) Save() error {
-	return v.SaveTo(GetDataDir())
+	return v.SaveToStore(GetStore())
 }
 
 // SaveTo writes vacation data to the specified directory.
 func (v *VacationData) SaveTo(dir string) error {
-	file := vacationDataFile{Vacations: v.vacations}
-	return SaveYAMLTo(dir, vacationsFilename, &file)
+	return v.SaveToStore(NewDirStore(dir))
+}
+
+// SaveToStore writes vacation data to the given store.
+func (v *VacationData) SaveToStore(s Store) error {
+	return saveDocument(s, vacationsFilename, v.fileDocument())
+}
+
+func (v *VacationData) fileDocument() interface{} {
+	return &vacationDataFile{SchemaVersion: CurrentSchemaVersion, Vacations: v.vacations}
 }
 
 // Add appends a vacation.
@@ -73,6 +123,16 @@ func (v *VacationData) Remove(startDate, endDate string) {
 	v.vacations = filtered
 }
 
+// Update replaces the vacation at index i (as ordered by All).
+func (v *VacationData) Update(i int, vacation Vacation) {
+	v.vacations[i] = vacation
+}
+
+// RemoveAt deletes the vacation at index i (as ordered by All).
+func (v *VacationData) RemoveAt(i int) {
+	v.vacations = append(v.vacations[:i], v.vacations[i+1:]...)
+}
+
 // All returns a copy of all vacations.
 func (v *VacationData) All() []Vacation {
 	result := make([]Vacation, len(v.vacations))
@@ -80,13 +140,29 @@ func (v *VacationData) All() []Vacation {
 	return result
 }
 
+// Approved returns a VacationData holding only the approved vacations.
+func (v *VacationData) Approved() *VacationData {
+	approved := []Vacation{}
+	for _, vac := range v.vacations {
+		if vac.Approved {
+			approved = append(approved, vac)
+		}
+	}
+	return &VacationData{vacations: approved}
+}
+
+// Clone returns a copy of the VacationData.
+func (v *VacationData) Clone() *VacationData {
+	return &VacationData{vacations: v.All()}
+}
+
 // Len returns the number of vacations.
 func (v *VacationData) Len() int {
 	return len(v.vacations)
 }
 
 // GetVacationMap expands all vacation date ranges into individual date keys.
-// Only weekdays (Mon–Fri) are included; holidays are NOT excluded here.
+// Only working days (see IsWorkday) are included; holidays are NOT excluded here.
 func (v *VacationData) GetVacationMap() map[string]Vacation {
 	m := make(map[string]Vacation)
 	for _, vac := range v.vacations {
@@ -99,7 +175,7 @@ func (v *VacationData) GetVacationMap() map[string]Vacation {
 			continue
 		}
 		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
-			if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			if !IsWorkday(d) {
 				continue
 			}
 			m[d.Format(BadgeDateFormat)] = vac
@@ -133,3 +133,49 @@ func TestVacationLoadMissingFile(t *testing.T) {
 		t.Error("expected empty vacations for missing file")
 	}
 }
+
+func TestVacationValidate(t *testing.T) {
+	cases := []struct {
+		v  Vacation
+		ok bool
+	}{
+		{Vacation{Destination: "Beach", StartDate: "2025-06-10", EndDate: "2025-06-10"}, true},
+		{Vacation{Destination: " ", StartDate: "2025-06-10", EndDate: "2025-06-10"}, false},
+		{Vacation{Destination: "Beach", StartDate: "06/10/2025", EndDate: "2025-06-10"}, false},
+		{Vacation{Destination: "Beach", StartDate: "2025-06-10", EndDate: "2025-06-09"}, false},
+		{Vacation{Destination: "Flu", StartDate: "2025-06-10", EndDate: "2025-06-10", Type: LeaveSick}, true},
+		{Vacation{Destination: "Flu", StartDate: "2025-06-10", EndDate: "2025-06-10", Type: "holiday"}, false},
+	}
+	for _, c := range cases {
+		if err := c.v.Validate(); (err == nil) != c.ok {
+			t.Errorf("%+v: Validate() = %v, want ok=%v", c.v, err, c.ok)
+		}
+	}
+}
+
+func TestVacationUpdateRemoveAt(t *testing.T) {
+	v := NewVacationData()
+	v.Add(Vacation{Destination: "A", StartDate: "2025-01-01", EndDate: "2025-01-02"})
+	v.Add(Vacation{Destination: "B", StartDate: "2025-02-01", EndDate: "2025-02-02"})
+	v.Update(0, Vacation{Destination: "C", StartDate: "2025-03-01", EndDate: "2025-03-02"})
+	v.RemoveAt(1)
+	all := v.All()
+	if len(all) != 1 || all[0].Destination != "C" {
+		t.Errorf("unexpected vacations: %+v", all)
+	}
+}
+
+func TestVacationApprovedClone(t *testing.T) {
+	v := NewVacationData()
+	v.Add(Vacation{Destination: "A", StartDate: "2025-01-01", EndDate: "2025-01-02", Approved: true})
+	v.Add(Vacation{Destination: "B", StartDate: "2025-02-03", EndDate: "2025-02-04"})
+	approved := v.Approved().All()
+	if len(approved) != 1 || approved[0].Destination != "A" {
+		t.Errorf("unexpected approved vacations: %+v", approved)
+	}
+	c := v.Clone()
+	c.RemoveAt(0)
+	if v.Len() != 2 || c.Len() != 1 {
+		t.Errorf("changing a clone should leave the original alone: %d, %d", v.Len(), c.Len())
+	}
+}
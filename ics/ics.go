@@ -0,0 +1,396 @@
+// Package ics reads, expands and writes iCalendar (RFC 5545) VEVENTs, as
+// exchanged with Outlook, Google Calendar and most other calendar tools.
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// Event is a single VEVENT. End is exclusive, as in iCalendar: an all-day
+// event on Jan 1 has Start Jan 1 and End Jan 2.
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Start        time.Time
+	End          time.Time
+	AllDay       bool
+	RRule        string      // raw RRULE value, empty if the event doesn't recur
+	ExDates      []time.Time // excluded recurrence instances
+	RecurrenceID *time.Time  // set on an override of one instance of a recurring event
+	Status       string      // e.g. CONFIRMED, TENTATIVE, CANCELLED
+	Categories   []string
+	Alarms       []Alarm // written by Write; VALARMs are skipped by Parse
+}
+
+// Alarm is a display reminder (VALARM) that fires Before the event starts.
+type Alarm struct {
+	Before      time.Duration
+	Description string
+}
+
+// Occurrence is one concrete instance of an event after RRULE expansion.
+type Occurrence struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time // exclusive
+	AllDay  bool
+}
+
+// Days returns the calendar dates the occurrence covers, as UTC midnights.
+func (o Occurrence) Days() []time.Time {
+	first := dateOf(o.Start)
+	last := first
+	if o.End.After(o.Start) {
+		last = dateOf(o.End.Add(-time.Nanosecond))
+	}
+	var days []time.Time
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days
+}
+
+// Parse reads every VEVENT in r. Nested components such as VALARM are
+// ignored.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var cur *Event
+	var hasEnd bool
+	var duration time.Duration
+	depth := 0 // nesting inside the current VEVENT (e.g. VALARM)
+
+	for n, line := range lines {
+		name, params, value, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT") && cur == nil:
+			cur = &Event{}
+			hasEnd, duration, depth = false, 0, 0
+			continue
+		case cur == nil:
+			continue
+		case name == "BEGIN":
+			depth++
+			continue
+		case name == "END" && depth > 0:
+			depth--
+			continue
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if cur.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: VEVENT %q has no DTSTART", n+1, cur.Summary)
+			}
+			if !hasEnd {
+				switch {
+				case duration > 0:
+					cur.End = cur.Start.Add(duration)
+				case cur.AllDay:
+					cur.End = cur.Start.AddDate(0, 0, 1)
+				default:
+					cur.End = cur.Start
+				}
+			}
+			events = append(events, *cur)
+			cur = nil
+			continue
+		case depth > 0:
+			continue
+		}
+
+		var perr error
+		switch name {
+		case "UID":
+			cur.UID = value
+		case "SUMMARY":
+			cur.Summary = unescapeText(value)
+		case "DESCRIPTION":
+			cur.Description = unescapeText(value)
+		case "STATUS":
+			cur.Status = strings.ToUpper(value)
+		case "RRULE":
+			cur.RRule = value
+		case "CATEGORIES":
+			for _, c := range splitUnescaped(value) {
+				cur.Categories = append(cur.Categories, unescapeText(c))
+			}
+		case "DTSTART":
+			cur.Start, cur.AllDay, perr = parseDateTime(value, params)
+		case "DTEND":
+			cur.End, _, perr = parseDateTime(value, params)
+			hasEnd = true
+		case "DURATION":
+			duration, perr = parseDuration(value)
+		case "RECURRENCE-ID":
+			var t time.Time
+			t, _, perr = parseDateTime(value, params)
+			cur.RecurrenceID = &t
+		case "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				t, _, err := parseDateTime(v, params)
+				if err != nil {
+					perr = err
+					break
+				}
+				cur.ExDates = append(cur.ExDates, t)
+			}
+		}
+		if perr != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", n+1, name, perr)
+		}
+	}
+	if cur != nil {
+		return nil, fmt.Errorf("unterminated VEVENT %q", cur.Summary)
+	}
+	return events, nil
+}
+
+// Expand turns events into the occurrences that start within [from, to]
+// (inclusive dates), expanding RRULEs, dropping EXDATEs and cancelled events,
+// and applying RECURRENCE-ID overrides. Occurrences are sorted by start.
+func Expand(events []Event, from, to time.Time) ([]Occurrence, error) {
+	windowStart := dateOf(from)
+	windowEnd := dateOf(to).AddDate(0, 0, 1)
+
+	overridden := map[string][]time.Time{}
+	for _, ev := range events {
+		if ev.RecurrenceID != nil {
+			overridden[ev.UID] = append(overridden[ev.UID], *ev.RecurrenceID)
+		}
+	}
+
+	var result []Occurrence
+	for _, ev := range events {
+		if ev.Status == "CANCELLED" {
+			continue
+		}
+		length := ev.End.Sub(ev.Start)
+		inWindow := func(start time.Time) bool {
+			d := dateOf(start)
+			return !d.Before(windowStart) && d.Before(windowEnd)
+		}
+		occ := func(start time.Time) Occurrence {
+			return Occurrence{UID: ev.UID, Summary: ev.Summary, Start: start, End: start.Add(length), AllDay: ev.AllDay}
+		}
+
+		if ev.RRule == "" || ev.RecurrenceID != nil {
+			if inWindow(ev.Start) {
+				result = append(result, occ(ev.Start))
+			}
+			continue
+		}
+
+		opt, err := rrule.StrToROptionInLocation(ev.RRule, ev.Start.Location())
+		if err != nil {
+			return nil, fmt.Errorf("event %q: RRULE %q: %w", ev.Summary, ev.RRule, err)
+		}
+		opt.Dtstart = ev.Start
+		rule, err := rrule.NewRRule(*opt)
+		if err != nil {
+			return nil, fmt.Errorf("event %q: RRULE %q: %w", ev.Summary, ev.RRule, err)
+		}
+		skip := append(append([]time.Time{}, ev.ExDates...), overridden[ev.UID]...)
+		// Widen the search by a day on each side so zone offsets can't drop an
+		// instance; inWindow then filters on the event's own calendar date.
+		for _, start := range rule.Between(windowStart.AddDate(0, 0, -1), windowEnd.AddDate(0, 0, 1), true) {
+			if !inWindow(start) || matchesAny(start, skip, ev.AllDay) {
+				continue
+			}
+			result = append(result, occ(start))
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Start.Before(result[j].Start) })
+	return result, nil
+}
+
+func matchesAny(t time.Time, list []time.Time, allDay bool) bool {
+	for _, x := range list {
+		if t.Equal(x) || (allDay && dateOf(t).Equal(dateOf(x))) {
+			return true
+		}
+	}
+	return false
+}
+
+// dateOf returns t's calendar date (in t's own location) as a UTC midnight.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// unfold reads content lines, joining folded continuation lines.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseLine splits "NAME;PARAM=VALUE:value" into its parts. Parameter values
+// may be quoted and contain ':' or ';'.
+func parseLine(line string) (name string, params map[string]string, value string, ok bool) {
+	inQuote := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			inQuote = !inQuote
+		} else if c == ':' && !inQuote {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+	head, value := line[:colon], line[colon+1:]
+	parts := splitUnquoted(head, ';')
+	name = strings.ToUpper(parts[0])
+	params = map[string]string{}
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return name, params, value, true
+}
+
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	inQuote := false
+	start := 0
+	for i, c := range s {
+		if c == '"' {
+			inQuote = !inQuote
+		} else if c == sep && !inQuote {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// splitUnescaped splits a TEXT list on commas that aren't backslash-escaped.
+func splitUnescaped(s string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// parseDateTime parses a DATE or DATE-TIME value. Times with a TZID are
+// placed in that zone; zones Go doesn't know (e.g. Windows names exported by
+// Outlook) and floating times are read as UTC, which keeps the wall-clock date.
+func parseDateTime(value string, params map[string]string) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.Parse("20060102", value)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// parseDuration parses an RFC 5545 DURATION such as P1D, PT1H30M or P2W.
+func parseDuration(s string) (time.Duration, error) {
+	orig := s
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "+"), "P")
+	if strings.HasPrefix(orig, "-") {
+		return 0, fmt.Errorf("negative duration %q", orig)
+	}
+	var d time.Duration
+	inTime := false
+	num := ""
+	for _, c := range s {
+		switch {
+		case c == 'T':
+			inTime = true
+		case c >= '0' && c <= '9':
+			num += string(c)
+		default:
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+			num = ""
+			switch {
+			case c == 'W':
+				d += time.Duration(n) * 7 * 24 * time.Hour
+			case c == 'D':
+				d += time.Duration(n) * 24 * time.Hour
+			case c == 'H' && inTime:
+				d += time.Duration(n) * time.Hour
+			case c == 'M' && inTime:
+				d += time.Duration(n) * time.Minute
+			case c == 'S' && inTime:
+				d += time.Duration(n) * time.Second
+			default:
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	return d, nil
+}
@@ -0,0 +1,144 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VTIMEZONE\r\nTZID:America/New_York\r\nEND:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:newyear@example.com\r\n" +
+	"SUMMARY:New Year's Day\r\n" +
+	"DTSTART;VALUE=DATE:20250101\r\n" +
+	"DTEND;VALUE=DATE:20250102\r\n" +
+	"RRULE:FREQ=YEARLY\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:thanksgiving@example.com\r\n" +
+	"SUMMARY:Thanksgiving\\, observed\r\n" +
+	"DTSTART;VALUE=DATE:20251127\r\n" +
+	"DTEND;VALUE=DATE:20251129\r\n" +
+	"RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH\r\n" +
+	"BEGIN:VALARM\r\nTRIGGER:-PT15M\r\nDESCRIPTION:Reminder\r\nEND:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"SUMMARY:Stand\r\n" +
+	" up\r\n" +
+	"DTSTART;TZID=America/New_York:20250106T090000\r\n" +
+	"DURATION:PT15M\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=4\r\n" +
+	"EXDATE;TZID=America/New_York:20250113T090000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"RECURRENCE-ID;TZID=America/New_York:20250120T090000\r\n" +
+	"SUMMARY:Stand up (moved)\r\n" +
+	"DTSTART;TZID=America/New_York:20250121T090000\r\n" +
+	"DTEND;TZID=America/New_York:20250121T091500\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled@example.com\r\n" +
+	"SUMMARY:Cancelled\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART;VALUE=DATE:20250301\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(sampleCalendar))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 5 {
+		t.Fatalf("expected 5 events, got %d", len(events))
+	}
+	if events[1].Summary != "Thanksgiving, observed" {
+		t.Errorf("expected unescaped summary, got %q", events[1].Summary)
+	}
+	if events[2].Summary != "Standup" {
+		t.Errorf("expected unfolded summary, got %q", events[2].Summary)
+	}
+	if !events[0].AllDay || events[2].AllDay {
+		t.Error("expected all-day detection from VALUE=DATE")
+	}
+	if got := events[2].End.Sub(events[2].Start); got != 15*time.Minute {
+		t.Errorf("expected DURATION to set End, got %v", got)
+	}
+	if events[2].Start.Location().String() != "America/New_York" {
+		t.Errorf("expected TZID location, got %v", events[2].Start.Location())
+	}
+}
+
+func TestParseMissingDTSTART(t *testing.T) {
+	_, err := Parse(strings.NewReader("BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\n"))
+	if err == nil || !strings.Contains(err.Error(), "no DTSTART") {
+		t.Errorf("expected DTSTART error, got %v", err)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	events, _ := Parse(strings.NewReader(sampleCalendar))
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	occs, err := Expand(events, from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, o := range occs {
+		got = append(got, o.Start.Format("2006-01-02")+" "+o.Summary)
+	}
+	want := []string{
+		"2025-01-01 New Year's Day",
+		"2025-01-06 Standup",
+		// 01-13 is an EXDATE; 01-20 is replaced by its override on 01-21.
+		"2025-01-21 Stand up (moved)",
+		"2025-01-27 Standup",
+		"2025-11-27 Thanksgiving, observed",
+		"2026-01-01 New Year's Day",
+		"2026-11-26 Thanksgiving, observed",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("occurrences:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestOccurrenceDays(t *testing.T) {
+	o := Occurrence{
+		Start:  time.Date(2025, 11, 27, 0, 0, 0, 0, time.UTC),
+		End:    time.Date(2025, 11, 29, 0, 0, 0, 0, time.UTC),
+		AllDay: true,
+	}
+	days := o.Days()
+	if len(days) != 2 || days[1].Day() != 28 {
+		t.Errorf("expected Nov 27–28 (DTEND exclusive), got %v", days)
+	}
+
+	ny, _ := time.LoadLocation("America/New_York")
+	timed := Occurrence{Start: time.Date(2025, 1, 6, 23, 0, 0, 0, ny), End: time.Date(2025, 1, 6, 23, 30, 0, 0, ny)}
+	if d := timed.Days(); len(d) != 1 || d[0].Day() != 6 {
+		t.Errorf("timed event should stay on its local date, got %v", d)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"P1D":     24 * time.Hour,
+		"PT1H30M": 90 * time.Minute,
+		"P2W":     14 * 24 * time.Hour,
+		"P1DT12H": 36 * time.Hour,
+	}
+	for in, want := range cases {
+		if got, err := parseDuration(in); err != nil || got != want {
+			t.Errorf("parseDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseDuration("P1X"); err == nil {
+		t.Error("expected error for invalid duration")
+	}
+}
@@ -0,0 +1,136 @@
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Calendar is a VCALENDAR to be written by Write.
+type Calendar struct {
+	ProdID string    // PRODID, e.g. "-//rto//rto//EN"
+	Name   string    // X-WR-CALNAME, shown by most calendar apps
+	Stamp  time.Time // DTSTAMP written on every event
+	Events []Event
+}
+
+// maxLineOctets is the longest content line allowed before folding.
+const maxLineOctets = 75
+
+// Write writes cal as an iCalendar stream with CRLF line endings and folded
+// long lines. All-day events are written as DATE values; other times are
+// written in UTC. RRULE, EXDATE and RECURRENCE-ID are not written.
+func Write(w io.Writer, cal Calendar) error {
+	bw := bufio.NewWriter(w)
+	line := func(format string, args ...any) {
+		writeFolded(bw, fmt.Sprintf(format, args...))
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:%s", cal.ProdID)
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME:%s", escapeText(cal.Name))
+	}
+	for _, ev := range cal.Events {
+		line("BEGIN:VEVENT")
+		line("UID:%s", ev.UID)
+		line("DTSTAMP:%s", formatUTC(cal.Stamp))
+		if ev.AllDay {
+			line("DTSTART;VALUE=DATE:%s", ev.Start.Format("20060102"))
+			line("DTEND;VALUE=DATE:%s", ev.End.Format("20060102"))
+		} else {
+			line("DTSTART:%s", formatUTC(ev.Start))
+			line("DTEND:%s", formatUTC(ev.End))
+		}
+		line("SUMMARY:%s", escapeText(ev.Summary))
+		if ev.Description != "" {
+			line("DESCRIPTION:%s", escapeText(ev.Description))
+		}
+		if len(ev.Categories) > 0 {
+			cats := make([]string, len(ev.Categories))
+			for i, c := range ev.Categories {
+				cats[i] = escapeText(c)
+			}
+			line("CATEGORIES:%s", strings.Join(cats, ","))
+		}
+		if ev.Status != "" {
+			line("STATUS:%s", ev.Status)
+		}
+		if ev.AllDay {
+			line("TRANSP:TRANSPARENT")
+		}
+		for _, a := range ev.Alarms {
+			line("BEGIN:VALARM")
+			line("ACTION:DISPLAY")
+			line("DESCRIPTION:%s", escapeText(a.Description))
+			line("TRIGGER:-%s", formatDuration(a.Before))
+			line("END:VALARM")
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// writeFolded writes s as one content line, folding it every 75 octets
+// without splitting a UTF-8 sequence.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = maxLineOctets - 1 // continuation lines start with a space
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// formatDuration formats a non-negative duration as an RFC 5545 DURATION,
+// e.g. PT15H or P1DT30M.
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	h, m, s := d/time.Hour, (d%time.Hour)/time.Minute, (d%time.Minute)/time.Second
+
+	var b strings.Builder
+	b.WriteString("P")
+	if days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+	}
+	if h > 0 || m > 0 || s > 0 || days == 0 {
+		b.WriteString("T")
+		if h > 0 {
+			fmt.Fprintf(&b, "%dH", h)
+		}
+		if m > 0 {
+			fmt.Fprintf(&b, "%dM", m)
+		}
+		if s > 0 || (h == 0 && m == 0) {
+			fmt.Fprintf(&b, "%dS", s)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package ics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	day := time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+	cal := Calendar{
+		ProdID: "-//rto//test//EN",
+		Name:   "rto",
+		Stamp:  time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Events: []Event{
+			{
+				UID: "event-1@rto", Summary: "Offsite; Reston, VA", Start: day, End: day.AddDate(0, 0, 1), AllDay: true,
+				Categories: []string{"Event"},
+				Alarms:     []Alarm{{Before: 15 * time.Hour, Description: "Office day"}},
+			},
+			{
+				UID: "timed@rto", Summary: "Review", Start: day.Add(14 * time.Hour), End: day.Add(15 * time.Hour),
+				Status: "TENTATIVE",
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, cal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"DTSTAMP:20250301T120000Z\r\n",
+		"DTSTART;VALUE=DATE:20250312\r\n",
+		`SUMMARY:Offsite\; Reston\, VA` + "\r\n",
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Office day\r\nTRIGGER:-PT15H\r\nEND:VALARM\r\n",
+		"DTSTART:20250312T140000Z\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	events, err := Parse(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("re-parsing: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	e := events[0]
+	if e.Summary != "Offsite; Reston, VA" || !e.AllDay || !e.Start.Equal(day) || e.Categories[0] != "Event" {
+		t.Errorf("unexpected round-tripped event: %+v", e)
+	}
+	if events[1].Status != "TENTATIVE" || !events[1].End.Equal(day.Add(15*time.Hour)) {
+		t.Errorf("unexpected round-tripped timed event: %+v", events[1])
+	}
+}
+
+func TestWriteFoldsLongLines(t *testing.T) {
+	long := strings.Repeat("é", 100) // 2 octets each
+	var buf bytes.Buffer
+	Write(&buf, Calendar{ProdID: "x", Events: []Event{{UID: "u", Summary: long, Start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), AllDay: true}}})
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line longer than %d octets: %q", maxLineOctets, line)
+		}
+	}
+	events, err := Parse(&buf)
+	if err != nil || events[0].Summary != long {
+		t.Errorf("folded summary did not round-trip: %v", err)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	cases := map[time.Duration]string{
+		15 * time.Hour:                "PT15H",
+		24*time.Hour + 30*time.Minute: "P1DT30M",
+		48 * time.Hour:                "P2D",
+		0:                             "PT0S",
+	}
+	for in, want := range cases {
+		if got := formatDuration(in); got != want {
+			t.Errorf("formatDuration(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
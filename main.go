@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"rto/cmd"
@@ -11,6 +13,9 @@ import (
 )
 
 var dataDir string
+var storeSpec string
+var lockWait time.Duration
+var outputFlag string
 
 var rootCmd = &cobra.Command{
 	Use:   "rto",
@@ -20,16 +25,52 @@ var rootCmd = &cobra.Command{
 		if dataDir != "" {
 			data.SetDataDir(dataDir)
 		}
+		cmd.SetLockWait(lockWait)
+		format, err := cmd.ParseOutputFormat(outputFlag)
+		if err != nil {
+			return err
+		}
+		cmd.SetOutputFormat(format)
+		if storeSpec != "" {
+			st, err := data.OpenStore(storeSpec)
+			if err != nil {
+				return err
+			}
+			data.SetStore(st)
+		}
 		// Auto-init if data directory is empty or missing
 		if c.Use == "init" {
 			return nil // let init handle it
 		}
-		if dirNeedsInit(data.GetDataDir()) {
+		if storeNeedsInit(data.GetStore()) {
 			fmt.Fprintf(os.Stderr, "Data directory not initialized. Running 'rto init'...\n")
-			if err := cmd.RunInitInDir(data.GetDataDir()); err != nil {
+			if err := cmd.RunInit(); err != nil {
 				return fmt.Errorf("auto-init failed: %w", err)
 			}
 		}
+		settings, err := data.LoadAppSettings()
+		if err != nil {
+			return fmt.Errorf("loading settings: %w", err)
+		}
+		schedule, err := settings.Schedule()
+		if err != nil {
+			return fmt.Errorf("settings.yaml: %w", err)
+		}
+		if err := settings.WeeklyMinimum.Validate(); err != nil {
+			return fmt.Errorf("settings.yaml: %w", err)
+		}
+		if err := settings.Planner.Validate(); err != nil {
+			return fmt.Errorf("settings.yaml: %w", err)
+		}
+		if _, err := data.LoadPolicy(settings); err != nil {
+			return fmt.Errorf("loading policy: %w", err)
+		}
+		if settings.RollingWindow.Enabled() {
+			if _, err := settings.RollingWindow.Days(); err != nil {
+				return fmt.Errorf("settings.yaml: rolling_window: %w", err)
+			}
+		}
+		data.SetWorkSchedule(schedule)
 		return nil
 	},
 	RunE: func(c *cobra.Command, args []string) error {
@@ -48,24 +89,90 @@ var initCmd = &cobra.Command{
 var statsCmd = &cobra.Command{
 	Use:   "stats [PERIOD_KEY]",
 	Short: "Print statistics for a time period",
-	Long:  `Print statistics for a time period (e.g., Q1_2025). Uses the current period if not specified.`,
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Print statistics for a time period (e.g., Q1_2025). Uses the current period if not specified;
+if today is past the last period, --yes first extends the time period file (see rto periods rollover).
+With --rolling, print the trailing-window average ending today instead; the period selects the
+days listed in json, yaml and csv --days output.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(c *cobra.Command, args []string) error {
-		key := ""
-		if len(args) > 0 {
-			key = args[0]
-		} else {
-			td, err := data.LoadTimePeriodData()
+		key, err := periodKeyArg(c, args)
+		if err != nil {
+			return err
+		}
+		days, _ := c.Flags().GetBool("days")
+		if rolling, _ := c.Flags().GetString("rolling"); rolling != "" {
+			return cmd.RunRollingStats(key, rolling, days)
+		}
+		return cmd.RunStats(key, days)
+	},
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan [PERIOD_KEY]",
+	Short: "Propose office days that reach the goal",
+	Long: `Propose the future office days that get a time period (default: the current one) to its goal
+and weekly minimum. Preferred weekdays are planned first and days with events last; holidays,
+leave, blackout dates and days already badged in or planned are never proposed. Defaults come
+from the planner block of settings.yaml; the flags override them. With --apply, the proposed
+days are recorded as planned office days.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		key, err := periodKeyArg(c, args)
+		if err != nil {
+			return err
+		}
+		opts := cmd.PlanOptions{}
+		opts.Weekdays, _ = c.Flags().GetStringSlice("weekdays")
+		opts.Blackouts, _ = c.Flags().GetStringSlice("blackout")
+		opts.Apply, _ = c.Flags().GetBool("apply")
+		if c.Flags().Changed("buffer") {
+			buffer, _ := c.Flags().GetInt("buffer")
+			opts.Buffer = &buffer
+		}
+		return cmd.RunPlan(key, opts)
+	},
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Compare attendance across past periods",
+	Long: `Print one line per period of a time period file (default: the default view) that has started:
+days required, badged in and flex, attendance rate, margin over the requirement and status, with
+a sparkline of the rates. The current period is marked in progress.`,
+	Args: cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		file, _ := c.Flags().GetString("file")
+		return cmd.RunHistory(file)
+	},
+}
+
+var analyticsCmd = &cobra.Command{
+	Use:   "analytics",
+	Short: "Show attendance patterns by weekday, streaks and office",
+	Long: `Print attendance patterns for a time period (default: the current one) or, with --from and --to,
+a date range: the share of each weekday spent in the office, the longest and current streaks of
+office days, the average badge-in time and the office badge-ins per office. Holidays, leave and
+days off neither count nor break a streak; days after today are left out.`,
+	Args: cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		opts := cmd.AnalyticsOptions{}
+		opts.Period, _ = c.Flags().GetString("period")
+		opts.From, _ = c.Flags().GetString("from")
+		opts.To, _ = c.Flags().GetString("to")
+		if opts.From != "" && opts.Period != "" {
+			return fmt.Errorf("give either --period or --from/--to, not both")
+		}
+		if opts.From == "" && opts.To != "" {
+			return fmt.Errorf("--to needs --from")
+		}
+		if opts.From == "" && opts.Period == "" {
+			key, err := periodKeyArg(c, nil)
 			if err != nil {
 				return err
 			}
-			tp, err := td.GetCurrentPeriod()
-			if err != nil {
-				return fmt.Errorf("cannot determine current period: %w (try specifying a period key)", err)
-			}
-			key = tp.Key
+			opts.Period = key
 		}
-		return cmd.RunStats(key)
+		return cmd.RunAnalytics(opts)
 	},
 }
 
@@ -85,6 +192,417 @@ var holidaysCmd = &cobra.Command{
 	},
 }
 
+var badgeCmd = &cobra.Command{
+	Use:   "badge [DATE|today]",
+	Short: "Record a badge-in or flex credit",
+	Long: `Record an office badge-in (or, with --flex, a flex credit) for DATE (YYYY-MM-DD, default today),
+or for every matching day from --from to --to. Follows the same rules as the calendar's b and f keys:
+a day holds either an office badge-in or a flex credit, never both. Weekends, holidays and vacation
+days are skipped when adding. --credit records part of a day, e.g. --credit 0.5 for a half day.
+--planned records a planned office day, which counts toward projections but not attendance until
+the day is recorded. Prints the updated stats for the period afterwards.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		opts := cmd.BadgeOptions{}
+		if len(args) > 0 {
+			opts.Date = args[0]
+		}
+		opts.From, _ = c.Flags().GetString("from")
+		opts.To, _ = c.Flags().GetString("to")
+		opts.Flex, _ = c.Flags().GetBool("flex")
+		opts.Planned, _ = c.Flags().GetBool("planned")
+		opts.Office, _ = c.Flags().GetString("office")
+		opts.Remove, _ = c.Flags().GetBool("remove")
+		opts.Credit, _ = c.Flags().GetFloat64("credit")
+		if c.Flags().Changed("credit") && opts.Credit == 0 {
+			return fmt.Errorf("--credit must be more than 0")
+		}
+		if days, _ := c.Flags().GetString("weekdays"); days != "" {
+			weekdays, err := cmd.ParseWeekdays(days)
+			if err != nil {
+				return err
+			}
+			opts.Weekdays = weekdays
+		}
+		return cmd.RunBadge(opts)
+	},
+}
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "List all events",
+	RunE: func(c *cobra.Command, args []string) error {
+		return cmd.RunEvents()
+	},
+}
+
+// SELECTOR arguments accept either the # shown by the list commands or a date.
+const selectorHelp = `SELECTOR is the # shown by the list command, or a date (YYYY-MM-DD) if only one entry matches it.`
+
+var eventCmd = &cobra.Command{
+	Use:   "event",
+	Short: "Add, edit or remove events",
+}
+
+var eventAddCmd = &cobra.Command{
+	Use:   "add DATE DESCRIPTION...",
+	Short: "Add an event",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(c *cobra.Command, args []string) error {
+		return cmd.RunEventAdd(data.Event{Date: args[0], Description: strings.Join(args[1:], " ")})
+	},
+}
+
+var eventEditCmd = &cobra.Command{
+	Use:   "edit SELECTOR",
+	Short: "Edit an event",
+	Long:  "Edit an event. Only the fields given as flags are changed. " + selectorHelp,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		return cmd.RunEventEdit(args[0], cmd.EventEdit{
+			Date:        changedString(c, "date"),
+			Description: changedString(c, "description"),
+		})
+	},
+}
+
+var eventRmCmd = &cobra.Command{
+	Use:   "rm SELECTOR",
+	Short: "Remove an event",
+	Long:  "Remove an event. " + selectorHelp,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		return cmd.RunEventRemove(args[0])
+	},
+}
+
+var vacationCmd = &cobra.Command{
+	Use:   "vacation",
+	Short: "Add, edit or remove vacations and other leave",
+	Long: `Add, edit or remove vacations and other leave. --type records sick leave, jury duty, business
+travel, bereavement or unpaid leave instead of a vacation; policy.yaml says how each type counts.`,
+}
+
+var vacationAddCmd = &cobra.Command{
+	Use:   "add DESTINATION --start DATE [--end DATE]",
+	Short: "Add a vacation",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		v := data.Vacation{Destination: args[0]}
+		v.StartDate, _ = c.Flags().GetString("start")
+		v.EndDate, _ = c.Flags().GetString("end")
+		v.Approved, _ = c.Flags().GetBool("approved")
+		typ, _ := c.Flags().GetString("type")
+		t, err := cmd.ParseLeaveFlag(typ)
+		if err != nil {
+			return err
+		}
+		v.Type = t
+		if v.EndDate == "" {
+			v.EndDate = v.StartDate
+		}
+		return cmd.RunVacationAdd(v)
+	},
+}
+
+var vacationEditCmd = &cobra.Command{
+	Use:   "edit SELECTOR",
+	Short: "Edit a vacation",
+	Long:  "Edit a vacation. Only the fields given as flags are changed. " + selectorHelp + " Vacations are matched on their start date.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		edit := cmd.VacationEdit{
+			Destination: changedString(c, "destination"),
+			StartDate:   changedString(c, "start"),
+			EndDate:     changedString(c, "end"),
+		}
+		if c.Flags().Changed("approved") {
+			approved, _ := c.Flags().GetBool("approved")
+			edit.Approved = &approved
+		}
+		if typ := changedString(c, "type"); typ != nil {
+			t, err := cmd.ParseLeaveFlag(*typ)
+			if err != nil {
+				return err
+			}
+			edit.Type = &t
+		}
+		return cmd.RunVacationEdit(args[0], edit)
+	},
+}
+
+var holidaysGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a year's holidays from a rule set",
+	Long: `Generate the holidays of a rule set for --year (default: this year) and merge them into
+holidays.yaml. Dates that already have a holiday are skipped, and every date considered is reported.
+--set names a built-in set (` + strings.Join(data.HolidaySetNames(), ", ") + `) or the path of a
+holiday rules file; holidays on a weekend move to the day they're observed, as the set's rules say.`,
+	Args: cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		opts := cmd.HolidayGenerateOptions{Year: time.Now().Year()}
+		if c.Flags().Changed("year") {
+			opts.Year, _ = c.Flags().GetInt("year")
+		}
+		opts.Set, _ = c.Flags().GetString("set")
+		opts.DryRun, _ = c.Flags().GetBool("dry-run")
+		return cmd.RunHolidayGenerate(opts)
+	},
+}
+
+var vacationRmCmd = &cobra.Command{
+	Use:   "rm SELECTOR",
+	Short: "Remove a vacation",
+	Long:  "Remove a vacation. " + selectorHelp + " Vacations are matched on their start date.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		return cmd.RunVacationRemove(args[0])
+	},
+}
+
+var holidayCmd = &cobra.Command{
+	Use:   "holiday",
+	Short: "Add, edit or remove holidays",
+}
+
+var holidayAddCmd = &cobra.Command{
+	Use:   "add DATE NAME...",
+	Short: "Add a holiday",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(c *cobra.Command, args []string) error {
+		return cmd.RunHolidayAdd(data.Holiday{Date: args[0], Name: strings.Join(args[1:], " ")})
+	},
+}
+
+var holidayEditCmd = &cobra.Command{
+	Use:   "edit SELECTOR",
+	Short: "Edit a holiday",
+	Long:  "Edit a holiday. Only the fields given as flags are changed. " + selectorHelp,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		return cmd.RunHolidayEdit(args[0], cmd.HolidayEdit{
+			Date: changedString(c, "date"),
+			Name: changedString(c, "name"),
+		})
+	},
+}
+
+var holidayRmCmd = &cobra.Command{
+	Use:   "rm SELECTOR",
+	Short: "Remove a holiday",
+	Long:  "Remove a holiday. " + selectorHelp,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		return cmd.RunHolidayRemove(args[0])
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import data from other tools",
+}
+
+var importICSCmd = &cobra.Command{
+	Use:   "ics FILE --as holidays|vacations|events",
+	Short: "Import an iCalendar (.ics) file",
+	Long: `Import the events in an iCalendar file (as exported by Outlook or Google Calendar) as holidays,
+vacations or events. All-day, multi-day and recurring (RRULE) events are supported; recurring events are
+expanded between --from and --to (default: the start of this year to the end of next year). Entries
+already present (same UID or date) are skipped, and every entry considered is reported. Timed events
+such as meetings are only imported as holidays or vacations with --timed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		opts := cmd.ICSImportOptions{Path: args[0]}
+		opts.As, _ = c.Flags().GetString("as")
+		opts.Approved, _ = c.Flags().GetBool("approved")
+		opts.Timed, _ = c.Flags().GetBool("timed")
+		opts.DryRun, _ = c.Flags().GetBool("dry-run")
+		var err error
+		if opts.From, opts.To, err = dateWindow(c); err != nil {
+			return err
+		}
+		return cmd.RunImportICS(opts)
+	},
+}
+
+var importBadgesCmd = &cobra.Command{
+	Use:   "badges FILE",
+	Short: "Import badge swipes from an access-control CSV export",
+	Long: `Import a CSV export of badge swipes (one row per swipe, with a header row). Swipes are collapsed
+into one office badge-in per day, stamped with the day's first swipe and the office its location maps to.
+Weekends, holidays and vacation days are skipped, and days with a flex credit are left alone.
+
+The column mapping is read from badge_import in settings.yaml; flags override it for this run, and
+--save-mapping stores the result there. Columns are given by header name or 1-based number.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		settings, err := data.LoadAppSettings()
+		if err != nil {
+			return fmt.Errorf("loading settings: %w", err)
+		}
+		m := settings.BadgeImport
+		for flag, dst := range map[string]*string{
+			"timestamp-column": &m.TimestampColumn,
+			"timestamp-format": &m.TimestampFormat,
+			"timezone":         &m.Timezone,
+			"location-column":  &m.LocationColumn,
+			"card-column":      &m.CardColumn,
+			"card":             &m.CardID,
+		} {
+			if c.Flags().Changed(flag) {
+				*dst, _ = c.Flags().GetString(flag)
+			}
+		}
+		pairs, _ := c.Flags().GetStringArray("map")
+		if len(pairs) > 0 {
+			offices := map[string]string{}
+			for k, v := range m.Offices {
+				offices[k] = v
+			}
+			for _, p := range pairs {
+				loc, office, ok := strings.Cut(p, "=")
+				if !ok || loc == "" {
+					return fmt.Errorf("invalid --map %q (expected LOCATION=OFFICE)", p)
+				}
+				offices[loc] = office
+			}
+			m.Offices = offices
+		}
+
+		opts := cmd.BadgeImportOptions{Path: args[0], Mapping: m}
+		opts.SaveMapping, _ = c.Flags().GetBool("save-mapping")
+		opts.DryRun, _ = c.Flags().GetBool("dry-run")
+		return cmd.RunImportBadges(opts)
+	},
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export data for other tools",
+}
+
+var exportICSCmd = &cobra.Command{
+	Use:   "ics [FILE]",
+	Short: "Export an iCalendar (.ics) file",
+	Long: `Write office days, flex days, vacations, holidays, events and the office days still required to
+meet the goal as all-day events in an iCalendar file (or to stdout), one category each, for importing or
+subscribing to in a calendar app. Required days are the earliest open workdays that cover what each
+current period still needs; --remind adds a reminder to them. Entries keep the same UID across exports.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		opts := cmd.ICSExportOptions{}
+		if len(args) == 1 {
+			opts.Path = args[0]
+		}
+		opts.Categories, _ = c.Flags().GetStringSlice("categories")
+		opts.Remind, _ = c.Flags().GetDuration("remind")
+		var err error
+		if opts.From, opts.To, err = dateWindow(c); err != nil {
+			return err
+		}
+		return cmd.RunExportICS(opts)
+	},
+}
+
+// dateWindow reads the --from and --to flags of the import and export
+// commands, defaulting to the start of this year through the end of next.
+func dateWindow(c *cobra.Command) (from, to time.Time, err error) {
+	year := time.Now().Year()
+	from = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	to = time.Date(year+1, 12, 31, 0, 0, 0, 0, time.UTC)
+	for name, dst := range map[string]*time.Time{"from": &from, "to": &to} {
+		if v, _ := c.Flags().GetString(name); v != "" {
+			t, err := time.Parse(data.BadgeDateFormat, v)
+			if err != nil {
+				return from, to, fmt.Errorf("invalid --%s %q (expected YYYY-MM-DD)", name, v)
+			}
+			*dst = t
+		}
+	}
+	return from, to, nil
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade data files to the current schema version",
+	Long: `Upgrade every data file to the current schema version. Files already current are left alone, and
+the rest only gain or update their schema_version, keeping comments and formatting, unless a migration
+changes more. Use --dry-run to show a diff without writing anything.`,
+	Args: cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		dryRun, _ := c.Flags().GetBool("dry-run")
+		return cmd.RunMigrate(dryRun)
+	},
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check data files for problems",
+	Long: `Check every data file for problems: unparseable dates, duplicate or unsorted entries,
+badges on weekends or holidays, inverted vacations, and overlapping or gapped time periods.
+Use --fix to repair the mechanical ones (dedupe, sort, drop weekend/holiday badges,
+normalize date_time).`,
+	Args: cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		fix, _ := c.Flags().GetBool("fix")
+		return cmd.RunDoctor(fix)
+	},
+}
+
+var periodsCmd = &cobra.Command{
+	Use:   "periods",
+	Short: "Manage time period files",
+}
+
+var periodsGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a time period file for a calendar or fiscal calendar",
+	Long: `Generate the periods of --years fiscal years from --year (default: this year) and merge them
+into a time period file, creating it if needed. Periods whose key is already in the file, or whose
+dates overlap one of its periods, are skipped.
+
+--unit is one of ` + strings.Join(data.PeriodUnits, ", ") + `. A fiscal year starts on the 1st of
+--start-month and is named for the year it ends in: with --start-month oct, FY2027 runs from
+Oct 1, 2026 to Sep 30, 2027. --pattern 4-4-5, 4-5-4 or 5-4-4 makes it a retail calendar of whole
+weeks starting on the Monday nearest the 1st of --start-month. Weeks without --pattern are ISO weeks.`,
+	Args: cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		opts := cmd.PeriodsGenerateOptions{Spec: data.PeriodSpec{Year: time.Now().Year()}}
+		opts.Spec.Unit, _ = c.Flags().GetString("unit")
+		opts.Spec.Pattern, _ = c.Flags().GetString("pattern")
+		opts.Spec.Years, _ = c.Flags().GetInt("years")
+		if c.Flags().Changed("year") {
+			opts.Spec.Year, _ = c.Flags().GetInt("year")
+		}
+		if c.Flags().Changed("start-month") {
+			s, _ := c.Flags().GetString("start-month")
+			m, err := data.ParseMonth(s)
+			if err != nil {
+				return err
+			}
+			opts.Spec.StartMonth = m
+		}
+		opts.File, _ = c.Flags().GetString("file")
+		opts.Register, _ = c.Flags().GetBool("register")
+		opts.DryRun, _ = c.Flags().GetBool("dry-run")
+		return cmd.RunPeriodsGenerate(opts)
+	},
+}
+
+var periodsRolloverCmd = &cobra.Command{
+	Use:   "rollover",
+	Short: "Extend time period files that today is past",
+	Long: `Check each time period file in settings.yaml and, when today is past its last period, show the
+periods that carry it through today: the rest of its last fiscal year and each year after. The
+calendar, key format and names are inferred from the file's last periods. With --yes, the periods
+are added.`,
+	Args: cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		yes, _ := c.Flags().GetBool("yes")
+		return cmd.RunPeriodsRollover(yes)
+	},
+}
+
 var backupCmd = &cobra.Command{
 	Use:   "backup",
 	Short: "Backup data directory to git",
@@ -105,37 +623,155 @@ var backupCmd = &cobra.Command{
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&dataDir, "data-dir", "d", "", "Data directory (default: ./config)")
+	rootCmd.PersistentFlags().DurationVar(&lockWait, "lock-wait", 0, "How long to wait for a data lock held by another rto process (e.g. 10s)")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "text", "Output format for stats, plan, history, analytics, vacations, holidays and events: text, json, yaml or csv")
+	rootCmd.PersistentFlags().StringVar(&storeSpec, "store", "", "Storage backend: dir[:PATH] or sqlite[:PATH] (default: dir)")
+
+	statsCmd.Flags().Bool("yes", false, "Extend the time period file if today is past its last period")
+	statsCmd.Flags().Bool("days", false, "With --output csv, write one row per day instead of the summary")
+	statsCmd.Flags().String("rolling", "", "Show the trailing-window average instead, e.g. 12w or 90d")
+
+	planCmd.Flags().StringSlice("weekdays", nil, "Preferred office days, e.g. tue,wed,thu (default: settings planner.weekdays)")
+	planCmd.Flags().StringSlice("blackout", nil, "Dates never to plan (YYYY-MM-DD), added to planner.blackouts; repeatable")
+	planCmd.Flags().Int("buffer", 0, "Office days to plan beyond the goal (default: settings planner.buffer)")
+	planCmd.Flags().Bool("apply", false, "Record the proposed days as planned office days")
+	planCmd.Flags().Bool("yes", false, "Extend the time period file if today is past its last period")
+
+	historyCmd.Flags().String("file", "", "Time period file to compare (default: the first in settings.yaml)")
+
+	analyticsCmd.Flags().String("period", "", "Time period key, e.g. Q1_2025 (default: the current period)")
+	analyticsCmd.Flags().String("from", "", "Start of a date range instead (YYYY-MM-DD or today)")
+	analyticsCmd.Flags().String("to", "", "End of the date range, inclusive (default: today)")
+	analyticsCmd.Flags().Bool("yes", false, "Extend the time period file if today is past its last period")
+
+	badgeCmd.Flags().Bool("flex", false, "Record a flex credit instead of an office badge-in")
+	badgeCmd.Flags().Bool("planned", false, "Record a planned office day instead of a badge-in")
+	badgeCmd.Flags().String("office", "", "Office name (default: settings default_office)")
+	badgeCmd.Flags().Bool("remove", false, "Remove the entry instead of adding it")
+	badgeCmd.Flags().Float64("credit", 0, "Fraction of the day credited, e.g. 0.5 for a half day (default: a full day)")
+	badgeCmd.Flags().String("from", "", "Start of a date range (YYYY-MM-DD or today)")
+	badgeCmd.Flags().String("to", "", "End of a date range, inclusive (default: today)")
+	badgeCmd.Flags().String("weekdays", "", "Only these days of the week, e.g. mon,wed,fri (default: every working day)")
+
+	eventEditCmd.Flags().String("date", "", "New date (YYYY-MM-DD)")
+	eventEditCmd.Flags().String("description", "", "New description")
+	eventCmd.AddCommand(eventAddCmd, eventEditCmd, eventRmCmd)
+
+	vacationAddCmd.Flags().String("start", "", "First day of the vacation (YYYY-MM-DD)")
+	vacationAddCmd.Flags().String("end", "", "Last day of the vacation (default: same as --start)")
+	vacationAddCmd.Flags().Bool("approved", false, "Mark the vacation as approved")
+	vacationAddCmd.Flags().String("type", "", "Leave type: vacation, sick, jury_duty, travel, bereavement or unpaid (default: vacation)")
+	_ = vacationAddCmd.MarkFlagRequired("start")
+	vacationEditCmd.Flags().String("destination", "", "New destination")
+	vacationEditCmd.Flags().String("start", "", "New start date (YYYY-MM-DD)")
+	vacationEditCmd.Flags().String("end", "", "New end date (YYYY-MM-DD)")
+	vacationEditCmd.Flags().Bool("approved", false, "Set approval (--approved=false to clear)")
+	vacationEditCmd.Flags().String("type", "", "New leave type")
+	vacationCmd.AddCommand(vacationAddCmd, vacationEditCmd, vacationRmCmd)
+
+	holidayEditCmd.Flags().String("date", "", "New date (YYYY-MM-DD)")
+	holidayEditCmd.Flags().String("name", "", "New name")
+	holidayCmd.AddCommand(holidayAddCmd, holidayEditCmd, holidayRmCmd)
+	holidaysGenerateCmd.Flags().Int("year", 0, "Year to generate (default: this year)")
+	holidaysGenerateCmd.Flags().String("set", "us-federal", "Built-in holiday set or rules file: "+strings.Join(data.HolidaySetNames(), ", "))
+	holidaysGenerateCmd.Flags().Bool("dry-run", false, "Show what would be added without writing")
+	holidaysCmd.AddCommand(holidaysGenerateCmd)
+
+	importICSCmd.Flags().String("as", "", "What to import the events as: holidays, vacations or events")
+	importICSCmd.Flags().String("from", "", "Import occurrences from this date (default: Jan 1 this year)")
+	importICSCmd.Flags().String("to", "", "Import occurrences up to this date (default: Dec 31 next year)")
+	importICSCmd.Flags().Bool("approved", false, "Mark imported vacations as approved")
+	importICSCmd.Flags().Bool("timed", false, "Also import timed (not all-day) events as holidays or vacations")
+	importICSCmd.Flags().Bool("dry-run", false, "Show what would be imported without writing")
+	_ = importICSCmd.MarkFlagRequired("as")
+	importBadgesCmd.Flags().String("timestamp-column", "", `Column holding the swipe time (default "timestamp")`)
+	importBadgesCmd.Flags().String("timestamp-format", "", "Go time layout of the timestamps (default: try common formats)")
+	importBadgesCmd.Flags().String("timezone", "", "IANA timezone of timestamps without an offset, e.g. America/New_York (default: local)")
+	importBadgesCmd.Flags().String("location-column", "", "Column holding the door or building")
+	importBadgesCmd.Flags().String("card-column", "", "Column holding the card id")
+	importBadgesCmd.Flags().String("card", "", "Only import swipes of this card id (requires --card-column)")
+	importBadgesCmd.Flags().StringArray("map", nil, "Map a location (or part of one) to an office: LOCATION=OFFICE; repeatable")
+	importBadgesCmd.Flags().Bool("save-mapping", false, "Save the column mapping to settings.yaml")
+	importBadgesCmd.Flags().Bool("dry-run", false, "Show what would be recorded without writing")
+	importCmd.AddCommand(importICSCmd, importBadgesCmd)
+
+	exportICSCmd.Flags().String("from", "", "Export days from this date (default: Jan 1 this year)")
+	exportICSCmd.Flags().String("to", "", "Export days up to this date (default: Dec 31 next year)")
+	exportICSCmd.Flags().StringSlice("categories", nil, "Only these categories: "+strings.Join(cmd.ICSCategories, ",")+" (default: all)")
+	exportICSCmd.Flags().Duration("remind", 0, "Add a reminder this long before each required day starts, e.g. 15h for 09:00 the day before")
+	exportCmd.AddCommand(exportICSCmd)
+
+	migrateCmd.Flags().Bool("dry-run", false, "Show what would change without writing")
+	doctorCmd.Flags().Bool("fix", false, "Repair mechanical problems")
+
+	periodsGenerateCmd.Flags().String("unit", data.UnitQuarters, "Period length: "+strings.Join(data.PeriodUnits, ", "))
+	periodsGenerateCmd.Flags().String("start-month", "", "First month of the fiscal year, e.g. oct or 10 (default: jan)")
+	periodsGenerateCmd.Flags().String("pattern", "", "Retail calendar week pattern: 4-4-5, 4-5-4 or 5-4-4")
+	periodsGenerateCmd.Flags().Int("year", 0, "First fiscal year to generate (default: this year)")
+	periodsGenerateCmd.Flags().Int("years", 1, "Number of fiscal years to generate")
+	periodsGenerateCmd.Flags().String("file", "", "Time period file to write (default: named for the calendar, e.g. fiscal-oct-quarters.yaml)")
+	periodsGenerateCmd.Flags().Bool("register", false, "Add the file to time_periods in settings.yaml")
+	periodsGenerateCmd.Flags().Bool("dry-run", false, "Show what would be added without writing")
+	periodsCmd.AddCommand(periodsGenerateCmd)
+	periodsRolloverCmd.Flags().Bool("yes", false, "Add the periods instead of only showing them")
+	periodsCmd.AddCommand(periodsRolloverCmd)
 
 	backupCmd.Flags().StringP("remote", "r", "", "Git remote URL")
 	backupCmd.Flags().StringP("dir", "", "", "Directory to backup (default: data-dir)")
 
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(analyticsCmd)
+	rootCmd.AddCommand(badgeCmd)
 	rootCmd.AddCommand(vacationsCmd)
 	rootCmd.AddCommand(holidaysCmd)
+	rootCmd.AddCommand(eventsCmd)
+	rootCmd.AddCommand(eventCmd)
+	rootCmd.AddCommand(vacationCmd)
+	rootCmd.AddCommand(holidayCmd)
+	rootCmd.AddCommand(periodsCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(backupCmd)
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if c, ok := data.GetStore().(io.Closer); ok {
+		c.Close()
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
-// dirNeedsInit returns true only if the data directory has never been initialized.
+// storeNeedsInit returns true only if the store has never been initialized.
 // It checks for settings.yaml as the canonical marker of initialization.
-func dirNeedsInit(dir string) bool {
-	info, err := os.Stat(dir)
-	if os.IsNotExist(err) {
-		return true
-	}
-	if !info.IsDir() {
-		return true
+func storeNeedsInit(st data.Store) bool {
+	return !st.Exists("settings.yaml")
+}
+
+// periodKeyArg returns the period key in args, or the current period's,
+// extending the time period file first if --yes is set and today is past it.
+func periodKeyArg(c *cobra.Command, args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
 	}
-	settingsPath := filepath.Join(dir, "settings.yaml")
-	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
-		return true
+	yes, _ := c.Flags().GetBool("yes")
+	return cmd.CurrentPeriodKey(yes)
+}
+
+// changedString returns the value of a string flag if it was set on the
+// command line, or nil so edit commands can leave the field alone.
+func changedString(c *cobra.Command, name string) *string {
+	if !c.Flags().Changed(name) {
+		return nil
 	}
-	return false
+	v, _ := c.Flags().GetString(name)
+	return &v
 }
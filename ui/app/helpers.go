@@ -3,12 +3,15 @@ package app
 import (
 	"time"
 
+	"rto/calc"
+	"rto/data"
+
 	"charm.land/lipgloss/v2"
 )
 
 // ── Style Helpers ────────────────────────────────────────────────────────────
 
-func calendarDayStyle(selected, badged, flex, holidayVac, today, weekend, hasEvent bool) lipgloss.Style {
+func calendarDayStyle(selected, badged, flex, planned, missed, holiday bool, leave data.LeaveType, today, weekend, hasEvent bool) lipgloss.Style {
 	style := lipgloss.NewStyle()
 
 	switch {
@@ -16,8 +19,14 @@ func calendarDayStyle(selected, badged, flex, holidayVac, today, weekend, hasEve
 		style = style.Foreground(lipgloss.Color("172")).Bold(true)
 	case badged:
 		style = style.Foreground(lipgloss.Color("9")).Bold(true)
-	case holidayVac:
+	case missed:
+		style = style.Foreground(lipgloss.Color("203"))
+	case planned:
+		style = style.Foreground(lipgloss.Color("117"))
+	case holiday || leave == data.LeaveVacation:
 		style = style.Foreground(lipgloss.Color("2"))
+	case leave != "":
+		style = style.Foreground(lipgloss.Color(leaveColors[leave]))
 	case today:
 		style = style.Bold(true)
 	case weekend:
@@ -39,18 +48,55 @@ func calendarDayStyle(selected, badged, flex, holidayVac, today, weekend, hasEve
 	return style
 }
 
-func statusStyle(status string) lipgloss.Style {
+// cycleLeaveType returns the leave type dir steps after t in
+// data.LeaveTypes, wrapping around.
+func cycleLeaveType(t data.LeaveType, dir int) data.LeaveType {
+	n := len(data.LeaveTypes)
+	for i, lt := range data.LeaveTypes {
+		if lt == t {
+			return data.LeaveTypes[((i+dir)%n+n)%n]
+		}
+	}
+	return data.LeaveVacation
+}
+
+// leaveColors are the calendar colors of leave types other than vacation,
+// which shares green with holidays.
+var leaveColors = map[data.LeaveType]string{
+	data.LeaveSick:        "5",
+	data.LeaveJuryDuty:    "4",
+	data.LeaveTravel:      "6",
+	data.LeaveBereavement: "139",
+	data.LeaveUnpaid:      "3",
+}
+
+// statusStyle colors a compliance status; a status band's color comes from
+// the policy.
+func statusStyle(status string, bands []data.StatusBand) lipgloss.Style {
 	switch status {
-	case "Achieved":
+	case calc.StatusAchieved:
 		return lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Bold(true)
-	case "On Track":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("40"))
-	case "At Risk":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
-	case "Impossible":
+	case calc.StatusImpossible:
 		return lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+	}
+	for _, b := range bands {
+		if b.Name == status && b.Color != "" {
+			return lipgloss.NewStyle().Foreground(lipgloss.Color(b.Color))
+		}
+	}
+	return lipgloss.NewStyle()
+}
+
+// weekStatusMark returns the calendar's week-column glyph for a
+// weekly-minimum status: ✓ met, ✗ missed, · anything else.
+func weekStatusMark(status calc.WeekStatus) string {
+	switch status {
+	case calc.WeekMet:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render("✓")
+	case calc.WeekMissed:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("✗")
 	default:
-		return lipgloss.NewStyle()
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("·")
 	}
 }
 
@@ -8,6 +8,7 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"rto/backup"
+	"rto/calc"
 	"rto/data"
 )
 
@@ -23,6 +24,10 @@ func (m *AppModel) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m.handleHolidaysKey(msg)
 	case ViewSettings:
 		return m.handleSettingsKey(msg)
+	case ViewHistory:
+		return m.handleHistoryKey(msg)
+	case ViewAnalytics:
+		return m.handleAnalyticsKey(msg)
 	}
 	return m, nil
 }
@@ -37,6 +42,9 @@ func (m *AppModel) handleCalendarKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	if m.mode == ModeSearch {
 		return m.handleSearchKey(msg)
 	}
+	if m.mode == ModeRollover {
+		return m.handleRolloverKey(msg)
+	}
 
 	switch msg.String() {
 	case "ctrl+c":
@@ -53,6 +61,15 @@ func (m *AppModel) handleCalendarKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	case "f":
 		m.toggleFlex()
 		return m, nil
+	case "c":
+		m.cycleCredit()
+		return m, nil
+	case "l":
+		m.togglePlanned()
+		return m, nil
+	case "P":
+		m.applyPlan()
+		return m, nil
 	case "g":
 		m.gitBackup()
 		return m, nil
@@ -93,6 +110,10 @@ func (m *AppModel) handleCalendarKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		m.currentView = ViewSettings
 		m.listCursor = 0
 		m.mode = ModeNormal
+	case "H":
+		m.openHistory()
+	case "A":
+		m.openAnalytics()
 	case "y":
 		// Toggle year stats view (rendered in calendar view already)
 	case "space":
@@ -125,11 +146,12 @@ func (m *AppModel) handleAddEventKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	case "esc":
 		m.mode = ModeNormal
 	case "enter":
-		if strings.TrimSpace(m.inputBuffer) != "" {
-			m.eventData.Add(data.Event{
-				Date:        m.selectedDate.Format("2006-01-02"),
-				Description: strings.TrimSpace(m.inputBuffer),
-			})
+		ev := data.Event{
+			Date:        m.selectedDate.Format("2006-01-02"),
+			Description: strings.TrimSpace(m.inputBuffer),
+		}
+		if ev.Validate() == nil {
+			m.eventData.Add(ev)
 			m.markDirty()
 		}
 		m.mode = ModeNormal
@@ -188,6 +210,20 @@ func (m *AppModel) handleDeleteEventKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd
 	return m, nil
 }
 
+func (m *AppModel) handleRolloverKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "y", "enter":
+		m.acceptRollover()
+	case "n", "esc":
+		m.rolloverPeriods = nil
+		m.mode = ModeNormal
+		m.statusMsg = "Periods not added — rto periods rollover --yes adds them later"
+	}
+	return m, nil
+}
+
 func (m *AppModel) handleSearchKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
@@ -228,7 +264,7 @@ func (m *AppModel) handleVacationsNormal(msg tea.KeyPressMsg) (tea.Model, tea.Cm
 	case "a":
 		m.mode = ModeAdd
 		m.formCursor = 0
-		m.formInputs = []string{"", "", "", ""}
+		m.formInputs = []string{"", string(data.LeaveVacation), "", "", ""}
 	case "e", "enter":
 		if len(all) > 0 {
 			v := all[m.listCursor]
@@ -238,12 +274,24 @@ func (m *AppModel) handleVacationsNormal(msg tea.KeyPressMsg) (tea.Model, tea.Cm
 			}
 			m.mode = ModeEdit
 			m.formCursor = 0
-			m.formInputs = []string{v.Destination, v.StartDate, v.EndDate, approved}
+			m.formInputs = []string{v.Destination, string(v.Leave()), v.StartDate, v.EndDate, approved}
 		}
-	case "x":
+	case "y":
 		if len(all) > 0 {
 			v := all[m.listCursor]
-			m.vacationData.Remove(v.StartDate, v.EndDate)
+			v.Approved = !v.Approved
+			m.vacationData.Update(m.listCursor, v)
+			m.markDirty()
+			m.recalculateStats()
+			if v.Approved {
+				m.statusMsg = fmt.Sprintf("%s approved", v.Destination)
+			} else {
+				m.statusMsg = fmt.Sprintf("%s marked pending — committed stats no longer count it", v.Destination)
+			}
+		}
+	case "x":
+		if len(all) > 0 {
+			m.vacationData.RemoveAt(m.listCursor)
 			m.markDirty()
 			if m.listCursor >= m.vacationData.Len() && m.listCursor > 0 {
 				m.listCursor--
@@ -262,7 +310,23 @@ func (m *AppModel) handleVacationsNormal(msg tea.KeyPressMsg) (tea.Model, tea.Cm
 	return m, nil
 }
 
+// vacationTypeField is the form field holding the leave type, which is
+// chosen with ←/→ rather than typed.
+const vacationTypeField = 1
+
 func (m *AppModel) handleVacationsForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if m.formCursor == vacationTypeField {
+		switch msg.String() {
+		case "left":
+			m.formInputs[vacationTypeField] = string(cycleLeaveType(data.LeaveType(m.formInputs[vacationTypeField]), -1))
+			return m, nil
+		case "right", "space":
+			m.formInputs[vacationTypeField] = string(cycleLeaveType(data.LeaveType(m.formInputs[vacationTypeField]), 1))
+			return m, nil
+		case "backspace":
+			return m, nil
+		}
+	}
 	switch msg.String() {
 	case "esc":
 		m.mode = ModeNormal
@@ -271,21 +335,26 @@ func (m *AppModel) handleVacationsForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 		if m.formCursor < len(m.formInputs)-1 {
 			m.formCursor++
 		} else {
-			approved := strings.ToLower(strings.TrimSpace(m.formInputs[3])) == "y"
+			approved := strings.ToLower(strings.TrimSpace(m.formInputs[4])) == "y"
 			newVac := data.Vacation{
 				Destination: strings.TrimSpace(m.formInputs[0]),
-				StartDate:   strings.TrimSpace(m.formInputs[1]),
-				EndDate:     strings.TrimSpace(m.formInputs[2]),
+				StartDate:   strings.TrimSpace(m.formInputs[2]),
+				EndDate:     strings.TrimSpace(m.formInputs[3]),
 				Approved:    approved,
 			}
-			if m.mode == ModeEdit {
-				all := m.vacationData.All()
-				if m.listCursor < len(all) {
-					old := all[m.listCursor]
-					m.vacationData.Remove(old.StartDate, old.EndDate)
-				}
+			if t := data.LeaveType(m.formInputs[vacationTypeField]); t != data.LeaveVacation {
+				newVac.Type = t
+			}
+			if err := newVac.Validate(); err != nil {
+				m.statusMsg = "Invalid vacation: " + err.Error()
+				return m, nil
+			}
+			if m.mode == ModeEdit && m.listCursor < m.vacationData.Len() {
+				newVac.UID = m.vacationData.All()[m.listCursor].UID
+				m.vacationData.Update(m.listCursor, newVac)
+			} else {
+				m.vacationData.Add(newVac)
 			}
-			m.vacationData.Add(newVac)
 			m.markDirty()
 			m.mode = ModeNormal
 			m.formInputs = nil
@@ -301,7 +370,7 @@ func (m *AppModel) handleVacationsForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 			m.formCursor++
 		}
 	default:
-		if msg.Text != "" {
+		if msg.Text != "" && m.formCursor != vacationTypeField {
 			m.formInputs[m.formCursor] += msg.Text
 		}
 	}
@@ -336,15 +405,7 @@ func (m *AppModel) handleHolidaysNormal(msg tea.KeyPressMsg) (tea.Model, tea.Cmd
 		}
 	case "x":
 		if len(all) > 0 {
-			h := all[m.listCursor]
-			newHD := data.NewHolidayData()
-			for _, existing := range all {
-				if existing.Date == h.Date && existing.Name == h.Name {
-					continue
-				}
-				newHD.Add(existing)
-			}
-			*m.holidayData = *newHD
+			m.holidayData.RemoveAt(m.listCursor)
 			m.markDirty()
 			if m.listCursor >= m.holidayData.Len() && m.listCursor > 0 {
 				m.listCursor--
@@ -376,21 +437,16 @@ func (m *AppModel) handleHolidaysForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 				Date: strings.TrimSpace(m.formInputs[0]),
 				Name: strings.TrimSpace(m.formInputs[1]),
 			}
-			if m.mode == ModeEdit {
-				all := m.holidayData.All()
-				if m.listCursor < len(all) {
-					old := all[m.listCursor]
-					newHD := data.NewHolidayData()
-					for _, h := range all {
-						if h.Date == old.Date && h.Name == old.Name {
-							continue
-						}
-						newHD.Add(h)
-					}
-					*m.holidayData = *newHD
-				}
+			if err := newH.Validate(); err != nil {
+				m.statusMsg = "Invalid holiday: " + err.Error()
+				return m, nil
+			}
+			if m.mode == ModeEdit && m.listCursor < m.holidayData.Len() {
+				newH.UID = m.holidayData.All()[m.listCursor].UID
+				m.holidayData.Update(m.listCursor, newH)
+			} else {
+				m.holidayData.Add(newH)
 			}
-			m.holidayData.Add(newH)
 			m.markDirty()
 			m.mode = ModeNormal
 			m.formInputs = nil
@@ -413,6 +469,32 @@ func (m *AppModel) handleHolidaysForm(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 	return m, nil
 }
 
+func (m *AppModel) handleHistoryKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc":
+		m.currentView = ViewCalendar
+	case "enter":
+		m.openHistoryPeriod()
+	case "down":
+		if m.listCursor < len(m.history)-1 {
+			m.listCursor++
+		}
+	case "up":
+		if m.listCursor > 0 {
+			m.listCursor--
+		}
+	}
+	return m, nil
+}
+
+func (m *AppModel) handleAnalyticsKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc":
+		m.currentView = ViewCalendar
+	}
+	return m, nil
+}
+
 func (m *AppModel) handleSettingsKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	switch m.mode {
 	case ModeNormal:
@@ -463,6 +545,7 @@ func (m *AppModel) handleSettingsEdit(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 		case 2:
 			if v, err := strconv.Atoi(strings.TrimSpace(m.inputBuffer)); err == nil && v > 0 && v <= 100 {
 				m.settings.Goal = v
+				m.reloadPolicy()
 				m.recalculateStats()
 			}
 		}
@@ -496,41 +579,108 @@ func (m *AppModel) handleSettingsEdit(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 // Helper functions moved from calendar_view.go
 //
 
+// toggleBadge badges in, or clears, the selected date. A date after today
+// can't be attended yet, so it is planned instead; an entry recorded there
+// earlier can still be cleared.
 func (m *AppModel) toggleBadge() {
-	key := m.selectedDate.Format("2006-01-02")
-	if m.badgeData.Has(key) {
-		existing, _ := m.badgeData.Get(key)
-		if !existing.IsFlexCredit {
-			m.badgeData.Remove(key)
-		}
-	} else {
-		m.badgeData.Add(data.BadgeEntry{
-			EntryDate:  key,
-			DateTime:   data.FlexTime{Time: m.selectedDate},
-			Office:     m.settings.DefaultOffice,
-			IsBadgedIn: true,
-		})
+	if existing, ok := m.badgeData.Get(m.selectedDate.Format(data.BadgeDateFormat)); m.selectedDate.After(m.today) && (!ok || existing.Planned) {
+		m.togglePlanned()
+		if !ok {
+			m.statusMsg = "Future date — planned rather than badged in"
+		}
+		return
+	}
+	entry := data.NewOfficeBadge(m.selectedDate, m.settings.DefaultOffice)
+	if m.badgeData.ToggleBadge(entry) == data.BadgeConflict {
+		m.statusMsg = "Flex credit recorded — press f to clear it first"
+		return
+	}
+	m.markDirty()
+	m.recalculateStats()
+}
+
+// cycleCredit steps the selected date through a full day, a half day and
+// nothing. A new entry is an office badge-in; an existing one keeps its kind.
+func (m *AppModel) cycleCredit() {
+	if m.selectedDate.After(m.today) {
+		m.statusMsg = "Can't record credit for a future date — press l to plan it"
+		return
+	}
+	entry := data.NewOfficeBadge(m.selectedDate, m.settings.DefaultOffice)
+	if m.badgeData.CycleCredit(entry) == data.BadgeUnchanged {
+		return
+	}
+	m.markDirty()
+	m.recalculateStats()
+}
+
+// togglePlanned plans, or unplans, an office day on the selected date. A day
+// already recorded is left alone.
+func (m *AppModel) togglePlanned() {
+	entry := data.NewPlannedBadge(m.selectedDate, m.settings.DefaultOffice)
+	if m.badgeData.TogglePlanned(entry) == data.BadgeConflict {
+		m.statusMsg = "Day already recorded — press b or f to clear it first"
+		return
 	}
 	m.markDirty()
 	m.recalculateStats()
 }
 
+// applyPlan plans, in what-if mode, the days the planner proposes for the
+// active period, so the plan can be tried out and then discarded.
+func (m *AppModel) applyPlan() {
+	if m.activeStats == nil {
+		return
+	}
+	opts, err := calc.NewPlanOptions(m.settings.Planner, m.eventData)
+	if err != nil {
+		m.statusMsg = "settings.yaml: " + err.Error()
+		return
+	}
+	plan := calc.PlanAttendance(m.activeStats, m.today, opts)
+	if len(plan.Days) == 0 {
+		m.statusMsg = "Nothing to plan — the goal is already covered"
+		return
+	}
+	if !m.isWhatIf() {
+		m.enterWhatIf()
+	}
+	for _, d := range plan.Days {
+		m.badgeData.SetBadge(data.NewPlannedBadge(d, m.settings.DefaultOffice), false)
+	}
+	m.recalculateStats()
+	m.statusMsg = fmt.Sprintf("Planned %d office days in what-if mode — w discards them", len(plan.Days))
+	if plan.Short > 0 {
+		m.statusMsg += fmt.Sprintf("; still %d short of the goal", plan.Short)
+	}
+}
+
+// toggleFlex records, or clears, a flex credit on the selected date. As with
+// rto badge --flex, a date after today can't get one, though an entry
+// recorded there earlier can still be cleared. Before adding one it warns if
+// the period's flex cap is already used up, or if the policy doesn't count
+// flex credits.
 func (m *AppModel) toggleFlex() {
-	key := m.selectedDate.Format("2006-01-02")
-	if m.badgeData.Has(key) {
-		existing, _ := m.badgeData.Get(key)
-		if existing.IsFlexCredit {
-			m.badgeData.Remove(key)
-		}
-	} else {
-		m.badgeData.Add(data.BadgeEntry{
-			EntryDate:    key,
-			DateTime:     data.FlexTime{Time: m.selectedDate},
-			Office:       m.settings.FlexCredit,
-			IsBadgedIn:   true,
-			IsFlexCredit: true,
-		})
+	warning := ""
+	existing, recorded := m.badgeData.Get(m.selectedDate.Format(data.BadgeDateFormat))
+	if m.selectedDate.After(m.today) && (!recorded || existing.Planned) {
+		m.statusMsg = "Can't record a flex credit for a future date"
+		return
+	}
+	if s := m.activeStats; s != nil && (!recorded || existing.Planned) && !m.policy.Flex.Allows(s.FlexDays) {
+		if m.policy.Flex.Counts() {
+			warning = fmt.Sprintf("Flex cap reached: %d of %d credits already used this period — this one won't count", s.FlexDays, s.FlexCap)
+		} else {
+			warning = fmt.Sprintf("Flex credits don't count under policy %s", m.policy.Name)
+		}
+	}
+	entry := data.NewFlexBadge(m.selectedDate, m.settings.FlexCredit)
+	if m.badgeData.ToggleBadge(entry) == data.BadgeConflict {
+		m.statusMsg = "Office badge-in recorded — press b to clear it first"
+		return
 	}
+	// The credit is kept either way; the warning says it won't help.
+	m.statusMsg = warning
 	m.markDirty()
 	m.recalculateStats()
 }
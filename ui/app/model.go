@@ -21,6 +21,8 @@ const (
 	ViewHolidays
 	ViewSettings
 	ViewYearStats
+	ViewHistory
+	ViewAnalytics
 )
 
 type ViewMode int
@@ -31,6 +33,7 @@ const (
 	ModeEdit
 	ModeDelete
 	ModeSearch
+	ModeRollover // asking whether to extend the time period file past today
 )
 
 type AppModel struct {
@@ -41,6 +44,8 @@ type AppModel struct {
 	vacationData   *data.VacationData
 	eventData      *data.EventData
 	settings       *data.AppSettings
+	policy         *data.Policy
+	store          data.Store
 	dataDir        string
 
 	// UI state
@@ -56,9 +61,11 @@ type AppModel struct {
 
 	// Time period view switching
 	activeTimePeriodIdx int
+	rolloverPeriods     []data.TimePeriod // offered in ModeRollover
 
 	// What-if mode
 	whatIfSnapshot      *data.BadgeEntryData
+	whatIfVacations     *data.VacationData
 	whatIfDirtySnapshot string
 
 	// Bubbletea helpers
@@ -67,6 +74,9 @@ type AppModel struct {
 	termHeight    int
 	activeStats   *calc.PeriodStats
 	yearStats     *calc.PeriodStats
+	rollingStats  *calc.RollingStats  // nil unless settings.yaml has a rolling_window
+	history       []calc.HistoryEntry // periods listed in ViewHistory
+	analytics     *calc.Analytics     // attendance patterns shown in ViewAnalytics
 	statusMsg     string
 	gitInfo       backup.StatusInfo
 	cleanChecksum string
@@ -78,13 +88,19 @@ func New() (*AppModel, error) {
 		return nil, fmt.Errorf("loading settings: %w", err)
 	}
 
+	store := data.GetStore()
 	dir := data.GetDataDir()
 	tpFile := settings.ActiveTimePeriodFile(0)
-	timePeriodData, err := data.LoadTimePeriodDataFrom(dir, tpFile)
+	timePeriodData, err := data.LoadTimePeriodDataFromStore(store, tpFile)
 	if err != nil {
 		return nil, fmt.Errorf("loading time periods (%s): %w", tpFile, err)
 	}
 
+	policy, err := data.LoadPolicyFromStore(store, settings)
+	if err != nil {
+		return nil, fmt.Errorf("loading policy: %w", err)
+	}
+
 	badgeData, err := data.LoadBadgeEntryData()
 	if err != nil {
 		return nil, fmt.Errorf("loading badge data: %w", err)
@@ -116,6 +132,8 @@ func New() (*AppModel, error) {
 		vacationData:        vacationData,
 		eventData:           eventData,
 		settings:            settings,
+		policy:              policy,
+		store:               store,
 		dataDir:             dir,
 		currentView:         ViewCalendar,
 		mode:                ModeNormal,
@@ -125,6 +143,7 @@ func New() (*AppModel, error) {
 		activeTimePeriodIdx: 0,
 	}
 	m.recalculateStats()
+	m.checkRollover()
 	m.refreshGitInfo()
 	m.cleanChecksum = m.dataChecksum()
 	return m, nil
@@ -140,7 +159,7 @@ func (m *AppModel) dataChecksum() string {
 	badges := m.badgeData.All()
 	sort.Slice(badges, func(i, j int) bool { return badges[i].EntryDate < badges[j].EntryDate })
 	for _, b := range badges {
-		fmt.Fprintf(h, "B|%s|%v|", b.EntryDate, b.IsFlexCredit)
+		fmt.Fprintf(h, "B|%s|%v|%g|%v|", b.EntryDate, b.IsFlexCredit, b.Credit, b.Planned)
 	}
 
 	events := m.eventData.All()
@@ -157,7 +176,7 @@ func (m *AppModel) dataChecksum() string {
 	vacations := m.vacationData.All()
 	sort.Slice(vacations, func(i, j int) bool { return vacations[i].StartDate < vacations[j].StartDate })
 	for _, v := range vacations {
-		fmt.Fprintf(h, "V|%s|%s|%s|%v|", v.StartDate, v.EndDate, v.Destination, v.Approved)
+		fmt.Fprintf(h, "V|%s|%s|%s|%v|%s|", v.StartDate, v.EndDate, v.Destination, v.Approved, v.Type)
 	}
 
 	holidays := m.holidayData.All()
@@ -222,24 +241,44 @@ func (m *AppModel) DataDir() string {
 	return m.dataDir
 }
 
+// reloadPolicy re-reads policy.yaml, which takes its default goal from
+// settings. A policy that fails to load leaves the current one in place.
+func (m *AppModel) reloadPolicy() {
+	if p, err := data.LoadPolicyFromStore(m.store, m.settings); err == nil {
+		m.policy = p
+	}
+}
+
 func (m *AppModel) recalculateStats() {
 	period, err := m.timePeriodData.GetPeriodByDate(m.navDate)
 	if err != nil {
 		return
 	}
-	stats, err := calc.CalculatePeriodStats(
+	stats, err := calc.CalculatePolicyStats(
 		period,
 		m.badgeData,
 		m.holidayData,
 		m.vacationData,
-		m.settings.Goal,
+		m.policy,
 		&m.today,
 	)
 	if err != nil {
 		return
 	}
+	calc.CalculateWeeks(stats, m.settings.WeeklyMinimum, m.today)
 	m.activeStats = stats
 	m.recalculateYearStats(period)
+	m.recalculateRollingStats(period)
+}
+
+func (m *AppModel) recalculateRollingStats(period *data.TimePeriod) {
+	m.rollingStats = nil
+	windowDays, err := m.settings.RollingWindow.Days()
+	if !m.settings.RollingWindow.Enabled() || err != nil {
+		return
+	}
+	m.rollingStats = calc.CalculateRolling(m.badgeData, m.holidayData, m.vacationData, m.policy,
+		windowDays, period.StartDate, period.EndDate, m.today)
 }
 
 func (m *AppModel) recalculateYearStats(period *data.TimePeriod) {
@@ -260,7 +299,7 @@ func (m *AppModel) recalculateYearStats(period *data.TimePeriod) {
 		return
 	}
 
-	stats, err := calc.CalculateYearStats(yearPeriods, m.badgeData, m.holidayData, m.vacationData, m.settings.Goal, &m.today)
+	stats, err := calc.CalculateYearPolicyStats(yearPeriods, m.badgeData, m.holidayData, m.vacationData, m.policy, &m.today)
 	if err == nil {
 		m.yearStats = stats
 	}
@@ -273,7 +312,7 @@ func (m *AppModel) switchTimePeriodView(dir int) {
 	}
 	m.activeTimePeriodIdx = (m.activeTimePeriodIdx + dir + n) % n
 	tpFile := m.settings.ActiveTimePeriodFile(m.activeTimePeriodIdx)
-	td, err := data.LoadTimePeriodDataFrom(m.dataDir, tpFile)
+	td, err := data.LoadTimePeriodDataFromStore(m.store, tpFile)
 	if err != nil {
 		m.statusMsg = fmt.Sprintf("Error loading %s: %v", tpFile, err)
 		return
@@ -292,6 +331,85 @@ func (m *AppModel) switchTimePeriodView(dir int) {
 		m.selectedDate = p.StartDate
 	}
 	m.recalculateStats()
+	m.checkRollover()
+}
+
+// openHistory computes the stats of each period of the active view that has
+// started and lists them, with the cursor on the latest.
+func (m *AppModel) openHistory() {
+	history, err := calc.CalculateHistory(m.timePeriodData.All(), m.badgeData, m.holidayData, m.vacationData, m.policy, m.today)
+	if err != nil {
+		m.statusMsg = "Error calculating history: " + err.Error()
+		return
+	}
+	m.history = history
+	m.currentView = ViewHistory
+	m.listCursor = max(0, len(history)-1)
+	m.mode = ModeNormal
+}
+
+// openHistoryPeriod shows the period under the history cursor in the
+// calendar, selecting today if it falls in the period.
+func (m *AppModel) openHistoryPeriod() {
+	if m.listCursor >= len(m.history) {
+		return
+	}
+	h := m.history[m.listCursor]
+	m.selectedDate = h.StartDate
+	if !m.today.Before(h.StartDate) && !m.today.After(h.EndDate) {
+		m.selectedDate = m.today
+	}
+	m.navDate = time.Date(h.StartDate.Year(), h.StartDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	m.currentView = ViewCalendar
+	m.recalculateStats()
+}
+
+// openAnalytics shows the attendance patterns of the period on screen.
+func (m *AppModel) openAnalytics() {
+	period, err := m.timePeriodData.GetPeriodByDate(m.navDate)
+	if err != nil {
+		m.statusMsg = "No time period to analyze here"
+		return
+	}
+	m.analytics = calc.CalculateAnalytics(m.badgeData, m.holidayData, m.vacationData, period.StartDate, period.EndDate, m.today)
+	m.currentView = ViewAnalytics
+	m.mode = ModeNormal
+}
+
+// checkRollover offers to extend the active time period file when today is
+// past its last period, or says why it can't.
+func (m *AppModel) checkRollover() {
+	periods, err := m.timePeriodData.Rollover(m.today)
+	if err != nil {
+		m.statusMsg = "Today is past the last period: " + err.Error()
+		return
+	}
+	if len(periods) > 0 {
+		m.rolloverPeriods = periods
+		m.mode = ModeRollover
+	}
+}
+
+// acceptRollover adds the offered periods to the active time period file,
+// saves it, and moves to the period holding today.
+func (m *AppModel) acceptRollover() {
+	for _, tp := range m.rolloverPeriods {
+		m.timePeriodData.Add(tp)
+	}
+	n := len(m.rolloverPeriods)
+	m.rolloverPeriods = nil
+	m.mode = ModeNormal
+	if err := m.timePeriodData.SaveToStore(m.store); err != nil {
+		m.statusMsg = fmt.Sprintf("Error saving %s: %v", m.timePeriodData.Filename(), err)
+		return
+	}
+	m.statusMsg = fmt.Sprintf("Added %d periods to %s", n, m.timePeriodData.Filename())
+	m.selectedDate = m.today
+	m.navDate = time.Date(m.today.Year(), m.today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if p, err := m.timePeriodData.GetPeriodByDate(m.today); err == nil {
+		m.navDate = time.Date(p.StartDate.Year(), p.StartDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	m.recalculateStats()
 }
 
 func (m *AppModel) isWhatIf() bool {
@@ -300,14 +418,17 @@ func (m *AppModel) isWhatIf() bool {
 
 func (m *AppModel) enterWhatIf() {
 	m.whatIfSnapshot = m.badgeData.Clone()
+	m.whatIfVacations = m.vacationData.Clone()
 	m.whatIfDirtySnapshot = m.cleanChecksum
 }
 
 func (m *AppModel) exitWhatIf() {
 	if m.whatIfSnapshot != nil {
 		m.badgeData = m.whatIfSnapshot
+		m.vacationData = m.whatIfVacations
 		m.cleanChecksum = m.whatIfDirtySnapshot
 		m.whatIfSnapshot = nil
+		m.whatIfVacations = nil
 		m.recalculateStats()
 	}
 }
@@ -2,9 +2,11 @@ package app
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"rto/calc"
 	"rto/data"
 
 	tea "charm.land/bubbletea/v2"
@@ -28,6 +30,10 @@ func (m *AppModel) View() tea.View {
 		mainContent = m.renderSettings()
 	case ViewYearStats:
 		mainContent = m.renderYearStats()
+	case ViewHistory:
+		mainContent = m.renderHistory()
+	case ViewAnalytics:
+		mainContent = m.renderAnalytics()
 	default:
 		mainContent = "Unknown view"
 	}
@@ -51,6 +57,10 @@ func (m *AppModel) windowTitle() string {
 		return base + " — Holidays"
 	case ViewSettings:
 		return base + " — Settings"
+	case ViewHistory:
+		return base + " — History"
+	case ViewAnalytics:
+		return base + " — Analytics"
 	}
 	if m.isWhatIf() {
 		return base + " — What-If"
@@ -134,6 +144,11 @@ func (m *AppModel) renderCalendar() string {
 		yearBox := renderBoxWithTitle(yearTitle, yearContent, statRowWidth)
 		statsSection = lipgloss.JoinVertical(lipgloss.Left, periodBox, yearBox)
 	}
+	if m.rollingStats != nil {
+		rollingTitle := fmt.Sprintf("Rolling Stats: %s", data.FormatWindow(m.rollingStats.WindowDays))
+		rollingBox := renderBoxWithTitle(rollingTitle, m.renderRollingStats(), statRowWidth)
+		statsSection = lipgloss.JoinVertical(lipgloss.Left, statsSection, rollingBox)
+	}
 
 	mainContent := lipgloss.JoinHorizontal(lipgloss.Top, calendarAndEvents, "  ", statsSection)
 	return mainContent
@@ -165,17 +180,24 @@ func (m *AppModel) drawMonth(
 	header := fmt.Sprintf("%s %d", monthName(int(month.Month())), month.Year())
 	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("231")).Width(24).Align(lipgloss.Center).Render(header) + "\n")
 
-	// Day headers
-	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" Su Mo Tu We Th Fr Sa  ") + "\n")
+	// Weekly-minimum marks describe ISO weeks, so with them each row runs
+	// Monday to Sunday and is exactly the week its mark is for.
+	weekMarks := m.activeStats != nil && len(m.activeStats.Weeks) > 0
+	dayHeader, weekStart := " Su Mo Tu We Th Fr Sa  ", time.Sunday
+	if weekMarks {
+		dayHeader, weekStart = " Mo Tu We Th Fr Sa Su  ", time.Monday
+	}
+	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(dayHeader) + "\n")
 
 	firstDay := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
-	startWeekday := int(firstDay.Weekday())
+	startWeekday := (int(firstDay.Weekday()) - int(weekStart) + 7) % 7
 	daysInM := daysInMonth(month.Year(), int(month.Month()))
 
+	// Each cell is a one-column mark (½ for a partial day) and the day number.
 	var days []string
 	// Pad with empty cells
 	for i := 0; i < startWeekday; i++ {
-		days = append(days, "  ")
+		days = append(days, "   ")
 	}
 
 	for day := 1; day <= daysInM; day++ {
@@ -184,30 +206,49 @@ func (m *AppModel) drawMonth(
 
 		isSelected := date.Equal(m.selectedDate)
 		isToday := date.Equal(m.today)
-		isWeekend := date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+		isWeekend := !data.IsWorkday(date)
 
-		_, isBadged := badges[key]
-		isFlexCredit := false
+		// A planned day isn't badged in; once it passes unrecorded it's missed.
+		isBadged, isFlexCredit, isPlanned, isMissed := false, false, false, false
+		mark := " "
 		if entry, ok := badges[key]; ok {
+			isBadged = !entry.Planned
 			isFlexCredit = entry.IsFlexCredit
+			isPlanned = entry.Planned
+			isMissed = entry.Planned && date.Before(m.today)
+			if isMissed {
+				mark = "!"
+			} else if entry.IsPartial() {
+				mark = "½"
+			}
 		}
 		_, isHoliday := holidayMap[key]
-		_, isVacation := vacations[key]
+		var leave data.LeaveType
+		if v, ok := vacations[key]; ok {
+			leave = v.Leave()
+		}
 		_, hasEvent := events[key]
 
-		style := calendarDayStyle(isSelected, isBadged, isFlexCredit, isHoliday || isVacation, isToday, isWeekend, hasEvent).
-			Width(2).Align(lipgloss.Right)
-		days = append(days, style.Render(fmt.Sprintf("%d", day)))
+		style := calendarDayStyle(isSelected, isBadged, isFlexCredit, isPlanned, isMissed, isHoliday, leave, isToday, isWeekend, hasEvent)
+		markStyle := calendarDayStyle(false, isBadged, isFlexCredit, isPlanned, isMissed, isHoliday, leave, false, isWeekend, hasEvent)
+		days = append(days, markStyle.Render(mark)+style.Width(2).Align(lipgloss.Right).Render(fmt.Sprintf("%d", day)))
 	}
 
-	// Create rows
+	// Create rows, each followed by its week's weekly-minimum mark
 	var rows []string
 	for i := 0; i < len(days); i += 7 {
 		end := i + 7
 		if end > len(days) {
 			end = len(days)
 		}
-		rows = append(rows, strings.Join(days[i:end], " "))
+		row := strings.Join(days[i:end], "")
+		if weekMarks {
+			monday := firstDay.AddDate(0, 0, i-startWeekday)
+			if w, ok := m.activeStats.WeekOf(monday); ok {
+				row += strings.Repeat("   ", i+7-end) + " " + weekStatusMark(w.Status)
+			}
+		}
+		rows = append(rows, row)
 	}
 	b.WriteString(strings.Join(rows, "\n"))
 
@@ -225,7 +266,8 @@ func (m *AppModel) renderStats() string {
 	sectionStyle := lipgloss.NewStyle().Bold(true)
 
 	b.WriteString(sectionStyle.Render("STATUS") + "\n")
-	b.WriteString(renderStatRow("  Status", statusStyle(s.ComplianceStatus).Render(s.ComplianceStatus), "") + "\n")
+	b.WriteString(renderStatRow("  Policy: "+s.Policy, "", "") + "\n")
+	b.WriteString(renderStatRow("  Status", statusStyle(s.ComplianceStatus, m.policy.StatusBands).Render(s.ComplianceStatus), "") + "\n")
 
 	paceStr := fmt.Sprintf("%+d days", s.DaysAheadOfPace)
 	if s.DaysAheadOfPace > 0 {
@@ -245,14 +287,15 @@ func (m *AppModel) renderStats() string {
 	if s.TotalDays > 0 {
 		goalPct = fmt.Sprintf("%.1f%%", float64(s.DaysRequired)/float64(s.TotalDays)*100)
 	}
-	goalLabel := fmt.Sprintf("  Goal (%d%% Required)", m.settings.Goal)
+	goalLabel := fmt.Sprintf("  Goal (%d%% Required)", s.GoalPct)
 	b.WriteString(renderStatRow(goalLabel, fmt.Sprintf("%d / %d", s.DaysRequired, s.TotalDays), goalPct) + "\n")
 	officePct := ""
 	if s.DaysRequired > 0 {
-		officePct = fmt.Sprintf("%.1f%%", float64(s.DaysBadgedIn)/float64(s.DaysRequired)*100)
+		officePct = fmt.Sprintf("%.1f%%", s.Credits/float64(s.DaysRequired)*100)
 	}
-	b.WriteString(renderStatRow("  Office Days", fmt.Sprintf("%d / %d", s.DaysBadgedIn, s.DaysRequired), officePct) + "\n")
-	badgeOnly := s.DaysBadgedIn - s.FlexDays
+	// Credits equals DaysBadgedIn unless there are weighted flex credits or partial days.
+	b.WriteString(renderStatRow("  Office Days", fmt.Sprintf("%g / %d", s.Credits, s.DaysRequired), officePct) + "\n")
+	badgeOnly := s.DaysBadgedIn - s.FlexDays - s.LeaveAttended
 	badgePct := ""
 	flexPct := ""
 	if s.DaysBadgedIn > 0 {
@@ -261,11 +304,59 @@ func (m *AppModel) renderStats() string {
 	}
 	b.WriteString(renderStatRow("   Badge-In Days", fmt.Sprintf("%d", badgeOnly), badgePct) + "\n")
 	b.WriteString(renderStatRow("   Flex Credits", fmt.Sprintf("%d", s.FlexDays), flexPct) + "\n")
+	if s.LeaveAttended > 0 {
+		b.WriteString(renderStatRow("   Leave Counted as Office Days", fmt.Sprintf("%d", s.LeaveAttended), "") + "\n")
+	}
+	if s.FlexCap > 0 {
+		label := "   Flex Credits Used / Allowed"
+		if s.FlexWeight != 1 {
+			label = fmt.Sprintf("   Flex Credits Used / Allowed (×%g)", s.FlexWeight)
+		}
+		b.WriteString(renderStatRow(label, fmt.Sprintf("%d / %d", s.FlexDays, s.FlexCap), "") + "\n")
+	} else if s.FlexWeight != 1 {
+		b.WriteString(renderStatRow(fmt.Sprintf("   Flex Credits Credited (×%g)", s.FlexWeight), fmt.Sprintf("%g", float64(s.FlexDays)*s.FlexWeight), "") + "\n")
+	}
+	if s.PartialDays > 0 {
+		b.WriteString(renderStatRow("   Partial Days", fmt.Sprintf("%d", s.PartialDays), "") + "\n")
+	}
+	for _, t := range data.LeaveTypes {
+		if n := s.LeaveDays[t]; n > 0 {
+			b.WriteString(renderStatRow("  On Leave: "+t.Label(), fmt.Sprintf("%d", n), "") + "\n")
+		}
+	}
 	neededPct := ""
 	if s.DaysRequired > 0 {
 		neededPct = fmt.Sprintf("%.1f%%", float64(s.DaysStillNeeded)/float64(s.DaysRequired)*100)
 	}
 	b.WriteString(renderStatRow("  Still Needed", fmt.Sprintf("%d / %d", s.DaysStillNeeded, s.DaysRequired), neededPct) + "\n")
+	if s.PlannedDays > 0 {
+		reached := "goal not reached"
+		if s.PlannedCompletionDate != nil {
+			reached = "goal " + s.PlannedCompletionDate.Format("Jan 2")
+		}
+		b.WriteString(renderStatRow(fmt.Sprintf("  Planned Days (%s)", reached), fmt.Sprintf("%d", s.PlannedDays), "") + "\n")
+		b.WriteString(renderStatRow("  Projected Office Days", fmt.Sprintf("%g / %d", s.ProjectedCredits, s.DaysRequired), "") + "\n")
+	}
+	if s.MissedPlans > 0 {
+		b.WriteString(renderStatRow("  Missed Plans", fmt.Sprintf("%d", s.MissedPlans), "") + "\n")
+	}
+	if len(s.Weeks) > 0 {
+		weeksPct := fmt.Sprintf("%.1f%%", float64(s.WeeksMet)/float64(len(s.Weeks))*100)
+		weeksLabel := fmt.Sprintf("  Weeks Met (%d-day minimum, %d missed)", s.WeeklyMinimum, s.WeeksMissed)
+		b.WriteString(renderStatRow(weeksLabel, fmt.Sprintf("%d / %d", s.WeeksMet, len(s.Weeks)), weeksPct) + "\n")
+	}
+	if c := s.Committed; c != nil {
+		b.WriteString("\n")
+		b.WriteString(sectionStyle.Render(fmt.Sprintf("PENDING LEAVE (%d days not approved)", s.PendingLeaveDays)) + "\n")
+		b.WriteString(renderCompareRow("", "Tentative", "Committed") + "\n")
+		b.WriteString(renderCompareRow("  Available Working Days", fmt.Sprintf("%d", s.TotalDays), fmt.Sprintf("%d", c.TotalDays)) + "\n")
+		b.WriteString(renderCompareRow("  Goal", fmt.Sprintf("%d", s.DaysRequired), fmt.Sprintf("%d", c.DaysRequired)) + "\n")
+		b.WriteString(renderCompareRow("  Still Needed", fmt.Sprintf("%d", s.DaysStillNeeded), fmt.Sprintf("%d", c.DaysStillNeeded)) + "\n")
+		b.WriteString(renderCompareRow("  Days Ahead of Pace", fmt.Sprintf("%+d", s.DaysAheadOfPace), fmt.Sprintf("%+d", c.DaysAheadOfPace)) + "\n")
+		b.WriteString(renderCompareRow("  Status",
+			statusStyle(s.ComplianceStatus, m.policy.StatusBands).Render(s.ComplianceStatus),
+			statusStyle(c.ComplianceStatus, m.policy.StatusBands).Render(c.ComplianceStatus)) + "\n")
+	}
 
 	return b.String()
 }
@@ -305,6 +396,17 @@ func renderStatRow(label, value, pct string) string {
 	return label + strings.Repeat(" ", labelPad) + strings.Repeat(" ", valuePad) + value + pctCol
 }
 
+// renderCompareRow renders a stat row with two right-aligned value columns
+// splitting the value and percent columns of renderStatRow.
+func renderCompareRow(label, left, right string) string {
+	const colW = (statValueCol + statPctCol) / 2
+	pad := func(s string, w int) string {
+		return strings.Repeat(" ", max(w-lipgloss.Width(s), 0)) + s
+	}
+	labelPad := max(statLabelCol-lipgloss.Width(label), 1)
+	return label + strings.Repeat(" ", labelPad) + pad(left, colW) + pad(right, colW)
+}
+
 func renderBoxWithTitle(title, content string, minWidth ...int) string {
 	lines := strings.Split(content, "\n")
 	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
@@ -378,6 +480,11 @@ func (m *AppModel) renderEventsAndHelp(eventMap map[string][]data.Event) string
 		// 		b.WriteString(fmt.Sprintf("  [%s] %s\n", ev.Date, ev.Description))
 		// 	}
 		// }
+	} else if m.mode == ModeRollover && len(m.rolloverPeriods) > 0 {
+		first, last := m.rolloverPeriods[0], m.rolloverPeriods[len(m.rolloverPeriods)-1]
+		prompt := fmt.Sprintf(" Today is past the last period in %s. Add %s – %s (%s to %s)? [y/n]",
+			m.timePeriodData.Filename(), first.StartDateRaw, last.EndDateRaw, first.Key, last.Key)
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true).Render(prompt) + "\n")
 	}
 
 	b.WriteString("\n")
@@ -393,10 +500,11 @@ func (m *AppModel) renderEventsAndHelp(eventMap map[string][]data.Event) string
 
 	bindings := [][2]string{
 		{"←→↑↓", "Navigate"}, {"b", m.settings.DefaultOffice}, {"f", m.settings.FlexCredit},
-		{"n/p", "Next/Prev period"}, {"a", "Add event"}, {"d", "Delete event"},
+		{"c", "Full/half day"}, {"n/p", "Next/Prev period"}, {"a", "Add event"}, {"d", "Delete event"},
 		{"s", "Search"}, {"w", "What-if"}, {"g", "Git backup"},
 		{"v", "Vacations"}, {"h", "Holidays"}, {"o", "Settings"},
-		{"q", "Quit"},
+		{"l", "Planned day"}, {"P", "Plan (what-if)"}, {"H", "History"},
+		{"A", "Analytics"}, {"q", "Quit"},
 	}
 
 	const keyColWidth = 24
@@ -417,7 +525,7 @@ func (m *AppModel) renderEventsAndHelp(eventMap map[string][]data.Event) string
 	}
 
 	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	b.WriteString(dimStyle.Render("Data: "+m.dataDir) + "\n")
+	b.WriteString(dimStyle.Render("Data: "+m.store.String()) + "\n")
 
 	if m.gitInfo.IsRepo {
 		dirty := m.hasUnsavedChanges()
@@ -455,11 +563,11 @@ func (m *AppModel) renderEventsAndHelp(eventMap map[string][]data.Event) string
 func (m *AppModel) renderVacations() string {
 	var b strings.Builder
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
-	b.WriteString(titleStyle.Render(" Vacations  (a=add  e=edit  x=delete  q=back)") + "\n")
+	b.WriteString(titleStyle.Render(" Vacations and Leave  (a=add  e=edit  y=approve  x=delete  q=back)") + "\n")
 
-	header := fmt.Sprintf(" %-4s  %-45s  %-12s  %-12s  %s", "#", "Destination", "Start", "End", "Approved")
+	header := fmt.Sprintf(" %-4s  %-45s  %-15s  %-12s  %-12s  %s", "#", "Destination", "Type", "Start", "End", "Approved")
 	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(header) + "\n")
-	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" ----  ---------------------------------------------  ------------  ------------  --------") + "\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" ----  ---------------------------------------------  ---------------  ------------  ------------  --------") + "\n")
 
 	all := m.vacationData.All()
 	for i, v := range all {
@@ -467,8 +575,8 @@ func (m *AppModel) renderVacations() string {
 		if v.Approved {
 			approved = "Yes"
 		}
-		line := fmt.Sprintf(" %-4d  %-45s  %-12s  %-12s  %s",
-			i+1, truncateStr(v.Destination, 45), v.StartDate, v.EndDate, approved)
+		line := fmt.Sprintf(" %-4d  %-45s  %-15s  %-12s  %-12s  %s",
+			i+1, truncateStr(v.Destination, 45), v.Leave().Label(), v.StartDate, v.EndDate, approved)
 		style := lipgloss.NewStyle()
 		if i == m.listCursor && m.mode == ModeNormal {
 			style = style.Reverse(true)
@@ -484,7 +592,7 @@ func (m *AppModel) renderVacations() string {
 		}
 		b.WriteString(lipgloss.NewStyle().Bold(true).Render(formTitle) + "\n")
 
-		fields := []string{"Destination", "Start Date (YYYY-MM-DD)", "End Date (YYYY-MM-DD)", "Approved (y/n)"}
+		fields := []string{"Destination", "Type (←/→)", "Start Date (YYYY-MM-DD)", "End Date (YYYY-MM-DD)", "Approved (y/n)"}
 		for i, field := range fields {
 			style := lipgloss.NewStyle()
 			if i == m.formCursor {
@@ -498,7 +606,11 @@ func (m *AppModel) renderVacations() string {
 			if i == m.formCursor {
 				suffix = "_"
 			}
-			b.WriteString(style.Render(fmt.Sprintf("  %s: %s%s\n", field, val, suffix)))
+			if i == vacationTypeField {
+				val = "‹ " + data.LeaveType(val).Label() + " ›"
+				suffix = ""
+			}
+			b.WriteString(style.Render(fmt.Sprintf("  %s: %s%s", field, val, suffix)) + "\n")
 		}
 		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  (Enter=next field, Esc=cancel)") + "\n")
 	}
@@ -545,7 +657,7 @@ func (m *AppModel) renderHolidays() string {
 			if i == m.formCursor {
 				suffix = "_"
 			}
-			b.WriteString(style.Render(fmt.Sprintf("  %s: %s%s\n", field, val, suffix)))
+			b.WriteString(style.Render(fmt.Sprintf("  %s: %s%s", field, val, suffix)) + "\n")
 		}
 		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  (Enter=next field, Esc=cancel)") + "\n")
 	}
@@ -553,6 +665,112 @@ func (m *AppModel) renderHolidays() string {
 	return b.String()
 }
 
+func (m *AppModel) renderHistory() string {
+	var b strings.Builder
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	b.WriteString(titleStyle.Render(" History — "+m.timePeriodData.Filename()+"  (enter=open in calendar  q=back)") + "\n")
+	if len(m.history) == 0 {
+		b.WriteString(dimStyle.Render("  No periods have started yet.") + "\n")
+		return b.String()
+	}
+
+	header := fmt.Sprintf(" %-12s  %-23s  %8s  %6s  %4s  %6s  %6s  %s", "Period", "Dates", "Required", "Badged", "Flex", "Rate", "Margin", "Status")
+	b.WriteString(dimStyle.Render(header) + "\n")
+	b.WriteString(dimStyle.Render(" ------------  -----------------------  --------  ------  ----  ------  ------  ------------------") + "\n")
+
+	var rates []float64
+	for i, h := range m.history {
+		margin := strconv.FormatFloat(h.Margin, 'f', -1, 64)
+		if h.Margin > 0 {
+			margin = "+" + margin
+		}
+		status := h.ComplianceStatus
+		if !h.Complete {
+			status += " (in progress)"
+		}
+		line := fmt.Sprintf(" %-12s  %s – %s  %8d  %6d  %4d  %5.1f%%  %6s  ",
+			h.Key, h.StartDate.Format("2006-01-02"), h.EndDate.Format("2006-01-02"),
+			h.DaysRequired, h.DaysBadgedIn, h.FlexDays, h.Rate*100, margin)
+		if i == m.listCursor {
+			b.WriteString(lipgloss.NewStyle().Reverse(true).Render(line+status) + "\n")
+		} else {
+			b.WriteString(line + statusStyle(h.ComplianceStatus, m.policy.StatusBands).Render(status) + "\n")
+		}
+		rates = append(rates, h.Rate)
+	}
+
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf(" Rate trend:  %s  ", calc.Sparkline(rates, 1)))
+	b.WriteString(dimStyle.Render(fmt.Sprintf("(goal %d%%)", m.history[len(m.history)-1].GoalPct)) + "\n")
+	return b.String()
+}
+
+func (m *AppModel) renderAnalytics() string {
+	var b strings.Builder
+	a := m.analytics
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	title := " Analytics"
+	if p, err := m.timePeriodData.GetPeriodByDate(a.Start); err == nil {
+		title += " — " + p.Name
+	}
+	title += fmt.Sprintf(" (%s – %s)  (q=back)", a.Start.Format("Jan 2"), a.End.Format("Jan 2, 2006"))
+	b.WriteString(titleStyle.Render(title) + "\n\n")
+
+	if len(a.Weekdays) == 0 {
+		b.WriteString(dimStyle.Render("  No workdays so far.") + "\n")
+	} else {
+		header := fmt.Sprintf(" %-10s  %6s  %4s  %9s  %6s", "Weekday", "Office", "Flex", "Available", "Rate")
+		b.WriteString(dimStyle.Render(header) + "\n")
+		least, _ := a.LeastAttended()
+		for _, ws := range a.Weekdays {
+			line := fmt.Sprintf(" %-10s  %6d  %4d  %9d  %5.1f%%  ", ws.Weekday, ws.Office, ws.Flex, ws.Available, ws.Rate*100)
+			bar := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render(calc.RateBar(ws.Rate, 20))
+			if ws.Weekday == least.Weekday && least.Rate < 1 {
+				bar += lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("  least attended")
+			}
+			b.WriteString(line + bar + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf(" Longest streak:   %s\n", a.LongestStreak))
+	b.WriteString(fmt.Sprintf(" Current streak:   %s\n", a.CurrentStreak))
+	officeDays := 0
+	for _, o := range a.Offices {
+		officeDays += o.Days
+	}
+	if a.TimedBadgeIns > 0 {
+		b.WriteString(fmt.Sprintf(" Average arrival:  %s  ", calc.FormatTimeOfDay(a.AverageArrival)))
+		b.WriteString(dimStyle.Render(fmt.Sprintf("(from %d of %d office badge-ins)", a.TimedBadgeIns, officeDays)) + "\n")
+	} else {
+		b.WriteString(" Average arrival:  " + dimStyle.Render("no badge-in times recorded") + "\n")
+	}
+
+	b.WriteString("\n Offices:\n")
+	if len(a.Offices) == 0 {
+		b.WriteString(dimStyle.Render("  No office badge-ins yet.") + "\n")
+	}
+	for _, o := range a.Offices {
+		name := o.Office
+		if name == "" {
+			name = "(unnamed)"
+		}
+		b.WriteString(fmt.Sprintf("  %-24s  %3d\n", name, o.Days))
+	}
+	return b.String()
+}
+
+// goalSetting shows the settings.yaml goal, noting when policy.yaml
+// overrides it.
+func (m *AppModel) goalSetting() string {
+	if m.policy.Goal != m.settings.Goal {
+		return fmt.Sprintf("%d  (policy.yaml: %d)", m.settings.Goal, m.policy.Goal)
+	}
+	return fmt.Sprintf("%d", m.settings.Goal)
+}
+
 func (m *AppModel) renderSettings() string {
 	var b strings.Builder
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
@@ -561,7 +779,7 @@ func (m *AppModel) renderSettings() string {
 	settings := [][2]string{
 		{"Default Office", m.settings.DefaultOffice},
 		{"Flex Credit Label", m.settings.FlexCredit},
-		{"Goal (%)", fmt.Sprintf("%d", m.settings.Goal)},
+		{"Goal (%)", m.goalSetting()},
 	}
 
 	for i, s := range settings {
@@ -596,9 +814,9 @@ func (m *AppModel) renderYearStatsContent() string {
 	b.WriteString(renderStatRow("  Total Working Days", fmt.Sprintf("%d", ys.AvailableWorkdays-ys.Holidays), "") + "\n")
 	b.WriteString(renderStatRow("  Available Working Days", fmt.Sprintf("%d", ys.TotalDays), "") + "\n")
 	b.WriteString(renderStatRow("  Holidays", fmt.Sprintf("%d", ys.Holidays), "") + "\n")
-	b.WriteString(renderStatRow("  Vacation Days", fmt.Sprintf("%d", ys.VacationDays), "") + "\n")
+	b.WriteString(renderStatRow("  Leave Days Excluded", fmt.Sprintf("%d", ys.VacationDays), "") + "\n")
 	b.WriteString(renderStatRow("  Office Days", fmt.Sprintf("%d", ys.DaysBadgedIn), "") + "\n")
-	badgeOnly := ys.DaysBadgedIn - ys.FlexDays
+	badgeOnly := ys.DaysBadgedIn - ys.FlexDays - ys.LeaveAttended
 	yBadgePct := ""
 	yFlexPct := ""
 	if ys.DaysBadgedIn > 0 {
@@ -611,6 +829,36 @@ func (m *AppModel) renderYearStatsContent() string {
 	return b.String()
 }
 
+func (m *AppModel) renderRollingStats() string {
+	rs := m.rollingStats
+	t := rs.Today
+
+	var b strings.Builder
+	status := "Compliant"
+	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+	if !t.Compliant() {
+		status = "Below Goal"
+		statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+	}
+	b.WriteString(renderStatRow("  Status", statusStyle.Render(status), "") + "\n")
+	b.WriteString(renderStatRow("  Window", t.WindowStart.Format("Jan 2")+"–"+t.Date.Format("Jan 2"), "") + "\n")
+	b.WriteString(renderStatRow(fmt.Sprintf("  Goal (%d%% Required)", rs.GoalPct), fmt.Sprintf("%d / %d", t.Required, t.Available), "") + "\n")
+	b.WriteString(renderStatRow("  Office Days", fmt.Sprintf("%g / %d", t.BadgedIn, t.Available), fmt.Sprintf("%.1f%%", t.Rate*100)) + "\n")
+	below := "never"
+	switch {
+	case t.BelowGoalOn.Equal(t.Date):
+		below = "today"
+	case !t.BelowGoalOn.IsZero():
+		below = t.BelowGoalOn.Format("Jan 2, 2006")
+	}
+	b.WriteString(renderStatRow("  Below Goal From (no more badge-ins)", below, "") + "\n")
+	if d, ok := rs.Day(m.selectedDate); ok && !d.Date.Equal(t.Date) {
+		label := fmt.Sprintf("  Window Ending %s", d.Date.Format("Jan 2"))
+		b.WriteString(renderStatRow(label, fmt.Sprintf("%g / %d", d.BadgedIn, d.Available), fmt.Sprintf("%.1f%%", d.Rate*100)) + "\n")
+	}
+	return b.String()
+}
+
 func (m *AppModel) renderYearStats() string {
 	content := m.renderYearStatsContent()
 	if content == "" {